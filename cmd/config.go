@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 
 	"strings"
+	"time"
 
 	"github.com/Phantas0s/devdash/internal"
 	"github.com/adrg/xdg"
@@ -30,6 +31,15 @@ type General struct {
 	Keys    map[string]string `mapstructure:"keys"`
 	Refresh int64             `mapstructure:"refresh"`
 	Editor  string            `mapstructure:"editor"`
+	Cache   Cache             `mapstructure:"cache"`
+}
+
+// Cache of the API responses.
+type Cache struct {
+	// TTL of the responses, in seconds. No cache if 0.
+	TTL int64 `mapstructure:"ttl"`
+	// Persist the responses on disk, in $XDG_CACHE_HOME/devdash.
+	Persist bool `mapstructure:"persist"`
 }
 
 // RefreshTime return the duration before refreshing the data of all widgets, in seconds.
@@ -42,11 +52,11 @@ func (c config) RefreshTime() int64 {
 }
 
 type Project struct {
-	Name        string                       `mapstructure:"name"`
-	NameOptions map[string]string            `mapstructure:"name_options"`
-	Services    Services                     `mapstructure:"services"`
-	Themes      map[string]map[string]string `mapstructure:"themes"`
-	Widgets     []Row                        `mapstructure:"widgets"`
+	Name        string                            `mapstructure:"name"`
+	NameOptions map[string]string                 `mapstructure:"name_options"`
+	Services    map[string]map[string]interface{} `mapstructure:"services"`
+	Themes      map[string]map[string]string      `mapstructure:"themes"`
+	Widgets     []Row                             `mapstructure:"widgets"`
 }
 
 // Row is constitued of columns
@@ -64,86 +74,6 @@ type Widgets struct {
 	Elements []internal.Widget `mapstructure:"elements"`
 }
 
-type Services struct {
-	GoogleAnalytics     GoogleAnalytics `mapstructure:"google_analytics"`
-	GoogleSearchConsole SearchConsole   `mapstructure:"google_search_console"`
-	Monitor             Monitor         `mapstructure:"monitor"`
-	Github              Github          `mapstructure:"github"`
-	TravisCI            TravisCI        `mapstructure:"travis"`
-	Feedly              Feedly          `mapstructure:"feedly"`
-	Git                 Git             `mapstructure:"git"`
-	RemoteHost          RemoteHost      `mapstructure:"remote_host"`
-	Localhost           RemoteHost      `mapstructure:"local_host"`
-}
-
-type GoogleAnalytics struct {
-	Keyfile string `mapstructure:"keyfile"`
-	ViewID  string `mapstructure:"view_id"`
-}
-
-type SearchConsole struct {
-	Keyfile string `mapstructure:"keyfile"`
-	Address string `mapstructure:"address"`
-}
-
-type Monitor struct {
-	Address string `mapstructure:"address"`
-}
-
-type Github struct {
-	Token      string `mapstructure:"token"`
-	Owner      string `mapstructure:"owner"`
-	Repository string `mapstructure:"repository"`
-}
-
-type TravisCI struct {
-	Token string `mapstructure:"token"`
-}
-
-type Feedly struct {
-	Address string `mapstructure:"address"`
-}
-
-type RemoteHost struct {
-	Username string `mapstructure:"username"`
-	Address  string `mapstructure:"address"`
-}
-
-type Git struct {
-	Path string `mapstructure:"path"`
-}
-
-func (g GoogleAnalytics) empty() bool {
-	return g == GoogleAnalytics{}
-}
-
-func (m Monitor) empty() bool {
-	return m == Monitor{}
-}
-func (s SearchConsole) empty() bool {
-	return s == SearchConsole{}
-}
-
-func (g Github) empty() bool {
-	return g == Github{}
-}
-
-func (t TravisCI) empty() bool {
-	return t == TravisCI{}
-}
-
-func (f Feedly) empty() bool {
-	return f == Feedly{}
-}
-
-func (g Git) empty() bool {
-	return g == Git{}
-}
-
-func (g RemoteHost) empty() bool {
-	return g == RemoteHost{}
-}
-
 // OrderWidgets add the widgets to a three dimensional slice.
 // First dimension: index of the rows (ir or indexRows).
 // Second dimension: index of the columns (ic or indexColumn).
@@ -167,6 +97,20 @@ func (p Project) OrderWidgets() ([][][]internal.Widget, [][]string) {
 	return rows, sizes
 }
 
+// CacheTTL return the duration the API responses are kept.
+func (c config) CacheTTL() time.Duration {
+	return time.Duration(c.General.Cache.TTL) * time.Second
+}
+
+// CachePath return the directory where the API responses are persisted, or an empty string if they're not.
+func (c config) CachePath() string {
+	if !c.General.Cache.Persist {
+		return ""
+	}
+
+	return filepath.Join(xdg.CacheHome, "devdash")
+}
+
 func dashPath() string {
 	return filepath.Join(xdg.ConfigHome, "devdash")
 }
@@ -193,21 +137,6 @@ func mapConfig(cfgFile string) (config, string) {
 		panic(err)
 	}
 
-	prefix := "DEVDASH"
-	for k, _ := range cfg.Projects {
-		if cfg.Projects[k].Services.GoogleAnalytics.Keyfile == "" {
-			cfg.Projects[k].Services.GoogleAnalytics.Keyfile = os.Getenv(prefix + "_GA_KEYFILE")
-		}
-
-		if cfg.Projects[k].Services.GoogleSearchConsole.Keyfile == "" {
-			cfg.Projects[k].Services.GoogleSearchConsole.Keyfile = os.Getenv(prefix + "_GSC_KEYFILE")
-		}
-
-		if cfg.Projects[k].Services.Github.Token == "" {
-			cfg.Projects[k].Services.Github.Token = os.Getenv(prefix + "_GITHUB_TOKEN")
-		}
-	}
-
 	return cfg, viper.ConfigFileUsed()
 }
 
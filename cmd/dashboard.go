@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+	"sync/atomic"
+
+	"github.com/Phantas0s/devdash/internal"
+)
+
+// dashboard displayed in the terminal.
+// It's kept between reloads to refresh the widgets in place and to reuse the services (and their clients).
+type dashboard struct {
+	tui *internal.Tui
+	// refresh the widgets having their own refresh interval. Nil if the dashboard is only rendered once.
+	scheduler *internal.Scheduler
+
+	// configuration of the projects displayed
+	projects []Project
+	// projects displayed, in the same order as their configuration
+	displayed []displayedProject
+	// services created, indexed by project and service configuration key
+	services map[string]cachedService
+	// when set to 1, the next reload rebuild the whole dashboard
+	rebuild int32
+	// lock the projects while they're replaced or read
+	mu sync.Mutex
+}
+
+type displayedProject interface {
+	Refresh()
+	RenderedWidgets() map[int]internal.Widget
+}
+
+type cachedService struct {
+	options map[string]interface{}
+	service internal.Service
+}
+
+func newDashboard(tui *internal.Tui, scheduler *internal.Scheduler) *dashboard {
+	return &dashboard{
+		tui:       tui,
+		scheduler: scheduler,
+		services:  map[string]cachedService{},
+	}
+}
+
+// rebuildNext force the next reload to rebuild the whole dashboard.
+func (d *dashboard) rebuildNext() {
+	atomic.StoreInt32(&d.rebuild, 1)
+}
+
+// reload the configuration file.
+// If the projects didn't change, the widgets are refreshed in place.
+// Otherwise the dashboard is rebuilt, reusing the services which configuration didn't change.
+func (d *dashboard) reload(file string) {
+	cfg, _ := mapConfig(file)
+
+	if d.refresh(cfg) {
+		return
+	}
+
+	d.scheduler.Stop()
+	d.tui.HotReload()
+	d.build(cfg)
+}
+
+// refresh the widgets in place if the projects didn't change, returning false otherwise.
+// The widgets are fetched without lock: the widgets rendered can still be read meanwhile.
+func (d *dashboard) refresh(cfg config) bool {
+	d.mu.Lock()
+	if atomic.SwapInt32(&d.rebuild, 0) != 0 || !reflect.DeepEqual(d.projects, cfg.Projects) {
+		d.mu.Unlock()
+		return false
+	}
+	displayed := append([]displayedProject{}, d.displayed...)
+	d.mu.Unlock()
+
+	for _, p := range displayed {
+		p.Refresh()
+	}
+
+	return true
+}
+
+// build every services present in the configuration and display the projects.
+// The widgets are fetched without lock: the projects are only locked to be replaced.
+func (d *dashboard) build(cfg config) {
+	// The widgets of the previous projects are not rendered anymore.
+	d.mu.Lock()
+	d.projects, d.displayed = nil, nil
+	d.mu.Unlock()
+
+	displayed := []displayedProject{}
+	services := map[string]cachedService{}
+	for k, p := range cfg.Projects {
+		rows, sizes := p.OrderWidgets()
+		project := internal.NewProject(p.Name, p.NameOptions, rows, sizes, p.Themes, d.tui)
+
+		for _, f := range internal.RegisteredServices() {
+			options := f.WithEnv(p.Services[f.ConfigKey])
+			if !f.Configured(options) {
+				continue
+			}
+
+			key := fmt.Sprintf("%d.%s", k, f.ConfigKey)
+			s, ok := d.services[key]
+			if !ok || !reflect.DeepEqual(s.options, options) {
+				service, err := f.Create(options)
+				if err != nil {
+					internal.DisplayError(d.tui, err)()
+					// Try to create the service again at the next reload.
+					d.rebuildNext()
+					continue
+				}
+				s = cachedService{options: options, service: service}
+			}
+
+			services[key] = s
+			project.WithService(f.ID, s.service)
+		}
+
+		renderFuncs := project.CreateWidgets()
+		if !debug {
+			project.Render(renderFuncs)
+			if d.scheduler != nil {
+				project.Schedule(d.scheduler)
+			}
+		}
+		displayed = append(displayed, project)
+	}
+
+	d.mu.Lock()
+	d.projects, d.displayed = cfg.Projects, displayed
+	d.mu.Unlock()
+
+	// The services replaced are closed, like their connections.
+	for k, s := range d.services {
+		c, ok := s.service.(io.Closer)
+		if !ok {
+			continue
+		}
+		if n, ok := services[k]; ok && n.service == s.service {
+			continue
+		}
+		c.Close()
+	}
+
+	d.services = services
+}
+
+// renderedWidgets of every project displayed, with the name of their project.
+func (d *dashboard) renderedWidgets(f func(project string, id int, w internal.Widget)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for k, p := range d.displayed {
+		for id, w := range p.RenderedWidgets() {
+			f(d.projects[k].Name, id, w)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Phantas0s/devdash/internal"
+)
+
+type blockedProject struct {
+	refreshing chan bool
+	unblock    chan bool
+}
+
+func (p *blockedProject) Refresh() {
+	close(p.refreshing)
+	<-p.unblock
+}
+
+func (p *blockedProject) RenderedWidgets() map[int]internal.Widget {
+	return map[int]internal.Widget{1: {Name: "github.box_stars"}}
+}
+
+func Test_dashboardRefresh(t *testing.T) {
+	p := &blockedProject{refreshing: make(chan bool), unblock: make(chan bool)}
+	d := newDashboard(nil, nil)
+	d.projects = []Project{{Name: "devdash"}}
+	d.displayed = []displayedProject{p}
+
+	refreshed := make(chan bool)
+	go func() {
+		refreshed <- d.refresh(config{Projects: []Project{{Name: "devdash"}}})
+	}()
+	<-p.refreshing
+
+	// The widgets rendered are read while the widgets are refreshed.
+	read := make(chan bool)
+	go func() {
+		d.renderedWidgets(func(project string, id int, w internal.Widget) {})
+		close(read)
+	}()
+
+	select {
+	case <-read:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the widgets rendered to be read without waiting for the refresh")
+	}
+
+	close(p.unblock)
+	if !<-refreshed {
+		t.Errorf("Expected %v, actual %v", true, false)
+	}
+
+	if d.refresh(config{Projects: []Project{{Name: "termui"}}}) {
+		t.Errorf("Expected %v, actual %v", false, true)
+	}
+}
@@ -37,8 +37,6 @@ func generate(args []string) string {
 	default:
 		return createBlogDefaultConfig()
 	}
-
-	return "oops"
 }
 
 func createBlogDefaultConfig() string {
@@ -18,6 +18,8 @@ var (
 	cfgName string
 	logpath string
 	debug   bool
+	record  string
+	replay  string
 
 	rootCmd = &cobra.Command{
 		Use:   "devdash",
@@ -34,10 +36,15 @@ func init() {
 	// TODO logger
 	// rootCmd.Flags().StringVarP(&logpath, "logpath", "l", "", "Path for logging")
 	rootCmd.Flags().BoolVarP(&debug, "debug", "d", false, "Debug Mode - doesn't display graph")
+	rootCmd.PersistentFlags().StringVar(&record, "record", "", "Record the responses of every platform in a directory")
+	rootCmd.PersistentFlags().StringVar(&replay, "replay", "", "Display the dashboard with the responses recorded in a directory, without network")
+	rootCmd.MarkFlagsMutuallyExclusive("record", "replay")
 	rootCmd.AddCommand(listCmd())
 	rootCmd.AddCommand(versionCmd())
 	rootCmd.AddCommand(editCmd())
 	rootCmd.AddCommand(generateCmd())
+	rootCmd.AddCommand(snapshotCmd())
+	rootCmd.AddCommand(serveCmd())
 }
 
 func Execute() {
@@ -64,6 +71,12 @@ func run() {
 		fmt.Fprintf(os.Stdout, "Config file used: %s", cfgFile)
 	}
 
+	// Record the responses of the platforms, or replay them.
+	useRecorder()
+
+	// Share the API responses between widgets.
+	platform.UseCache(platform.NewCache(cfg.CacheTTL(), cfg.CachePath()))
+
 	// Passing a time.Time to this channel reload the entire dashboard.
 	hotReload := make(chan time.Time)
 
@@ -75,6 +88,10 @@ func run() {
 	stopAutoReload := make(chan bool)
 	autoReload(cfg.RefreshTime(), stopAutoReload, hotReload)
 
+	// Refresh the widgets having their own refresh interval.
+	scheduler := internal.NewScheduler()
+	dash := newDashboard(tui, scheduler)
+
 	editor := os.Getenv("EDITOR")
 	if cfg.General.Editor != "" {
 		editor = cfg.General.Editor
@@ -86,20 +103,22 @@ func run() {
 		cfg.KEdit(),
 		func() {
 			stopReload(stopAutoReload)
+			scheduler.Stop()
 			editDashboard(editor, cfgFile)
+			// The editor messed up the terminal: everything needs to be redrawn.
+			dash.rebuildNext()
 			hotReload <- time.Now()
 			autoReload(cfg.RefreshTime(), stopAutoReload, hotReload)
 		},
 	)
 
 	// First display.
-	build(cfgName, tui)
+	dash.build(cfg)
 
 	// Automatic reload
 	go func() {
 		for hr := range hotReload {
-			tui.HotReload()
-			build(cfgName, tui)
+			dash.reload(cfgName)
 			if debug {
 				fmt.Println("Last reload: " + hr.Format("2006-01-02 15:04:05"))
 			}
@@ -128,100 +147,14 @@ func stopReload(stopAutoReload chan<- bool) {
 	stopAutoReload <- true
 }
 
-// build every services present in the configuration
-func build(file string, tui *internal.Tui) {
-	cfg, _ := mapConfig(file)
-	for _, p := range cfg.Projects {
-		rows, sizes := p.OrderWidgets()
-		project := internal.NewProject(p.Name, p.NameOptions, rows, sizes, p.Themes, tui)
-
-		gaService := p.Services.GoogleAnalytics
-		if !gaService.empty() {
-			gaWidget, err := internal.NewGaWidget(gaService.Keyfile, gaService.ViewID)
-			if err != nil {
-				internal.DisplayError(tui, err)()
-			} else {
-				project.WithGa(gaWidget)
-			}
-		}
-
-		gscService := p.Services.GoogleSearchConsole
-		if !gscService.empty() {
-			gscWidget, err := internal.NewGscWidget(gscService.Keyfile, gscService.Address)
-			if err != nil {
-				internal.DisplayError(tui, err)()
-			} else {
-				project.WithGoogleSearchConsole(gscWidget)
-			}
-		}
-
-		monService := p.Services.Monitor
-		if !monService.empty() {
-			monWidget, err := internal.NewMonitorWidget(monService.Address)
-			if err != nil {
-				internal.DisplayError(tui, err)()
-			} else {
-				project.WithMonitor(monWidget)
-			}
-		}
-
-		githubService := p.Services.Github
-		if !githubService.empty() {
-			githubWidget, err := internal.NewGithubWidget(
-				githubService.Token,
-				githubService.Owner,
-				githubService.Repository,
-			)
-			if err != nil {
-				internal.DisplayError(tui, err)()
-			} else {
-				project.WithGithub(githubWidget)
-			}
-		}
-
-		travisService := p.Services.TravisCI
-		if !travisService.empty() {
-			travisWidget := internal.NewTravisCIWidget(travisService.Token)
-			project.WithTravisCI(travisWidget)
-		}
-
-		feedlyService := p.Services.Feedly
-		if !feedlyService.empty() {
-			feedlyWidget := internal.NewFeedlyWidget(feedlyService.Address)
-			project.WithFeedly(feedlyWidget)
-		}
-
-		gitService := p.Services.Git
-		if !gitService.empty() {
-			gitWidget := internal.NewGitWidget(gitService.Path)
-			project.WithGit(gitWidget)
-		}
-
-		remoteHostService := p.Services.RemoteHost
-		if !remoteHostService.empty() {
-			remoteHostWidget, err := internal.NewHostWidget(
-				remoteHostService.Username,
-				remoteHostService.Address,
-			)
-			if err != nil {
-				fmt.Println(err)
-				internal.DisplayError(tui, err)()
-			} else {
-				project.WithRemoteHost(remoteHostWidget)
-			}
-		}
-
-		localhost, err := internal.NewHostWidget("localhost", "localhost")
-		if err != nil {
-			fmt.Println(err)
-			internal.DisplayError(tui, err)()
-		}
-		project.WithLocalhost(localhost)
+// useRecorder depending on the flags --record and --replay.
+func useRecorder() {
+	if record != "" {
+		platform.Record(record)
+	}
 
-		renderFuncs := project.CreateWidgets()
-		if !debug {
-			project.Render(renderFuncs)
-		}
+	if replay != "" {
+		platform.Replay(replay)
 	}
 }
 
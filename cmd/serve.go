@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/Phantas0s/devdash/internal"
+	"github.com/Phantas0s/devdash/internal/platform"
+	"github.com/spf13/cobra"
+)
+
+const metricName = "devdash_widget_value"
+
+var metricsAddr string
+
+func serveCmd() *cobra.Command {
+	serveCmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Expose the values of the widgets of a dashboard as Prometheus metrics",
+		Long:  `Refresh the widgets of a dashboard like the terminal dashboard does, without terminal, and expose their numeric values at /metrics in the Prometheus text format.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := runServe(); err != nil {
+				fmt.Fprintln(os.Stderr, "Error: "+err.Error())
+				os.Exit(1)
+			}
+		},
+	}
+
+	serveCmd.Flags().StringVarP(&cfgName, "config", "c", "", "A valid dashboard configuration")
+	serveCmd.Flags().StringVarP(&metricsAddr, "metrics", "m", ":9100", "Address to listen to for the metrics endpoint")
+
+	return serveCmd
+}
+
+// runServe refresh the dashboard in a snapshot and serve the metrics till the server stops.
+func runServe() error {
+	snapshot := platform.NewSnapshot()
+	tui := internal.NewTUI(snapshot)
+
+	cfg, _ := mapConfig(cfgName)
+
+	useRecorder()
+	platform.UseCache(platform.NewCache(cfg.CacheTTL(), cfg.CachePath()))
+
+	dash := newDashboard(tui, internal.NewScheduler())
+	dash.build(cfg)
+
+	hotReload := make(chan time.Time)
+	autoReload(cfg.RefreshTime(), make(chan bool), hotReload)
+	go func() {
+		for range hotReload {
+			dash.reload(cfgName)
+		}
+	}()
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metricsHandler(dash, snapshot))
+
+	return http.ListenAndServe(metricsAddr, mux)
+}
+
+// metricsHandler writes the numeric values of every widget displayed in the snapshot.
+func metricsHandler(dash *dashboard, snapshot *platform.Snapshot) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		samples := []platform.Sample{}
+		dash.renderedWidgets(func(project string, id int, widget internal.Widget) {
+			cell, ok := snapshot.Cell(id)
+			if !ok {
+				return
+			}
+
+			for _, s := range cell.Samples() {
+				s.Labels["project"] = project
+				s.Labels["widget"] = widget.Name
+				// The same widget can be displayed more than once.
+				s.Labels["cell"] = strconv.Itoa(id)
+				samples = append(samples, s)
+			}
+		})
+
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		platform.WriteMetrics(w, metricName, "Numeric value displayed by a widget of the dashboard.", samples)
+	})
+}
@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Phantas0s/devdash/internal/platform"
+)
+
+func Test_metricsHandlerRefresh(t *testing.T) {
+	p := &blockedProject{refreshing: make(chan bool), unblock: make(chan bool)}
+	d := newDashboard(nil, nil)
+	d.projects = []Project{{Name: "devdash"}}
+	d.displayed = []displayedProject{p}
+
+	go d.refresh(config{Projects: []Project{{Name: "devdash"}}})
+	<-p.refreshing
+	defer close(p.unblock)
+
+	// The metrics are served while the widgets are refreshed.
+	served := make(chan int)
+	go func() {
+		rec := httptest.NewRecorder()
+		metricsHandler(d, platform.NewSnapshot()).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		served <- rec.Code
+	}()
+
+	select {
+	case code := <-served:
+		if code != http.StatusOK {
+			t.Errorf("Expected %v, actual %v", http.StatusOK, code)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the metrics to be served without waiting for the refresh")
+	}
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Phantas0s/devdash/internal"
+	"github.com/Phantas0s/devdash/internal/platform"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var format string
+
+func snapshotCmd() *cobra.Command {
+	snapshotCmd := &cobra.Command{
+		Use:   "snapshot",
+		Short: "Output the data of every widget of a dashboard, without terminal",
+		Long:  `Fetch the data of every widget of a dashboard once and output it as text, JSON or HTML. Useful to add a dashboard to reports or to compare it over time.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := runSnapshot(); err != nil {
+				fmt.Fprintln(os.Stderr, "Error: "+err.Error())
+				os.Exit(1)
+			}
+		},
+	}
+
+	snapshotCmd.Flags().StringVarP(&cfgName, "config", "c", "", "A valid dashboard configuration")
+	snapshotCmd.Flags().StringVarP(&format, "format", "f", platform.SnapshotText, "Format of the output: text, json or html")
+
+	return snapshotCmd
+}
+
+// runSnapshot render the dashboard once in a snapshot and write it on the standard output.
+func runSnapshot() error {
+	switch format {
+	case platform.SnapshotText, platform.SnapshotJSON, platform.SnapshotHTML:
+	default:
+		return errors.Errorf("unknown format %s (possible formats: text, json or html)", format)
+	}
+
+	snapshot := platform.NewSnapshot()
+	tui := internal.NewTUI(snapshot)
+
+	cfg, _ := mapConfig(cfgName)
+
+	useRecorder()
+	platform.UseCache(platform.NewCache(cfg.CacheTTL(), cfg.CachePath()))
+
+	newDashboard(tui, nil).build(cfg)
+
+	return snapshot.Write(os.Stdout, format)
+}
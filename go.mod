@@ -11,6 +11,7 @@ require (
 	github.com/maruel/panicparse v1.6.1 // indirect
 	github.com/mattn/go-runewidth v0.0.13 // indirect
 	github.com/mitchellh/go-wordwrap v1.0.1 // indirect
+	github.com/mitchellh/mapstructure v1.5.0
 	github.com/nsf/termbox-go v1.1.1 // indirect
 	github.com/pkg/errors v0.9.1
 	github.com/shuheiktgw/go-travis v0.3.1
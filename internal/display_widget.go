@@ -1,6 +1,9 @@
 package internal
 
-import "github.com/pkg/errors"
+import (
+	"github.com/Phantas0s/devdash/internal/platform"
+	"github.com/pkg/errors"
+)
 
 type displayWidget struct {
 	tui *Tui
@@ -12,6 +15,18 @@ func NewDisplayWidget() *displayWidget {
 	return &displayWidget{}
 }
 
+func init() {
+	RegisterService(ServiceFactory{
+		ID:      "display",
+		Name:    "Display",
+		Always:  true,
+		Widgets: []string{displayBox},
+		New: func(config interface{}) (Service, error) {
+			return NewDisplayWidget(), nil
+		},
+	})
+}
+
 func (d displayWidget) CreateWidgets(widget Widget, tui *Tui) (f func() error, err error) {
 	d.tui = tui
 
@@ -49,7 +64,7 @@ func (d displayWidget) box(widget Widget) (f func() error, err error) {
 
 func DisplayError(tui *Tui, err error) func() error {
 	return func() error {
-		return tui.AddTextBox(err.Error(), " ERROR ", map[string]string{
+		return tui.AddTextBox(err.Error(), " "+platform.ErrorTitle+" ", map[string]string{
 			optionBorderColor: "red",
 			optionTextColor:   "red",
 			optionTitleColor:  "red",
@@ -20,6 +20,23 @@ func NewFeedlyWidget(address string) *feedlyWidget {
 	}
 }
 
+type feedlyServiceConfig struct {
+	Address string `mapstructure:"address"`
+}
+
+func init() {
+	RegisterService(ServiceFactory{
+		ID:        "feedly",
+		Name:      "Feedly",
+		ConfigKey: "feedly",
+		Widgets:   []string{FeedlySubscribers},
+		Config:    func() interface{} { return &feedlyServiceConfig{} },
+		New: func(config interface{}) (Service, error) {
+			return NewFeedlyWidget(config.(*feedlyServiceConfig).Address), nil
+		},
+	})
+}
+
 func (f feedlyWidget) CreateWidgets(widget Widget, tui *Tui) (fu func() error, err error) {
 	f.tui = tui
 
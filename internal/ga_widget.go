@@ -50,6 +50,41 @@ func NewGaWidget(keyfile string, viewID string) (*gaWidget, error) {
 	}, nil
 }
 
+type gaServiceConfig struct {
+	Keyfile string `mapstructure:"keyfile"`
+	ViewID  string `mapstructure:"view_id"`
+}
+
+func init() {
+	RegisterService(ServiceFactory{
+		ID:        "ga",
+		Name:      "Google Analytics",
+		ConfigKey: "google_analytics",
+		Env:       map[string]string{"keyfile": "DEVDASH_GA_KEYFILE"},
+		Widgets: []string{
+			gaBoxRealtime,
+			gaBoxTotal,
+			gaBar,
+			gaBarSessions,
+			gaBarBounces,
+			gaBarUsers,
+			gaBarReturning,
+			gaBarNewReturning,
+			gaBarPages,
+			gaBarCountries,
+			gaBarDevices,
+			gaTablePages,
+			gaTableTrafficSources,
+			gaTable,
+		},
+		Config: func() interface{} { return &gaServiceConfig{} },
+		New: func(config interface{}) (Service, error) {
+			c := config.(*gaServiceConfig)
+			return NewGaWidget(c.Keyfile, c.ViewID)
+		},
+	})
+}
+
 // CreateWidgets for Google Analytics.
 func (g *gaWidget) CreateWidgets(widget Widget, tui *Tui) (f func() error, err error) {
 	g.tui = tui
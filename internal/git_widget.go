@@ -1,32 +1,88 @@
 package internal
 
 import (
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/Phantas0s/devdash/internal/platform"
 	"github.com/pkg/errors"
 )
 
 const (
-	gitBranches = "git.table_branches"
+	gitBranches     = "git.table_branches"
+	gitBarCommits   = "git.bar_commits"
+	gitTableCommits = "git.table_commits"
+	gitTableAuthors = "git.table_authors"
+	gitBoxStatus    = "git.box_status"
+	gitTableTags    = "git.table_tags"
+
+	gitTableRepositories = "git.table_repositories"
+	gitBoxRepositories   = "git.box_repositories"
 )
 
 type gitWidget struct {
-	tui    *Tui
-	client *platform.Git
+	tui          *Tui
+	client       *platform.Git
+	repositories *platform.GitRepositories
 }
 
-func NewGitWidget(path string) *gitWidget {
-	client := platform.NewGit(path)
+// NewGitWidget for the repository of the path, and for the repositories matching the paths (globs allowed).
+func NewGitWidget(path string, paths []string) *gitWidget {
 	return &gitWidget{
-		client: client,
+		client:       platform.NewGit(path),
+		repositories: platform.NewGitRepositories(paths),
 	}
 }
 
+type gitServiceConfig struct {
+	Path  string   `mapstructure:"path"`
+	Paths []string `mapstructure:"paths"`
+}
+
+func init() {
+	RegisterService(ServiceFactory{
+		ID:        "git",
+		Name:      "Git",
+		ConfigKey: "git",
+		Widgets: []string{
+			gitBranches,
+			gitBarCommits,
+			gitTableCommits,
+			gitTableAuthors,
+			gitBoxStatus,
+			gitTableTags,
+			gitTableRepositories,
+			gitBoxRepositories,
+		},
+		Config: func() interface{} { return &gitServiceConfig{} },
+		New: func(config interface{}) (Service, error) {
+			c := config.(*gitServiceConfig)
+			return NewGitWidget(c.Path, c.Paths), nil
+		},
+	})
+}
+
 func (g gitWidget) CreateWidgets(widget Widget, tui *Tui) (f func() error, err error) {
 	g.tui = tui
 
 	switch widget.Name {
 	case gitBranches:
 		f, err = g.branches(widget)
+	case gitBarCommits:
+		f, err = g.barCommits(widget)
+	case gitTableCommits:
+		f, err = g.tableCommits(widget)
+	case gitTableAuthors:
+		f, err = g.tableAuthors(widget)
+	case gitBoxStatus:
+		f, err = g.boxStatus(widget)
+	case gitTableTags:
+		f, err = g.tableTags(widget)
+	case gitTableRepositories:
+		f, err = g.tableRepositories(widget)
+	case gitBoxRepositories:
+		f, err = g.boxRepositories(widget)
 	default:
 		return nil, errors.Errorf("can't find the widget %s for service Git", widget.Name)
 	}
@@ -51,3 +107,192 @@ func (g gitWidget) branches(widget Widget) (f func() error, err error) {
 
 	return
 }
+
+func (g gitWidget) barCommits(widget Widget) (f func() error, err error) {
+	title := " Git Commits "
+	if _, ok := widget.Options[optionTitle]; ok {
+		title = widget.Options[optionTitle]
+	}
+
+	startDate := "7_days_ago"
+	if _, ok := widget.Options[optionStartDate]; ok {
+		startDate = widget.Options[optionStartDate]
+	}
+
+	endDate := "today"
+	if _, ok := widget.Options[optionEndDate]; ok {
+		endDate = widget.Options[optionEndDate]
+	}
+
+	// Per day or per week.
+	period := "day"
+	if _, ok := widget.Options[optionTimePeriod]; ok {
+		period = widget.Options[optionTimePeriod]
+	}
+
+	sd, ed, err := platform.ConvertDates(time.Now(), startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	dim, counts, err := g.client.CountCommits(sd, ed, period)
+	if err != nil {
+		return nil, err
+	}
+
+	f = func() error {
+		return g.tui.AddBarChart(counts, dim, title, widget.Options)
+	}
+
+	return
+}
+
+func (g gitWidget) tableCommits(widget Widget) (f func() error, err error) {
+	title := " Git Commits "
+	if _, ok := widget.Options[optionTitle]; ok {
+		title = widget.Options[optionTitle]
+	}
+
+	var limit int64 = 5
+	if _, ok := widget.Options[optionRowLimit]; ok {
+		limit, err = strconv.ParseInt(widget.Options[optionRowLimit], 10, 0)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s must be a number", widget.Options[optionRowLimit])
+		}
+	}
+
+	data, err := g.client.Commits(int(limit))
+	if err != nil {
+		return nil, err
+	}
+
+	f = func() error {
+		return g.tui.AddTable(data, title, widget.Options)
+	}
+
+	return
+}
+
+func (g gitWidget) tableAuthors(widget Widget) (f func() error, err error) {
+	title := " Git Authors "
+	if _, ok := widget.Options[optionTitle]; ok {
+		title = widget.Options[optionTitle]
+	}
+
+	var limit int64 = 5
+	if _, ok := widget.Options[optionRowLimit]; ok {
+		limit, err = strconv.ParseInt(widget.Options[optionRowLimit], 10, 0)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s must be a number", widget.Options[optionRowLimit])
+		}
+	}
+
+	data, err := g.client.Authors(int(limit))
+	if err != nil {
+		return nil, err
+	}
+
+	f = func() error {
+		return g.tui.AddTable(data, title, widget.Options)
+	}
+
+	return
+}
+
+func (g gitWidget) boxStatus(widget Widget) (f func() error, err error) {
+	title := " Git Status "
+	if _, ok := widget.Options[optionTitle]; ok {
+		title = widget.Options[optionTitle]
+	}
+
+	status, err := g.client.Status()
+	if err != nil {
+		return nil, err
+	}
+
+	f = func() error {
+		return g.tui.AddTextBox(status, title, widget.Options)
+	}
+
+	return
+}
+
+func (g gitWidget) tableTags(widget Widget) (f func() error, err error) {
+	title := " Git Tags "
+	if _, ok := widget.Options[optionTitle]; ok {
+		title = widget.Options[optionTitle]
+	}
+
+	var limit int64 = 5
+	if _, ok := widget.Options[optionRowLimit]; ok {
+		limit, err = strconv.ParseInt(widget.Options[optionRowLimit], 10, 0)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s must be a number", widget.Options[optionRowLimit])
+		}
+	}
+
+	data, err := g.client.Tags(int(limit))
+	if err != nil {
+		return nil, err
+	}
+
+	f = func() error {
+		return g.tui.AddTable(data, title, widget.Options)
+	}
+
+	return
+}
+
+func (g gitWidget) tableRepositories(widget Widget) (f func() error, err error) {
+	title := " Git Repositories "
+	if _, ok := widget.Options[optionTitle]; ok {
+		title = widget.Options[optionTitle]
+	}
+
+	var limit int64 = 10
+	if _, ok := widget.Options[optionRowLimit]; ok {
+		limit, err = strconv.ParseInt(widget.Options[optionRowLimit], 10, 0)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s must be a number", widget.Options[optionRowLimit])
+		}
+	}
+
+	// Only the repositories dirty, unpushed or behind.
+	filters := []string{}
+	if _, ok := widget.Options[optionFilters]; ok {
+		for _, v := range strings.Split(widget.Options[optionFilters], ",") {
+			if f := strings.TrimSpace(v); f != "" {
+				filters = append(filters, f)
+			}
+		}
+	}
+
+	data, err := g.repositories.ListRepositories(filters, int(limit))
+	if err != nil {
+		return nil, err
+	}
+
+	f = func() error {
+		return g.tui.AddTable(data, title, widget.Options)
+	}
+
+	return
+}
+
+func (g gitWidget) boxRepositories(widget Widget) (f func() error, err error) {
+	title := " Git Repositories "
+	if _, ok := widget.Options[optionTitle]; ok {
+		title = widget.Options[optionTitle]
+	}
+
+	summary, err := g.repositories.Summary()
+	if err != nil {
+		return nil, err
+	}
+
+	f = func() error {
+		return g.tui.AddTextBox(summary, title, widget.Options)
+	}
+
+	return
+}
@@ -0,0 +1,243 @@
+package internal
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Phantas0s/devdash/internal/platform"
+	"github.com/pkg/errors"
+)
+
+const (
+	giteaBoxStars          = "gitea.box_stars"
+	giteaTableIssues       = "gitea.table_issues"
+	giteaTablePullRequests = "gitea.table_pull_requests"
+	giteaTableReleases     = "gitea.table_releases"
+	giteaBarCommits        = "gitea.bar_commits"
+)
+
+type giteaWidget struct {
+	tui    *Tui
+	client *platform.Gitea
+}
+
+// NewGiteaWidget with all information necessary to connect to the Gitea API.
+func NewGiteaWidget(baseURL string, token string, owner string, repo string) (*giteaWidget, error) {
+	g, err := platform.NewGiteaClient(baseURL, token, owner, repo)
+	if err != nil {
+		return nil, err
+	}
+	return &giteaWidget{
+		client: g,
+	}, nil
+}
+
+type giteaServiceConfig struct {
+	BaseURL    string `mapstructure:"base_url"`
+	Token      string `mapstructure:"token"`
+	Owner      string `mapstructure:"owner"`
+	Repository string `mapstructure:"repository"`
+}
+
+func init() {
+	RegisterService(ServiceFactory{
+		ID:        "gitea",
+		Name:      "Gitea",
+		ConfigKey: "gitea",
+		Widgets: []string{
+			giteaBoxStars,
+			giteaTableIssues,
+			giteaTablePullRequests,
+			giteaTableReleases,
+			giteaBarCommits,
+		},
+		Config: func() interface{} { return &giteaServiceConfig{} },
+		New: func(config interface{}) (Service, error) {
+			c := config.(*giteaServiceConfig)
+			return NewGiteaWidget(c.BaseURL, c.Token, c.Owner, c.Repository)
+		},
+	})
+}
+
+// CreateWidgets for the Gitea service.
+func (g *giteaWidget) CreateWidgets(widget Widget, tui *Tui) (f func() error, err error) {
+	g.tui = tui
+
+	switch widget.Name {
+	case giteaBoxStars:
+		f, err = g.boxStars(widget)
+	case giteaTableIssues:
+		f, err = g.tableIssues(widget)
+	case giteaTablePullRequests:
+		f, err = g.tablePullRequests(widget)
+	case giteaTableReleases:
+		f, err = g.tableReleases(widget)
+	case giteaBarCommits:
+		f, err = g.barCommits(widget)
+	default:
+		return nil, errors.Errorf("can't find the widget %s for service gitea", widget.Name)
+	}
+
+	return
+}
+
+func (g *giteaWidget) boxStars(widget Widget) (f func() error, err error) {
+	var repo string
+	if _, ok := widget.Options[optionRepository]; ok {
+		repo = widget.Options[optionRepository]
+	}
+
+	title := fmt.Sprintf(" Gitea Stars for %s", repo)
+	if _, ok := widget.Options[optionTitle]; ok {
+		title = widget.Options[optionTitle]
+	}
+
+	stars, err := g.client.TotalStars(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	s := strconv.FormatInt(int64(stars), 10)
+
+	f = func() error {
+		return g.tui.AddTextBox(
+			s,
+			title,
+			widget.Options,
+		)
+	}
+
+	return
+}
+
+func (g *giteaWidget) tableIssues(widget Widget) (f func() error, err error) {
+	var repo string
+	if _, ok := widget.Options[optionRepository]; ok {
+		repo = widget.Options[optionRepository]
+	}
+
+	title := " Gitea Issues "
+	if _, ok := widget.Options[optionTitle]; ok {
+		title = widget.Options[optionTitle]
+	}
+
+	var limit int64 = 5
+	if _, ok := widget.Options[optionRowLimit]; ok {
+		limit, err = strconv.ParseInt(widget.Options[optionRowLimit], 10, 0)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s must be a number", widget.Options[optionRowLimit])
+		}
+	}
+
+	is, err := g.client.ListIssues(repo, int(limit))
+	if err != nil {
+		return nil, err
+	}
+
+	f = func() error {
+		return g.tui.AddTable(is, title, widget.Options)
+	}
+
+	return
+}
+
+func (g *giteaWidget) tablePullRequests(widget Widget) (f func() error, err error) {
+	var repo string
+	if _, ok := widget.Options[optionRepository]; ok {
+		repo = widget.Options[optionRepository]
+	}
+
+	title := " Gitea Pull Requests "
+	if _, ok := widget.Options[optionTitle]; ok {
+		title = widget.Options[optionTitle]
+	}
+
+	var limit int64 = 5
+	if _, ok := widget.Options[optionRowLimit]; ok {
+		limit, err = strconv.ParseInt(widget.Options[optionRowLimit], 10, 0)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s must be a number", widget.Options[optionRowLimit])
+		}
+	}
+
+	prs, err := g.client.ListPullRequests(repo, int(limit))
+	if err != nil {
+		return nil, err
+	}
+
+	f = func() error {
+		return g.tui.AddTable(prs, title, widget.Options)
+	}
+
+	return
+}
+
+func (g *giteaWidget) tableReleases(widget Widget) (f func() error, err error) {
+	var repo string
+	if _, ok := widget.Options[optionRepository]; ok {
+		repo = widget.Options[optionRepository]
+	}
+
+	title := " Gitea Releases "
+	if _, ok := widget.Options[optionTitle]; ok {
+		title = widget.Options[optionTitle]
+	}
+
+	var limit int64 = 5
+	if _, ok := widget.Options[optionRowLimit]; ok {
+		limit, err = strconv.ParseInt(widget.Options[optionRowLimit], 10, 0)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s must be a number", widget.Options[optionRowLimit])
+		}
+	}
+
+	rs, err := g.client.ListReleases(repo, int(limit))
+	if err != nil {
+		return nil, err
+	}
+
+	f = func() error {
+		return g.tui.AddTable(rs, title, widget.Options)
+	}
+
+	return
+}
+
+func (g *giteaWidget) barCommits(widget Widget) (f func() error, err error) {
+	var repo string
+	if _, ok := widget.Options[optionRepository]; ok {
+		repo = widget.Options[optionRepository]
+	}
+
+	title := " Gitea Commit Per Week "
+	if _, ok := widget.Options[optionTitle]; ok {
+		title = widget.Options[optionTitle]
+	}
+
+	startDate := "7_weeks_ago"
+	if _, ok := widget.Options[optionStartDate]; ok {
+		startDate = widget.Options[optionStartDate]
+	}
+
+	endDate := "today"
+	if _, ok := widget.Options[optionEndDate]; ok {
+		endDate = widget.Options[optionEndDate]
+	}
+
+	sd, ed, err := platform.ConvertDates(time.Now(), startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	dim, counts, err := g.client.CountCommits(repo, sd, ed)
+	if err != nil {
+		return nil, err
+	}
+
+	f = func() error {
+		return g.tui.AddBarChart(counts, dim, title, widget.Options)
+	}
+
+	return
+}
@@ -21,6 +21,21 @@ const (
 	githubBarViews          = "github.bar_views"
 	githubBarCommits        = "github.bar_commits"
 	githubBarStars          = "github.bar_stars"
+
+	githubTableWorkflowRuns = "github.table_workflow_runs"
+	githubBoxLastRunStatus  = "github.box_last_run_status"
+	githubBarRunDurations   = "github.bar_run_durations"
+	githubTableReleases     = "github.table_releases"
+	githubBarDownloads      = "github.bar_downloads"
+
+	githubTableReviewRequests = "github.table_review_requests"
+
+	githubBoxOrgOpenIssues          = "github.box_org_open_issues"
+	githubBarOrgPullRequests        = "github.bar_org_pull_requests"
+	githubTableOrgStalePullRequests = "github.table_org_stale_pull_requests"
+	githubBarOrgCommits             = "github.bar_org_commits"
+
+	githubBoxRateLimit = "github.box_rate_limit"
 )
 
 type githubWidget struct {
@@ -29,8 +44,14 @@ type githubWidget struct {
 }
 
 // NewGithubWidget with all information necessary to connect to the Github API.
-func NewGithubWidget(token string, owner string, repo string) (*githubWidget, error) {
-	g, err := platform.NewGithubClient(token, owner, repo)
+func NewGithubWidget(
+	token string,
+	owner string,
+	repo string,
+	api string,
+	enterprise platform.GithubEnterprise,
+) (*githubWidget, error) {
+	g, err := platform.NewGithubClient(token, owner, repo, api, enterprise)
 	if err != nil {
 		return nil, err
 	}
@@ -39,6 +60,60 @@ func NewGithubWidget(token string, owner string, repo string) (*githubWidget, er
 	}, nil
 }
 
+type githubServiceConfig struct {
+	Token      string `mapstructure:"token"`
+	Owner      string `mapstructure:"owner"`
+	Repository string `mapstructure:"repository"`
+	// API is "rest" (default) or "graphql".
+	API string `mapstructure:"api"`
+
+	// Github Enterprise Server
+	BaseURL   string `mapstructure:"base_url"`
+	UploadURL string `mapstructure:"upload_url"`
+	CACert    string `mapstructure:"ca_cert"`
+}
+
+func init() {
+	RegisterService(ServiceFactory{
+		ID:        "github",
+		Name:      "Github",
+		ConfigKey: "github",
+		Env:       map[string]string{"token": "DEVDASH_GITHUB_TOKEN"},
+		Widgets: []string{
+			githubBoxStars,
+			githubBoxWatchers,
+			githubBoxOpenIssues,
+			githubTableRepositories,
+			githubTableBranches,
+			githubTableIssues,
+			githubTablePullRequests,
+			githubBarViews,
+			githubBarCommits,
+			githubBarStars,
+			githubTableWorkflowRuns,
+			githubBoxLastRunStatus,
+			githubBarRunDurations,
+			githubTableReleases,
+			githubBarDownloads,
+			githubTableReviewRequests,
+			githubBoxOrgOpenIssues,
+			githubBarOrgPullRequests,
+			githubTableOrgStalePullRequests,
+			githubBarOrgCommits,
+			githubBoxRateLimit,
+		},
+		Config: func() interface{} { return &githubServiceConfig{} },
+		New: func(config interface{}) (Service, error) {
+			c := config.(*githubServiceConfig)
+			return NewGithubWidget(c.Token, c.Owner, c.Repository, c.API, platform.GithubEnterprise{
+				BaseURL:   c.BaseURL,
+				UploadURL: c.UploadURL,
+				CACert:    c.CACert,
+			})
+		},
+	})
+}
+
 // CreateWidgets for the Github service.
 func (g *githubWidget) CreateWidgets(widget Widget, tui *Tui) (f func() error, err error) {
 	g.tui = tui
@@ -64,6 +139,28 @@ func (g *githubWidget) CreateWidgets(widget Widget, tui *Tui) (f func() error, e
 		f, err = g.barCommits(widget)
 	case githubBarStars:
 		f, err = g.barStars(widget)
+	case githubTableWorkflowRuns:
+		f, err = g.tableWorkflowRuns(widget)
+	case githubBoxLastRunStatus:
+		f, err = g.boxLastRunStatus(widget)
+	case githubBarRunDurations:
+		f, err = g.barRunDurations(widget)
+	case githubTableReleases:
+		f, err = g.tableReleases(widget)
+	case githubBarDownloads:
+		f, err = g.barDownloads(widget)
+	case githubTableReviewRequests:
+		f, err = g.tableReviewRequests(widget)
+	case githubBoxOrgOpenIssues:
+		f, err = g.boxOrgOpenIssues(widget)
+	case githubBarOrgPullRequests:
+		f, err = g.barOrgPullRequests(widget)
+	case githubTableOrgStalePullRequests:
+		f, err = g.tableOrgStalePullRequests(widget)
+	case githubBarOrgCommits:
+		f, err = g.barOrgCommits(widget)
+	case githubBoxRateLimit:
+		f, err = g.boxRateLimit(widget)
 	default:
 		return nil, errors.Errorf("can't find the widget %s for service github", widget.Name)
 	}
@@ -403,3 +500,416 @@ func (g *githubWidget) barStars(widget Widget) (f func() error, err error) {
 
 	return
 }
+
+func (g *githubWidget) tableWorkflowRuns(widget Widget) (f func() error, err error) {
+	var repo string
+	if _, ok := widget.Options[optionRepository]; ok {
+		repo = widget.Options[optionRepository]
+	}
+
+	title := " Github Workflow Runs "
+	if _, ok := widget.Options[optionTitle]; ok {
+		title = widget.Options[optionTitle]
+	}
+
+	var limit int64 = 5
+	if _, ok := widget.Options[optionRowLimit]; ok {
+		limit, err = strconv.ParseInt(widget.Options[optionRowLimit], 10, 0)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s must be a number", widget.Options[optionRowLimit])
+		}
+	}
+
+	rs, err := g.client.ListWorkflowRuns(repo, runFilter(widget), int(limit))
+	if err != nil {
+		return nil, err
+	}
+
+	f = func() error {
+		return g.tui.AddTable(rs, title, widget.Options)
+	}
+
+	return
+}
+
+func (g *githubWidget) boxLastRunStatus(widget Widget) (f func() error, err error) {
+	var repo string
+	if _, ok := widget.Options[optionRepository]; ok {
+		repo = widget.Options[optionRepository]
+	}
+
+	title := " Github Last Run "
+	if _, ok := widget.Options[optionTitle]; ok {
+		title = widget.Options[optionTitle]
+	}
+
+	status, err := g.client.LastRunStatus(repo, runFilter(widget))
+	if err != nil {
+		return nil, err
+	}
+
+	f = func() error {
+		return g.tui.AddTextBox(
+			status,
+			title,
+			widget.Options,
+		)
+	}
+
+	return
+}
+
+func (g *githubWidget) barRunDurations(widget Widget) (f func() error, err error) {
+	var repo string
+	if _, ok := widget.Options[optionRepository]; ok {
+		repo = widget.Options[optionRepository]
+	}
+
+	title := " Github Run Durations (s) "
+	if _, ok := widget.Options[optionTitle]; ok {
+		title = widget.Options[optionTitle]
+	}
+
+	var limit int64 = 10
+	if _, ok := widget.Options[optionRowLimit]; ok {
+		limit, err = strconv.ParseInt(widget.Options[optionRowLimit], 10, 0)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s must be a number", widget.Options[optionRowLimit])
+		}
+	}
+
+	dim, durations, err := g.client.RunDurations(repo, runFilter(widget), int(limit))
+	if err != nil {
+		return nil, err
+	}
+
+	f = func() error {
+		return g.tui.AddBarChart(durations, dim, title, widget.Options)
+	}
+
+	return
+}
+
+func (g *githubWidget) tableReleases(widget Widget) (f func() error, err error) {
+	var repo string
+	if _, ok := widget.Options[optionRepository]; ok {
+		repo = widget.Options[optionRepository]
+	}
+
+	title := " Github Releases "
+	if _, ok := widget.Options[optionTitle]; ok {
+		title = widget.Options[optionTitle]
+	}
+
+	var limit int64 = 5
+	if _, ok := widget.Options[optionRowLimit]; ok {
+		limit, err = strconv.ParseInt(widget.Options[optionRowLimit], 10, 0)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s must be a number", widget.Options[optionRowLimit])
+		}
+	}
+
+	rs, err := g.client.ListReleases(repo, int(limit))
+	if err != nil {
+		return nil, err
+	}
+
+	f = func() error {
+		return g.tui.AddTable(rs, title, widget.Options)
+	}
+
+	return
+}
+
+func (g *githubWidget) barDownloads(widget Widget) (f func() error, err error) {
+	var repo string
+	if _, ok := widget.Options[optionRepository]; ok {
+		repo = widget.Options[optionRepository]
+	}
+
+	title := " Github Downloads "
+	if _, ok := widget.Options[optionTitle]; ok {
+		title = widget.Options[optionTitle]
+	}
+
+	// Per release or per asset.
+	dimension := "release"
+	if _, ok := widget.Options[optionDimension]; ok {
+		dimension = widget.Options[optionDimension]
+	}
+
+	var tag string
+	if _, ok := widget.Options[optionTag]; ok {
+		tag = widget.Options[optionTag]
+	}
+
+	var limit int64 = 5
+	if _, ok := widget.Options[optionRowLimit]; ok {
+		limit, err = strconv.ParseInt(widget.Options[optionRowLimit], 10, 0)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s must be a number", widget.Options[optionRowLimit])
+		}
+	}
+
+	dim, downloads, err := g.client.CountDownloads(repo, dimension, tag, int(limit))
+	if err != nil {
+		return nil, err
+	}
+
+	f = func() error {
+		return g.tui.AddBarChart(downloads, dim, title, widget.Options)
+	}
+
+	return
+}
+
+// tableReviewRequests lists the pull requests waiting for a review of the user or the team.
+// The authenticated user is used if both are empty.
+func (g *githubWidget) tableReviewRequests(widget Widget) (f func() error, err error) {
+	var repo string
+	if _, ok := widget.Options[optionRepository]; ok {
+		repo = widget.Options[optionRepository]
+	}
+
+	var user string
+	if _, ok := widget.Options[optionUser]; ok {
+		user = widget.Options[optionUser]
+	}
+
+	var team string
+	if _, ok := widget.Options[optionTeam]; ok {
+		team = widget.Options[optionTeam]
+	}
+
+	title := " Github Review Requests "
+	if _, ok := widget.Options[optionTitle]; ok {
+		title = widget.Options[optionTitle]
+	}
+
+	var limit int64 = 5
+	if _, ok := widget.Options[optionRowLimit]; ok {
+		limit, err = strconv.ParseInt(widget.Options[optionRowLimit], 10, 0)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s must be a number", widget.Options[optionRowLimit])
+		}
+	}
+
+	rrs, err := g.client.ListReviewRequests(repo, user, team, int(limit))
+	if err != nil {
+		return nil, err
+	}
+
+	f = func() error {
+		return g.tui.AddTable(rrs, title, widget.Options)
+	}
+
+	return
+}
+
+func (g *githubWidget) boxOrgOpenIssues(widget Widget) (f func() error, err error) {
+	owner, repos := orgRepositories(widget)
+
+	title := " Github Open Issues "
+	if _, ok := widget.Options[optionTitle]; ok {
+		title = widget.Options[optionTitle]
+	}
+
+	count, err := g.client.OrgOpenIssues(owner, repos)
+	if err != nil {
+		return nil, err
+	}
+
+	s := strconv.FormatInt(int64(count), 10)
+
+	f = func() error {
+		return g.tui.AddTextBox(
+			s,
+			title,
+			widget.Options,
+		)
+	}
+
+	return
+}
+
+func (g *githubWidget) barOrgPullRequests(widget Widget) (f func() error, err error) {
+	owner, repos := orgRepositories(widget)
+
+	title := " Github Open Pull Requests "
+	if _, ok := widget.Options[optionTitle]; ok {
+		title = widget.Options[optionTitle]
+	}
+
+	var limit int64 = 10
+	if _, ok := widget.Options[optionRowLimit]; ok {
+		limit, err = strconv.ParseInt(widget.Options[optionRowLimit], 10, 0)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s must be a number", widget.Options[optionRowLimit])
+		}
+	}
+
+	dim, counts, err := g.client.OrgPullRequests(owner, repos, int(limit))
+	if err != nil {
+		return nil, err
+	}
+
+	f = func() error {
+		return g.tui.AddBarChart(counts, dim, title, widget.Options)
+	}
+
+	return
+}
+
+func (g *githubWidget) tableOrgStalePullRequests(widget Widget) (f func() error, err error) {
+	owner, repos := orgRepositories(widget)
+
+	title := " Github Stale Pull Requests "
+	if _, ok := widget.Options[optionTitle]; ok {
+		title = widget.Options[optionTitle]
+	}
+
+	var days int64 = 14
+	if _, ok := widget.Options[optionStaleDays]; ok {
+		days, err = strconv.ParseInt(widget.Options[optionStaleDays], 10, 0)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s must be a number", widget.Options[optionStaleDays])
+		}
+	}
+
+	var limit int64 = 5
+	if _, ok := widget.Options[optionRowLimit]; ok {
+		limit, err = strconv.ParseInt(widget.Options[optionRowLimit], 10, 0)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s must be a number", widget.Options[optionRowLimit])
+		}
+	}
+
+	since := time.Now().AddDate(0, 0, -int(days))
+	prs, err := g.client.OrgStalePullRequests(owner, repos, since, int(limit))
+	if err != nil {
+		return nil, err
+	}
+
+	f = func() error {
+		return g.tui.AddTable(prs, title, widget.Options)
+	}
+
+	return
+}
+
+func (g *githubWidget) barOrgCommits(widget Widget) (f func() error, err error) {
+	owner, repos := orgRepositories(widget)
+
+	title := " Github Commit Per Week "
+	if _, ok := widget.Options[optionTitle]; ok {
+		title = widget.Options[optionTitle]
+	}
+
+	sd := "7_weeks_ago"
+	if _, ok := widget.Options[optionStartDate]; ok {
+		sd = widget.Options[optionStartDate]
+	}
+
+	ed := "0_weeks_ago"
+	if _, ok := widget.Options[optionEndDate]; ok {
+		ed = widget.Options[optionEndDate]
+	}
+
+	scope := ownerScope
+	if _, ok := widget.Options[optionScope]; ok {
+		scope = widget.Options[optionScope]
+	}
+
+	if !strings.Contains(sd, "weeks_ago") || !strings.Contains(ed, "weeks_ago") {
+		return nil, errors.New("The widget github.bar_org_commits require you to indicate a week range, ie startDate: 5_weeks_ago, endDate: 1_weeks_ago ")
+	}
+
+	sw, err := platform.ExtractCountPeriod(sd)
+	if err != nil {
+		return nil, err
+	}
+
+	ew, err := platform.ExtractCountPeriod(ed)
+	if err != nil {
+		return nil, err
+	}
+
+	dim, counts, err := g.client.OrgCountCommits(owner, repos, scope, sw, ew, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	f = func() error {
+		return g.tui.AddBarChart(counts, dim, title, widget.Options)
+	}
+
+	return
+}
+
+func (g *githubWidget) boxRateLimit(widget Widget) (f func() error, err error) {
+	// The core API by default, or search or graphql.
+	resource := "core"
+	if _, ok := widget.Options[optionResource]; ok {
+		resource = widget.Options[optionResource]
+	}
+
+	remaining, limit, reset, err := g.client.RateLimit(resource)
+	if err != nil {
+		return nil, err
+	}
+
+	title := fmt.Sprintf(" Github Rate Limit (%d, reset at %s) ", limit, reset.Local().Format("15:04"))
+	if resource != "core" {
+		title = fmt.Sprintf(" Github %s Rate Limit (%d, reset at %s) ", strings.Title(resource), limit, reset.Local().Format("15:04"))
+	}
+	if _, ok := widget.Options[optionTitle]; ok {
+		title = widget.Options[optionTitle]
+	}
+
+	s := strconv.FormatInt(int64(remaining), 10)
+
+	f = func() error {
+		return g.tui.AddTextBox(
+			s,
+			title,
+			widget.Options,
+		)
+	}
+
+	return
+}
+
+// orgRepositories aggregated by a widget: every repository of the owner if no repository is given.
+func orgRepositories(widget Widget) (owner string, repos []string) {
+	if _, ok := widget.Options[optionOwner]; ok {
+		owner = widget.Options[optionOwner]
+	}
+
+	if _, ok := widget.Options[optionRepositories]; ok {
+		for _, v := range strings.Split(widget.Options[optionRepositories], ",") {
+			if r := strings.TrimSpace(v); r != "" {
+				repos = append(repos, r)
+			}
+		}
+	}
+
+	return owner, repos
+}
+
+// runFilter of the workflow runs from the options of the widget.
+func runFilter(widget Widget) platform.GithubRunFilter {
+	filter := platform.GithubRunFilter{}
+	if _, ok := widget.Options[optionWorkflow]; ok {
+		filter.Workflow = widget.Options[optionWorkflow]
+	}
+
+	if _, ok := widget.Options[optionBranch]; ok {
+		filter.Branch = widget.Options[optionBranch]
+	}
+
+	if _, ok := widget.Options[optionEvent]; ok {
+		filter.Event = widget.Options[optionEvent]
+	}
+
+	return filter
+}
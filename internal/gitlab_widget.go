@@ -0,0 +1,320 @@
+package internal
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Phantas0s/devdash/internal/platform"
+	"github.com/pkg/errors"
+)
+
+const (
+	gitlabBoxStars           = "gitlab.box_stars"
+	gitlabBoxPipelineStatus  = "gitlab.box_pipeline_status"
+	gitlabTableIssues        = "gitlab.table_issues"
+	gitlabTableMergeRequests = "gitlab.table_merge_requests"
+	gitlabTableBranches      = "gitlab.table_branches"
+	gitlabTablePipelines     = "gitlab.table_pipelines"
+	gitlabBarCommits         = "gitlab.bar_commits"
+)
+
+type gitlabWidget struct {
+	tui    *Tui
+	client *platform.Gitlab
+}
+
+// NewGitlabWidget with all information necessary to connect to the Gitlab API.
+func NewGitlabWidget(baseURL string, token string, owner string, repo string) (*gitlabWidget, error) {
+	g, err := platform.NewGitlabClient(baseURL, token, owner, repo)
+	if err != nil {
+		return nil, err
+	}
+	return &gitlabWidget{
+		client: g,
+	}, nil
+}
+
+type gitlabServiceConfig struct {
+	BaseURL    string `mapstructure:"base_url"`
+	Token      string `mapstructure:"token"`
+	Owner      string `mapstructure:"owner"`
+	Repository string `mapstructure:"repository"`
+}
+
+func init() {
+	RegisterService(ServiceFactory{
+		ID:        "gitlab",
+		Name:      "Gitlab",
+		ConfigKey: "gitlab",
+		Env:       map[string]string{"token": "DEVDASH_GITLAB_TOKEN"},
+		Widgets: []string{
+			gitlabBoxStars,
+			gitlabBoxPipelineStatus,
+			gitlabTableIssues,
+			gitlabTableMergeRequests,
+			gitlabTableBranches,
+			gitlabTablePipelines,
+			gitlabBarCommits,
+		},
+		Config: func() interface{} { return &gitlabServiceConfig{} },
+		New: func(config interface{}) (Service, error) {
+			c := config.(*gitlabServiceConfig)
+			return NewGitlabWidget(c.BaseURL, c.Token, c.Owner, c.Repository)
+		},
+	})
+}
+
+// CreateWidgets for the Gitlab service.
+func (g *gitlabWidget) CreateWidgets(widget Widget, tui *Tui) (f func() error, err error) {
+	g.tui = tui
+
+	switch widget.Name {
+	case gitlabBoxStars:
+		f, err = g.boxStars(widget)
+	case gitlabBoxPipelineStatus:
+		f, err = g.boxPipelineStatus(widget)
+	case gitlabTableIssues:
+		f, err = g.tableIssues(widget)
+	case gitlabTableMergeRequests:
+		f, err = g.tableMergeRequests(widget)
+	case gitlabTableBranches:
+		f, err = g.tableBranches(widget)
+	case gitlabTablePipelines:
+		f, err = g.tablePipelines(widget)
+	case gitlabBarCommits:
+		f, err = g.barCommits(widget)
+	default:
+		return nil, errors.Errorf("can't find the widget %s for service gitlab", widget.Name)
+	}
+
+	return
+}
+
+func (g *gitlabWidget) boxStars(widget Widget) (f func() error, err error) {
+	var repo string
+	if _, ok := widget.Options[optionRepository]; ok {
+		repo = widget.Options[optionRepository]
+	}
+
+	title := fmt.Sprintf(" Gitlab Stars for %s", repo)
+	if _, ok := widget.Options[optionTitle]; ok {
+		title = widget.Options[optionTitle]
+	}
+
+	stars, err := g.client.TotalStars(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	s := strconv.FormatInt(int64(stars), 10)
+
+	f = func() error {
+		return g.tui.AddTextBox(
+			s,
+			title,
+			widget.Options,
+		)
+	}
+
+	return
+}
+
+func (g *gitlabWidget) boxPipelineStatus(widget Widget) (f func() error, err error) {
+	var repo string
+	if _, ok := widget.Options[optionRepository]; ok {
+		repo = widget.Options[optionRepository]
+	}
+
+	var branch string
+	if _, ok := widget.Options[optionBranch]; ok {
+		branch = widget.Options[optionBranch]
+	}
+
+	title := " Gitlab Pipeline "
+	if _, ok := widget.Options[optionTitle]; ok {
+		title = widget.Options[optionTitle]
+	}
+
+	status, err := g.client.PipelineStatus(repo, branch)
+	if err != nil {
+		return nil, err
+	}
+
+	f = func() error {
+		return g.tui.AddTextBox(
+			status,
+			title,
+			widget.Options,
+		)
+	}
+
+	return
+}
+
+func (g *gitlabWidget) tableIssues(widget Widget) (f func() error, err error) {
+	var repo string
+	if _, ok := widget.Options[optionRepository]; ok {
+		repo = widget.Options[optionRepository]
+	}
+
+	title := " Gitlab Issues "
+	if _, ok := widget.Options[optionTitle]; ok {
+		title = widget.Options[optionTitle]
+	}
+
+	var limit int64 = 5
+	if _, ok := widget.Options[optionRowLimit]; ok {
+		limit, err = strconv.ParseInt(widget.Options[optionRowLimit], 10, 0)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s must be a number", widget.Options[optionRowLimit])
+		}
+	}
+
+	is, err := g.client.ListIssues(repo, int(limit))
+	if err != nil {
+		return nil, err
+	}
+
+	f = func() error {
+		return g.tui.AddTable(is, title, widget.Options)
+	}
+
+	return
+}
+
+func (g *gitlabWidget) tableMergeRequests(widget Widget) (f func() error, err error) {
+	var repo string
+	if _, ok := widget.Options[optionRepository]; ok {
+		repo = widget.Options[optionRepository]
+	}
+
+	title := " Gitlab Merge Requests "
+	if _, ok := widget.Options[optionTitle]; ok {
+		title = widget.Options[optionTitle]
+	}
+
+	var limit int64 = 5
+	if _, ok := widget.Options[optionRowLimit]; ok {
+		limit, err = strconv.ParseInt(widget.Options[optionRowLimit], 10, 0)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s must be a number", widget.Options[optionRowLimit])
+		}
+	}
+
+	mrs, err := g.client.ListMergeRequests(repo, int(limit))
+	if err != nil {
+		return nil, err
+	}
+
+	f = func() error {
+		return g.tui.AddTable(mrs, title, widget.Options)
+	}
+
+	return
+}
+
+func (g *gitlabWidget) tableBranches(widget Widget) (f func() error, err error) {
+	var repo string
+	if _, ok := widget.Options[optionRepository]; ok {
+		repo = widget.Options[optionRepository]
+	}
+
+	title := " Gitlab Branches "
+	if _, ok := widget.Options[optionTitle]; ok {
+		title = widget.Options[optionTitle]
+	}
+
+	var limit int64 = 5
+	if _, ok := widget.Options[optionRowLimit]; ok {
+		limit, err = strconv.ParseInt(widget.Options[optionRowLimit], 10, 0)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s must be a number", widget.Options[optionRowLimit])
+		}
+	}
+
+	bs, err := g.client.ListBranches(repo, int(limit))
+	if err != nil {
+		return nil, err
+	}
+
+	f = func() error {
+		return g.tui.AddTable(bs, title, widget.Options)
+	}
+
+	return
+}
+
+func (g *gitlabWidget) tablePipelines(widget Widget) (f func() error, err error) {
+	var repo string
+	if _, ok := widget.Options[optionRepository]; ok {
+		repo = widget.Options[optionRepository]
+	}
+
+	var branch string
+	if _, ok := widget.Options[optionBranch]; ok {
+		branch = widget.Options[optionBranch]
+	}
+
+	title := " Gitlab Pipelines "
+	if _, ok := widget.Options[optionTitle]; ok {
+		title = widget.Options[optionTitle]
+	}
+
+	var limit int64 = 5
+	if _, ok := widget.Options[optionRowLimit]; ok {
+		limit, err = strconv.ParseInt(widget.Options[optionRowLimit], 10, 0)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s must be a number", widget.Options[optionRowLimit])
+		}
+	}
+
+	ps, err := g.client.ListPipelines(repo, branch, int(limit))
+	if err != nil {
+		return nil, err
+	}
+
+	f = func() error {
+		return g.tui.AddTable(ps, title, widget.Options)
+	}
+
+	return
+}
+
+func (g *gitlabWidget) barCommits(widget Widget) (f func() error, err error) {
+	var repo string
+	if _, ok := widget.Options[optionRepository]; ok {
+		repo = widget.Options[optionRepository]
+	}
+
+	title := " Gitlab Commit Per Week "
+	if _, ok := widget.Options[optionTitle]; ok {
+		title = widget.Options[optionTitle]
+	}
+
+	startDate := "7_weeks_ago"
+	if _, ok := widget.Options[optionStartDate]; ok {
+		startDate = widget.Options[optionStartDate]
+	}
+
+	endDate := "today"
+	if _, ok := widget.Options[optionEndDate]; ok {
+		endDate = widget.Options[optionEndDate]
+	}
+
+	sd, ed, err := platform.ConvertDates(time.Now(), startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	dim, counts, err := g.client.CountCommits(repo, sd, ed)
+	if err != nil {
+		return nil, err
+	}
+
+	f = func() error {
+		return g.tui.AddBarChart(counts, dim, title, widget.Options)
+	}
+
+	return
+}
@@ -44,6 +44,26 @@ func NewGscWidget(keyfile string, address string) (*gscWidget, error) {
 	}, nil
 }
 
+type gscServiceConfig struct {
+	Keyfile string `mapstructure:"keyfile"`
+	Address string `mapstructure:"address"`
+}
+
+func init() {
+	RegisterService(ServiceFactory{
+		ID:        "gsc",
+		Name:      "Google Search Console",
+		ConfigKey: "google_search_console",
+		Env:       map[string]string{"keyfile": "DEVDASH_GSC_KEYFILE"},
+		Widgets:   []string{gscTablePages, gscTableQueries, gscTable},
+		Config:    func() interface{} { return &gscServiceConfig{} },
+		New: func(config interface{}) (Service, error) {
+			c := config.(*gscServiceConfig)
+			return NewGscWidget(c.Keyfile, c.Address)
+		},
+	})
+}
+
 // CreateWidgets for the Google Search Console API.
 func (s *gscWidget) CreateWidgets(widget Widget, tui *Tui) (f func() error, err error) {
 	s.tui = tui
@@ -2,8 +2,10 @@ package internal
 
 import (
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/Phantas0s/devdash/internal/platform"
@@ -29,15 +31,39 @@ const (
 	rhBox           = "rh.box"
 	rhGauge         = "rh.gauge"
 	rhBar           = "rh.bar"
+	rhTableFleet    = "rh.table_fleet"
+
+	rhSparklineCPU    = "rh.sparkline_cpu"
+	rhSparklineMemory = "rh.sparkline_memory"
+	rhSparklineNetIO  = "rh.sparkline_net_io"
+	rhSparklineDiskIO = "rh.sparkline_disk_io"
+	rhLineCPU         = "rh.line_cpu"
+	rhLineMemory      = "rh.line_memory"
+	rhLineNetIO       = "rh.line_net_io"
+	rhLineDiskIO      = "rh.line_disk_io"
+
+	rhBarCPUCores       = "rh.bar_cpu_cores"
+	rhTableNetInterface = "rh.table_net_interfaces"
+	rhTableDiskIO       = "rh.table_disk_io"
 )
 
 type HostWidget struct {
-	tui     *Tui
+	tui *Tui
+	// service is the default host of the widgets, nil if only named hosts are configured.
 	service *platform.Host
+	// hosts configured with a name, connected the first time a widget needs them.
+	hosts  map[string]*remoteHost
+	groups map[string][]string
 }
 
-func NewHostWidget(username, addr string) (*HostWidget, error) {
-	service, err := platform.NewHost(username, addr)
+type remoteHost struct {
+	config hostConfig
+	mu     sync.Mutex
+	host   *platform.Host
+}
+
+func NewHostWidget(username, addr string, opts platform.SSHOptions) (*HostWidget, error) {
+	service, err := platform.NewHost(username, addr, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -47,6 +73,180 @@ func NewHostWidget(username, addr string) (*HostWidget, error) {
 	}, nil
 }
 
+// NewRemoteHostWidget for the default host, if its address is configured, and for the named hosts.
+func NewRemoteHostWidget(c *hostServiceConfig) (*HostWidget, error) {
+	ms := &HostWidget{}
+	if c.Address != "" {
+		var err error
+		ms, err = NewHostWidget(c.Username, c.Address, c.sshOptions())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ms.hosts = map[string]*remoteHost{}
+	for name, h := range c.Hosts {
+		if h.Address == "" {
+			return nil, errors.Errorf("you need to specify the address of the host %s", name)
+		}
+		ms.hosts[name] = &remoteHost{config: h.inherit(c.hostConfig)}
+	}
+
+	for group, hosts := range c.Groups {
+		for _, h := range hosts {
+			if _, ok := ms.hosts[h]; !ok {
+				return nil, errors.Errorf("can't find the host %s of the group %s", h, group)
+			}
+		}
+	}
+	ms.groups = c.Groups
+
+	return ms, nil
+}
+
+// Close the connections to the hosts.
+func (ms *HostWidget) Close() error {
+	if ms.service != nil {
+		ms.service.Close()
+	}
+
+	for _, rh := range ms.hosts {
+		rh.mu.Lock()
+		if rh.host != nil {
+			rh.host.Close()
+			rh.host = nil
+		}
+		rh.mu.Unlock()
+	}
+
+	return nil
+}
+
+// hostConfig is the address of a host and the options to connect to it.
+type hostConfig struct {
+	Username string `mapstructure:"username"`
+	Address  string `mapstructure:"address"`
+	Port     int    `mapstructure:"port"`
+	// KeyFiles are private keys, used with the ones of the ssh-agent.
+	KeyFiles   []string `mapstructure:"key_files"`
+	Passphrase string   `mapstructure:"passphrase"`
+	KnownHosts []string `mapstructure:"known_hosts"`
+	ProxyJump  string   `mapstructure:"proxy_jump"`
+	// SSHConfig is the ssh config file, ~/.ssh/config by default.
+	SSHConfig string `mapstructure:"ssh_config"`
+	// InsecureIgnoreHostKey is nil when not set, for a named host to override the default host either way.
+	InsecureIgnoreHostKey *bool `mapstructure:"insecure_ignore_host_key"`
+}
+
+type hostServiceConfig struct {
+	// hostConfig is the default host. Its options are used by the named hosts when they don't set them.
+	hostConfig `mapstructure:",squash"`
+	Hosts      map[string]hostConfig `mapstructure:"hosts"`
+	// Groups of named hosts, for the fleet widgets.
+	Groups map[string][]string `mapstructure:"groups"`
+}
+
+// inherit the options not set from the default host.
+func (h hostConfig) inherit(d hostConfig) hostConfig {
+	if h.Username == "" {
+		h.Username = d.Username
+	}
+	if len(h.KeyFiles) == 0 {
+		h.KeyFiles = d.KeyFiles
+	}
+	if h.Passphrase == "" {
+		h.Passphrase = d.Passphrase
+	}
+	if len(h.KnownHosts) == 0 {
+		h.KnownHosts = d.KnownHosts
+	}
+	if h.ProxyJump == "" {
+		h.ProxyJump = d.ProxyJump
+	}
+	if h.SSHConfig == "" {
+		h.SSHConfig = d.SSHConfig
+	}
+	if h.InsecureIgnoreHostKey == nil {
+		h.InsecureIgnoreHostKey = d.InsecureIgnoreHostKey
+	}
+
+	return h
+}
+
+func (h hostConfig) sshOptions() platform.SSHOptions {
+	return platform.SSHOptions{
+		Port:                  h.Port,
+		KeyFiles:              h.KeyFiles,
+		Passphrase:            h.Passphrase,
+		KnownHosts:            h.KnownHosts,
+		ProxyJump:             h.ProxyJump,
+		ConfigFile:            h.SSHConfig,
+		InsecureIgnoreHostKey: h.InsecureIgnoreHostKey != nil && *h.InsecureIgnoreHostKey,
+	}
+}
+
+var rhWidgets = []string{
+	rhUptime,
+	rhLoad,
+	rhProcesses,
+	rhBoxMemRate,
+	rhGaugeMemRate,
+	rhBoxSwapRate,
+	rhGaugeSwapRate,
+	rhBoxNetIO,
+	rhBoxDiskIO,
+	rhBoxCPURate,
+	rhGaugeCPURate,
+	rhBarMemory,
+	rhBarRates,
+	rhTableDisk,
+	rhTable,
+	rhBox,
+	rhGauge,
+	rhBar,
+	rhTableFleet,
+	rhSparklineCPU,
+	rhSparklineMemory,
+	rhSparklineNetIO,
+	rhSparklineDiskIO,
+	rhLineCPU,
+	rhLineMemory,
+	rhLineNetIO,
+	rhLineDiskIO,
+	rhBarCPUCores,
+	rhTableNetInterface,
+	rhTableDiskIO,
+}
+
+func init() {
+	RegisterService(ServiceFactory{
+		ID:        "rh",
+		Name:      "Remote Host",
+		ConfigKey: "remote_host",
+		Widgets:   rhWidgets,
+		Config:    func() interface{} { return &hostServiceConfig{} },
+		New: func(config interface{}) (Service, error) {
+			return NewRemoteHostWidget(config.(*hostServiceConfig))
+		},
+	})
+
+	lhWidgets := []string{}
+	for _, w := range rhWidgets {
+		lhWidgets = append(lhWidgets, strings.Replace(w, "rh", "lh", 1))
+	}
+
+	RegisterService(ServiceFactory{
+		ID:        "lh",
+		Name:      "Localhost",
+		ConfigKey: "local_host",
+		Always:    true,
+		Widgets:   lhWidgets,
+		New: func(config interface{}) (Service, error) {
+			return NewHostWidget("localhost", "localhost", platform.SSHOptions{})
+		},
+	})
+}
+
 func (ms *HostWidget) CreateWidgets(widget Widget, tui *Tui) (f func() error, err error) {
 	ms.tui = tui
 
@@ -90,6 +290,30 @@ func (ms *HostWidget) CreateWidgets(widget Widget, tui *Tui) (f func() error, er
 		f, err = ms.gauge(widget)
 	case rhBar:
 		f, err = ms.bar(widget)
+	case rhTableFleet:
+		f, err = ms.tableFleet(widget)
+	case rhSparklineCPU:
+		f, err = ms.sparklineCPU(widget)
+	case rhSparklineMemory:
+		f, err = ms.sparklineMemory(widget)
+	case rhSparklineNetIO:
+		f, err = ms.sparklineNetIO(widget)
+	case rhSparklineDiskIO:
+		f, err = ms.sparklineDiskIO(widget)
+	case rhLineCPU:
+		f, err = ms.lineCPU(widget)
+	case rhLineMemory:
+		f, err = ms.lineMemory(widget)
+	case rhLineNetIO:
+		f, err = ms.lineNetIO(widget)
+	case rhLineDiskIO:
+		f, err = ms.lineDiskIO(widget)
+	case rhBarCPUCores:
+		f, err = ms.barCPUCores(widget)
+	case rhTableNetInterface:
+		f, err = ms.tableNetInterfaces(widget)
+	case rhTableDiskIO:
+		f, err = ms.tableDiskIO(widget)
 	default:
 		return nil, errors.Errorf("can't find the widget %s", widget.Name)
 	}
@@ -97,12 +321,17 @@ func (ms *HostWidget) CreateWidgets(widget Widget, tui *Tui) (f func() error, er
 }
 
 func (ms *HostWidget) boxLoad(widget Widget) (f func() error, err error) {
+	host, err := ms.host(widget)
+	if err != nil {
+		return nil, err
+	}
+
 	title := " Load "
 	if _, ok := widget.Options[optionTitle]; ok {
 		title = widget.Options[optionTitle]
 	}
 
-	load, err := platform.HostLoad(ms.service.Runner)
+	load, err := platform.HostLoad(host.Runner)
 	if err != nil {
 		return nil, err
 	}
@@ -115,12 +344,17 @@ func (ms *HostWidget) boxLoad(widget Widget) (f func() error, err error) {
 }
 
 func (ms *HostWidget) boxProcesses(widget Widget) (f func() error, err error) {
+	host, err := ms.host(widget)
+	if err != nil {
+		return nil, err
+	}
+
 	title := " Running processes "
 	if _, ok := widget.Options[optionTitle]; ok {
 		title = widget.Options[optionTitle]
 	}
 
-	procs, err := platform.HostProcesses(ms.service.Runner)
+	procs, err := platform.HostProcesses(host.Runner)
 	if err != nil {
 		return nil, err
 	}
@@ -133,12 +367,17 @@ func (ms *HostWidget) boxProcesses(widget Widget) (f func() error, err error) {
 }
 
 func (ms *HostWidget) boxUptime(widget Widget) (f func() error, err error) {
+	host, err := ms.host(widget)
+	if err != nil {
+		return nil, err
+	}
+
 	title := " Uptime "
 	if _, ok := widget.Options[optionTitle]; ok {
 		title = widget.Options[optionTitle]
 	}
 
-	uptime, err := platform.HostUptime(ms.service.Runner)
+	uptime, err := platform.HostUptime(host.Runner)
 	if err != nil {
 		return nil, err
 	}
@@ -177,12 +416,17 @@ func formatSeconds(dur time.Duration) string {
 }
 
 func (ms *HostWidget) boxCPURate(widget Widget) (f func() error, err error) {
+	host, err := ms.host(widget)
+	if err != nil {
+		return nil, err
+	}
+
 	title := " CPU usage "
 	if _, ok := widget.Options[optionTitle]; ok {
 		title = widget.Options[optionTitle]
 	}
 
-	CPURate, err := platform.HostCPURate(ms.service.Runner)
+	CPURate, err := platform.HostCPURate(host.Runner)
 	if err != nil {
 		return nil, err
 	}
@@ -195,12 +439,17 @@ func (ms *HostWidget) boxCPURate(widget Widget) (f func() error, err error) {
 }
 
 func (ms *HostWidget) gaugeCPURate(widget Widget) (f func() error, err error) {
+	host, err := ms.host(widget)
+	if err != nil {
+		return nil, err
+	}
+
 	title := " CPU usage "
 	if _, ok := widget.Options[optionTitle]; ok {
 		title = widget.Options[optionTitle]
 	}
 
-	CPURate, err := platform.HostCPURate(ms.service.Runner)
+	CPURate, err := platform.HostCPURate(host.Runner)
 	if err != nil {
 		return nil, err
 	}
@@ -213,12 +462,17 @@ func (ms *HostWidget) gaugeCPURate(widget Widget) (f func() error, err error) {
 }
 
 func (ms *HostWidget) boxMemRate(widget Widget) (f func() error, err error) {
+	host, err := ms.host(widget)
+	if err != nil {
+		return nil, err
+	}
+
 	title := " Memory usage "
 	if _, ok := widget.Options[optionTitle]; ok {
 		title = widget.Options[optionTitle]
 	}
 
-	memRate, err := platform.HostMemoryRate(ms.service.Runner)
+	memRate, err := platform.HostMemoryRate(host.Runner)
 	if err != nil {
 		return nil, err
 	}
@@ -231,12 +485,17 @@ func (ms *HostWidget) boxMemRate(widget Widget) (f func() error, err error) {
 }
 
 func (ms *HostWidget) gaugeMemRate(widget Widget) (f func() error, err error) {
+	host, err := ms.host(widget)
+	if err != nil {
+		return nil, err
+	}
+
 	title := " Memory usage "
 	if _, ok := widget.Options[optionTitle]; ok {
 		title = widget.Options[optionTitle]
 	}
 
-	memRate, err := platform.HostMemoryRate(ms.service.Runner)
+	memRate, err := platform.HostMemoryRate(host.Runner)
 	if err != nil {
 		return nil, err
 	}
@@ -249,12 +508,17 @@ func (ms *HostWidget) gaugeMemRate(widget Widget) (f func() error, err error) {
 }
 
 func (ms *HostWidget) boxSwapRate(widget Widget) (f func() error, err error) {
+	host, err := ms.host(widget)
+	if err != nil {
+		return nil, err
+	}
+
 	title := " Swap usage "
 	if _, ok := widget.Options[optionTitle]; ok {
 		title = widget.Options[optionTitle]
 	}
 
-	swapRate, err := platform.HostSwapRate(ms.service.Runner)
+	swapRate, err := platform.HostSwapRate(host.Runner)
 	if err != nil {
 		return nil, err
 	}
@@ -267,12 +531,17 @@ func (ms *HostWidget) boxSwapRate(widget Widget) (f func() error, err error) {
 }
 
 func (ms *HostWidget) gaugeSwapRate(widget Widget) (f func() error, err error) {
+	host, err := ms.host(widget)
+	if err != nil {
+		return nil, err
+	}
+
 	title := " Swap usage "
 	if _, ok := widget.Options[optionTitle]; ok {
 		title = widget.Options[optionTitle]
 	}
 
-	swapRate, err := platform.HostSwapRate(ms.service.Runner)
+	swapRate, err := platform.HostSwapRate(host.Runner)
 	if err != nil {
 		return nil, err
 	}
@@ -285,22 +554,27 @@ func (ms *HostWidget) gaugeSwapRate(widget Widget) (f func() error, err error) {
 }
 
 func (ms *HostWidget) barRates(widget Widget) (f func() error, err error) {
+	host, err := ms.host(widget)
+	if err != nil {
+		return nil, err
+	}
+
 	title := " Resources usage (%) "
 	if _, ok := widget.Options[optionTitle]; ok {
 		title = widget.Options[optionTitle]
 	}
 
-	swapRate, err := platform.HostSwapRate(ms.service.Runner)
+	swapRate, err := platform.HostSwapRate(host.Runner)
 	if err != nil {
 		return nil, err
 	}
 
-	cpuRate, err := platform.HostCPURate(ms.service.Runner)
+	cpuRate, err := platform.HostCPURate(host.Runner)
 	if err != nil {
 		return nil, err
 	}
 
-	memoryRate, err := platform.HostMemoryRate(ms.service.Runner)
+	memoryRate, err := platform.HostMemoryRate(host.Runner)
 	if err != nil {
 		return nil, err
 	}
@@ -318,6 +592,11 @@ func (ms *HostWidget) barRates(widget Widget) (f func() error, err error) {
 }
 
 func (ms *HostWidget) boxNetIO(widget Widget) (f func() error, err error) {
+	host, err := ms.host(widget)
+	if err != nil {
+		return nil, err
+	}
+
 	unit := "kb"
 	if _, ok := widget.Options[optionUnit]; ok {
 		unit = widget.Options[optionUnit]
@@ -328,7 +607,7 @@ func (ms *HostWidget) boxNetIO(widget Widget) (f func() error, err error) {
 		title = widget.Options[optionTitle]
 	}
 
-	netIO, err := platform.HostNetIO(ms.service.Runner, unit)
+	netIO, err := platform.HostNetIO(host.Runner, unit)
 	if err != nil {
 		return nil, err
 	}
@@ -341,6 +620,11 @@ func (ms *HostWidget) boxNetIO(widget Widget) (f func() error, err error) {
 }
 
 func (ms *HostWidget) boxDiskIO(widget Widget) (f func() error, err error) {
+	host, err := ms.host(widget)
+	if err != nil {
+		return nil, err
+	}
+
 	unit := "kb"
 	if _, ok := widget.Options[optionUnit]; ok {
 		unit = widget.Options[optionUnit]
@@ -351,7 +635,7 @@ func (ms *HostWidget) boxDiskIO(widget Widget) (f func() error, err error) {
 		title = widget.Options[optionTitle]
 	}
 
-	diskIO, err := platform.HostDiskIO(ms.service.Runner, unit)
+	diskIO, err := platform.HostDiskIO(host.Runner, unit)
 	if err != nil {
 		return nil, err
 	}
@@ -364,6 +648,11 @@ func (ms *HostWidget) boxDiskIO(widget Widget) (f func() error, err error) {
 }
 
 func (ms *HostWidget) barMemory(widget Widget) (f func() error, err error) {
+	host, err := ms.host(widget)
+	if err != nil {
+		return nil, err
+	}
+
 	metrics := []string{"MemTotal", "MemFree", "MemAvailable"}
 	if _, ok := widget.Options[optionMetrics]; ok {
 		if len(widget.Options[optionMetrics]) > 0 {
@@ -388,7 +677,7 @@ func (ms *HostWidget) barMemory(widget Widget) (f func() error, err error) {
 		title = widget.Options[optionTitle]
 	}
 
-	mem, err := platform.HostMemory(ms.service.Runner, metrics, unit)
+	mem, err := platform.HostMemory(host.Runner, metrics, unit)
 	if err != nil {
 		return nil, err
 	}
@@ -401,6 +690,11 @@ func (ms *HostWidget) barMemory(widget Widget) (f func() error, err error) {
 }
 
 func (ms *HostWidget) tableDisk(widget Widget) (f func() error, err error) {
+	host, err := ms.host(widget)
+	if err != nil {
+		return nil, err
+	}
+
 	unit := "gb"
 	if _, ok := widget.Options[optionUnit]; ok {
 		unit = widget.Options[optionUnit]
@@ -418,7 +712,7 @@ func (ms *HostWidget) tableDisk(widget Widget) (f func() error, err error) {
 		}
 	}
 
-	data, err := platform.HostDisk(ms.service.Runner, headers, unit)
+	data, err := platform.HostDisk(host.Runner, headers, unit)
 	if err != nil {
 		return nil, err
 	}
@@ -431,6 +725,11 @@ func (ms *HostWidget) tableDisk(widget Widget) (f func() error, err error) {
 }
 
 func (ms *HostWidget) table(widget Widget) (f func() error, err error) {
+	host, err := ms.host(widget)
+	if err != nil {
+		return nil, err
+	}
+
 	title := fmt.Sprintf(" Table ")
 	if _, ok := widget.Options[optionTitle]; ok {
 		title = widget.Options[optionTitle]
@@ -450,7 +749,7 @@ func (ms *HostWidget) table(widget Widget) (f func() error, err error) {
 		}
 	}
 
-	data, err := platform.HostTable(ms.service.Runner, cmd, headers)
+	data, err := platform.HostTable(host.Runner, cmd, headers)
 	if err != nil {
 		return nil, err
 	}
@@ -463,6 +762,11 @@ func (ms *HostWidget) table(widget Widget) (f func() error, err error) {
 }
 
 func (ms *HostWidget) box(widget Widget) (f func() error, err error) {
+	host, err := ms.host(widget)
+	if err != nil {
+		return nil, err
+	}
+
 	title := fmt.Sprintf(" Box ")
 	if _, ok := widget.Options[optionTitle]; ok {
 		title = widget.Options[optionTitle]
@@ -473,7 +777,7 @@ func (ms *HostWidget) box(widget Widget) (f func() error, err error) {
 		cmd = widget.Options[optionCommand]
 	}
 
-	data, err := platform.HostBox(ms.service.Runner, cmd)
+	data, err := platform.HostBox(host.Runner, cmd)
 	if err != nil {
 		return nil, err
 	}
@@ -486,6 +790,11 @@ func (ms *HostWidget) box(widget Widget) (f func() error, err error) {
 }
 
 func (ms *HostWidget) gauge(widget Widget) (f func() error, err error) {
+	host, err := ms.host(widget)
+	if err != nil {
+		return nil, err
+	}
+
 	title := fmt.Sprintf(" Gauge ")
 	if _, ok := widget.Options[optionTitle]; ok {
 		title = widget.Options[optionTitle]
@@ -497,7 +806,7 @@ func (ms *HostWidget) gauge(widget Widget) (f func() error, err error) {
 		cmd = widget.Options[optionCommand]
 	}
 
-	data, err := platform.HostGauge(ms.service.Runner, cmd)
+	data, err := platform.HostGauge(host.Runner, cmd)
 	if err != nil {
 		return nil, err
 	}
@@ -510,6 +819,11 @@ func (ms *HostWidget) gauge(widget Widget) (f func() error, err error) {
 }
 
 func (ms *HostWidget) bar(widget Widget) (f func() error, err error) {
+	host, err := ms.host(widget)
+	if err != nil {
+		return nil, err
+	}
+
 	title := " Example of bar "
 	if _, ok := widget.Options[optionTitle]; ok {
 		title = widget.Options[optionTitle]
@@ -527,7 +841,7 @@ func (ms *HostWidget) bar(widget Widget) (f func() error, err error) {
 		cmd = widget.Options[optionCommand]
 	}
 
-	data, err := platform.HostBar(ms.service.Runner, cmd)
+	data, err := platform.HostBar(host.Runner, cmd)
 	if err != nil {
 		return nil, err
 	}
@@ -543,3 +857,490 @@ func (ms *HostWidget) bar(widget Widget) (f func() error, err error) {
 
 	return
 }
+
+// host of the widget: the host named in the option "host", or the default one.
+func (ms *HostWidget) host(widget Widget) (*platform.Host, error) {
+	name, ok := widget.Options[optionHost]
+	if !ok {
+		if ms.service == nil {
+			return nil, errors.New("you need to specify the address of the default host, or a host option in the widget")
+		}
+		return ms.service, nil
+	}
+
+	return ms.namedHost(name)
+}
+
+// namedHost created the first time it's needed. If it can't be created, it's created again at the next call.
+// The connection is only opened to run the commands, with a backoff when it fails.
+func (ms *HostWidget) namedHost(name string) (*platform.Host, error) {
+	rh, ok := ms.hosts[name]
+	if !ok {
+		return nil, errors.Errorf("can't find the host %s", name)
+	}
+
+	rh.mu.Lock()
+	defer rh.mu.Unlock()
+
+	if rh.host != nil {
+		return rh.host, nil
+	}
+
+	c := rh.config
+	h, err := platform.NewHost(c.Username, c.Address, c.sshOptions())
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't connect to the host %s", name)
+	}
+	rh.host = h
+
+	return h, nil
+}
+
+func (ms *HostWidget) tableFleet(widget Widget) (f func() error, err error) {
+	title := " Fleet "
+	if _, ok := widget.Options[optionTitle]; ok {
+		title = widget.Options[optionTitle]
+	}
+
+	// Every named host by default.
+	hosts := []string{}
+	for k := range ms.hosts {
+		hosts = append(hosts, k)
+	}
+	sort.Strings(hosts)
+
+	if _, ok := widget.Options[optionGroup]; ok {
+		group := widget.Options[optionGroup]
+		if _, ok := ms.groups[group]; !ok {
+			return nil, errors.Errorf("can't find the group of hosts %s", group)
+		}
+		hosts = ms.groups[group]
+	}
+
+	if len(hosts) == 0 {
+		return nil, errors.New("you need to specify hosts in the remote_host service")
+	}
+
+	data := platform.HostFleet(hosts, func(name string) (func(cmd string) (string, error), error) {
+		h, err := ms.namedHost(name)
+		if err != nil {
+			return nil, err
+		}
+		return h.Runner, nil
+	})
+
+	f = func() error {
+		return ms.tui.AddTable(data, title, widget.Options)
+	}
+
+	return
+}
+
+// hostHistoryLayout of the times of the samples, displayed under the line charts.
+const hostHistoryLayout = "15:04:05"
+
+func (ms *HostWidget) sparklineCPU(widget Widget) (f func() error, err error) {
+	host, err := ms.host(widget)
+	if err != nil {
+		return nil, err
+	}
+
+	title := " CPU usage "
+	if _, ok := widget.Options[optionTitle]; ok {
+		title = widget.Options[optionTitle]
+	}
+
+	cpu, err := platform.HostCPUHistory(host.Runner, host.History, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	labels := []string{fmt.Sprintf("CPU %.2f %%", lastValue(cpu))}
+	colors := historyColors(widget.Options, []uint16{green})
+
+	f = func() error {
+		return ms.tui.AddSparklines([][]int{toInts(cpu)}, labels, title, colors, widget.Options)
+	}
+
+	return
+}
+
+func (ms *HostWidget) sparklineMemory(widget Widget) (f func() error, err error) {
+	host, err := ms.host(widget)
+	if err != nil {
+		return nil, err
+	}
+
+	title := " Memory usage "
+	if _, ok := widget.Options[optionTitle]; ok {
+		title = widget.Options[optionTitle]
+	}
+
+	mem, err := platform.HostMemoryHistory(host.Runner, host.History, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	labels := []string{fmt.Sprintf("Memory %.2f %%", lastValue(mem))}
+	colors := historyColors(widget.Options, []uint16{yellow})
+
+	f = func() error {
+		return ms.tui.AddSparklines([][]int{toInts(mem)}, labels, title, colors, widget.Options)
+	}
+
+	return
+}
+
+func (ms *HostWidget) sparklineNetIO(widget Widget) (f func() error, err error) {
+	host, err := ms.host(widget)
+	if err != nil {
+		return nil, err
+	}
+
+	unit := "kb"
+	if _, ok := widget.Options[optionUnit]; ok {
+		unit = widget.Options[optionUnit]
+	}
+
+	title := " Net I/O "
+	if _, ok := widget.Options[optionTitle]; ok {
+		title = widget.Options[optionTitle]
+	}
+
+	// The sparklines are drawn in bytes, to keep the small variations.
+	rx, tx, err := platform.HostNetIOHistory(host.Runner, host.History, "b", time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	labels := []string{
+		fmt.Sprintf("RX %.2f %s/s", lastValue(host.History.Values(platform.HostMetricNetRx, unit)), unit),
+		fmt.Sprintf("TX %.2f %s/s", lastValue(host.History.Values(platform.HostMetricNetTx, unit)), unit),
+	}
+	colors := historyColors(widget.Options, []uint16{green, blue})
+
+	f = func() error {
+		return ms.tui.AddSparklines([][]int{toInts(rx), toInts(tx)}, labels, title, colors, widget.Options)
+	}
+
+	return
+}
+
+func (ms *HostWidget) sparklineDiskIO(widget Widget) (f func() error, err error) {
+	host, err := ms.host(widget)
+	if err != nil {
+		return nil, err
+	}
+
+	unit := "kb"
+	if _, ok := widget.Options[optionUnit]; ok {
+		unit = widget.Options[optionUnit]
+	}
+
+	title := " Disk I/O "
+	if _, ok := widget.Options[optionTitle]; ok {
+		title = widget.Options[optionTitle]
+	}
+
+	// The sparklines are drawn in bytes, to keep the small variations.
+	read, write, err := platform.HostDiskIOHistory(host.Runner, host.History, "b", time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	labels := []string{
+		fmt.Sprintf("Read %.2f %s/s", lastValue(host.History.Values(platform.HostMetricDiskRead, unit)), unit),
+		fmt.Sprintf("Write %.2f %s/s", lastValue(host.History.Values(platform.HostMetricDiskWrite, unit)), unit),
+	}
+	colors := historyColors(widget.Options, []uint16{green, blue})
+
+	f = func() error {
+		return ms.tui.AddSparklines([][]int{toInts(read), toInts(write)}, labels, title, colors, widget.Options)
+	}
+
+	return
+}
+
+func (ms *HostWidget) lineCPU(widget Widget) (f func() error, err error) {
+	host, err := ms.host(widget)
+	if err != nil {
+		return nil, err
+	}
+
+	title := " CPU usage (%) "
+	if _, ok := widget.Options[optionTitle]; ok {
+		title = widget.Options[optionTitle]
+	}
+
+	cpu, err := platform.HostCPUHistory(host.Runner, host.History, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	times := host.History.Times(platform.HostMetricCPU, hostHistoryLayout)
+
+	f = func() error {
+		return ms.tui.AddLineChart(cpu, times, title, widget.Options)
+	}
+
+	return
+}
+
+func (ms *HostWidget) lineMemory(widget Widget) (f func() error, err error) {
+	host, err := ms.host(widget)
+	if err != nil {
+		return nil, err
+	}
+
+	title := " Memory usage (%) "
+	if _, ok := widget.Options[optionTitle]; ok {
+		title = widget.Options[optionTitle]
+	}
+
+	mem, err := platform.HostMemoryHistory(host.Runner, host.History, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	times := host.History.Times(platform.HostMetricMemory, hostHistoryLayout)
+
+	f = func() error {
+		return ms.tui.AddLineChart(mem, times, title, widget.Options)
+	}
+
+	return
+}
+
+func (ms *HostWidget) lineNetIO(widget Widget) (f func() error, err error) {
+	host, err := ms.host(widget)
+	if err != nil {
+		return nil, err
+	}
+
+	unit := "kb"
+	if _, ok := widget.Options[optionUnit]; ok {
+		unit = widget.Options[optionUnit]
+	}
+
+	metric := "total"
+	if _, ok := widget.Options[optionMetric]; ok {
+		metric = widget.Options[optionMetric]
+	}
+
+	title := fmt.Sprintf(" Net I/O %s (%s/s) ", metric, strings.ToUpper(unit))
+	if _, ok := widget.Options[optionTitle]; ok {
+		title = widget.Options[optionTitle]
+	}
+
+	rx, tx, err := platform.HostNetIOHistory(host.Runner, host.History, unit, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := selectHistory(metric, "rx", rx, "tx", tx)
+	if err != nil {
+		return nil, err
+	}
+	times := host.History.Times(platform.HostMetricNetRx, hostHistoryLayout)
+
+	f = func() error {
+		return ms.tui.AddLineChart(data, times, title, widget.Options)
+	}
+
+	return
+}
+
+func (ms *HostWidget) lineDiskIO(widget Widget) (f func() error, err error) {
+	host, err := ms.host(widget)
+	if err != nil {
+		return nil, err
+	}
+
+	unit := "kb"
+	if _, ok := widget.Options[optionUnit]; ok {
+		unit = widget.Options[optionUnit]
+	}
+
+	metric := "total"
+	if _, ok := widget.Options[optionMetric]; ok {
+		metric = widget.Options[optionMetric]
+	}
+
+	title := fmt.Sprintf(" Disk I/O %s (%s/s) ", metric, strings.ToUpper(unit))
+	if _, ok := widget.Options[optionTitle]; ok {
+		title = widget.Options[optionTitle]
+	}
+
+	read, write, err := platform.HostDiskIOHistory(host.Runner, host.History, unit, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := selectHistory(metric, "read", read, "write", write)
+	if err != nil {
+		return nil, err
+	}
+	times := host.History.Times(platform.HostMetricDiskRead, hostHistoryLayout)
+
+	f = func() error {
+		return ms.tui.AddLineChart(data, times, title, widget.Options)
+	}
+
+	return
+}
+
+func (ms *HostWidget) barCPUCores(widget Widget) (f func() error, err error) {
+	host, err := ms.host(widget)
+	if err != nil {
+		return nil, err
+	}
+
+	title := " CPU cores usage (%) "
+	if _, ok := widget.Options[optionTitle]; ok {
+		title = widget.Options[optionTitle]
+	}
+
+	include, exclude := deviceFilters(widget.Options, nil)
+	usage, cores, err := platform.HostCPUCores(host.Runner, host.History, include, exclude, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	f = func() error {
+		return ms.tui.AddBarChart(usage, cores, title, widget.Options)
+	}
+
+	return
+}
+
+func (ms *HostWidget) tableNetInterfaces(widget Widget) (f func() error, err error) {
+	host, err := ms.host(widget)
+	if err != nil {
+		return nil, err
+	}
+
+	unit := "kb"
+	if _, ok := widget.Options[optionUnit]; ok {
+		unit = widget.Options[optionUnit]
+	}
+
+	title := fmt.Sprintf(" Network interfaces (%s/s) ", strings.ToUpper(unit))
+	if _, ok := widget.Options[optionTitle]; ok {
+		title = widget.Options[optionTitle]
+	}
+
+	include, exclude := deviceFilters(widget.Options, []string{"lo"})
+	data, err := platform.HostNetInterfaces(host.Runner, host.History, unit, include, exclude, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	f = func() error {
+		return ms.tui.AddTable(data, title, widget.Options)
+	}
+
+	return
+}
+
+func (ms *HostWidget) tableDiskIO(widget Widget) (f func() error, err error) {
+	host, err := ms.host(widget)
+	if err != nil {
+		return nil, err
+	}
+
+	unit := "kb"
+	if _, ok := widget.Options[optionUnit]; ok {
+		unit = widget.Options[optionUnit]
+	}
+
+	title := fmt.Sprintf(" Disk I/O (%s/s) ", strings.ToUpper(unit))
+	if _, ok := widget.Options[optionTitle]; ok {
+		title = widget.Options[optionTitle]
+	}
+
+	include, exclude := deviceFilters(widget.Options, []string{"loop*", "ram*"})
+	data, err := platform.HostDiskDevices(host.Runner, host.History, unit, include, exclude, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	f = func() error {
+		return ms.tui.AddTable(data, title, widget.Options)
+	}
+
+	return
+}
+
+// deviceFilters returns the patterns of the options include and exclude.
+// The default exclude patterns are replaced by the option, even if empty.
+func deviceFilters(options map[string]string, exclude []string) ([]string, []string) {
+	include := []string{}
+	if _, ok := options[optionInclude]; ok {
+		include = splitPatterns(options[optionInclude])
+	}
+
+	if _, ok := options[optionExclude]; ok {
+		exclude = splitPatterns(options[optionExclude])
+	}
+
+	return include, exclude
+}
+
+func splitPatterns(s string) []string {
+	patterns := []string{}
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			patterns = append(patterns, v)
+		}
+	}
+
+	return patterns
+}
+
+// selectHistory returns the first or the second series depending on the metric given, or their sum for "total".
+func selectHistory(metric, firstName string, first []float64, secondName string, second []float64) ([]float64, error) {
+	switch metric {
+	case firstName:
+		return first, nil
+	case secondName:
+		return second, nil
+	case "total":
+		total := make([]float64, len(first))
+		for k := range first {
+			total[k] = first[k]
+			if k < len(second) {
+				total[k] += second[k]
+			}
+		}
+		return total, nil
+	default:
+		return nil, errors.Errorf("unknown metric %s (possible metrics: %s, %s or total)", metric, firstName, secondName)
+	}
+}
+
+// historyColors of the sparklines, from the options first_color and second_color, or the defaults given.
+func historyColors(options map[string]string, defaults []uint16) []uint16 {
+	colors := append([]uint16{}, defaults...)
+	for k, o := range []string{optionFirstColor, optionSecondColor} {
+		if _, ok := options[o]; ok && k < len(colors) {
+			colors[k] = colorLookUp[options[o]]
+		}
+	}
+
+	return colors
+}
+
+func toInts(values []float64) []int {
+	ints := make([]int, 0, len(values))
+	for _, v := range values {
+		ints = append(ints, int(v))
+	}
+
+	return ints
+}
+
+func lastValue(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	return values[len(values)-1]
+}
@@ -1,6 +1,7 @@
 package internal
 
 import (
+	"reflect"
 	"testing"
 	"time"
 )
@@ -28,3 +29,153 @@ func Test_formatSeconds(t *testing.T) {
 		})
 	}
 }
+
+func Test_NewRemoteHostWidget(t *testing.T) {
+	testCases := []struct {
+		name     string
+		options  map[string]interface{}
+		expected map[string]hostConfig
+		wantErr  bool
+	}{
+		{
+			name: "named hosts inherit the default options",
+			options: map[string]interface{}{
+				"username":  "matthieu",
+				"key_files": "~/.ssh/id_devdash",
+				"hosts": map[string]interface{}{
+					"web1": map[string]interface{}{"address": "web1.example.com"},
+					"db":   map[string]interface{}{"address": "db.example.com", "username": "postgres", "port": 2222},
+				},
+				"groups": map[string]interface{}{
+					"web": []interface{}{"web1"},
+				},
+			},
+			expected: map[string]hostConfig{
+				"web1": {Username: "matthieu", Address: "web1.example.com", KeyFiles: []string{"~/.ssh/id_devdash"}},
+				"db":   {Username: "postgres", Address: "db.example.com", Port: 2222, KeyFiles: []string{"~/.ssh/id_devdash"}},
+			},
+		},
+		{
+			name: "named host checking the host key",
+			options: map[string]interface{}{
+				"insecure_ignore_host_key": true,
+				"hosts": map[string]interface{}{
+					"web1": map[string]interface{}{"address": "web1.example.com", "insecure_ignore_host_key": false},
+					"db":   map[string]interface{}{"address": "db.example.com"},
+				},
+			},
+			expected: map[string]hostConfig{
+				"web1": {Address: "web1.example.com", InsecureIgnoreHostKey: boolPtr(false)},
+				"db":   {Address: "db.example.com", InsecureIgnoreHostKey: boolPtr(true)},
+			},
+		},
+		{
+			name: "host without address",
+			options: map[string]interface{}{
+				"hosts": map[string]interface{}{
+					"web1": map[string]interface{}{"username": "matthieu"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown host in a group",
+			options: map[string]interface{}{
+				"hosts": map[string]interface{}{
+					"web1": map[string]interface{}{"address": "web1.example.com"},
+				},
+				"groups": map[string]interface{}{
+					"web": []interface{}{"web1", "web2"},
+				},
+			},
+			wantErr: true,
+		},
+	}
+
+	f, err := findService("rh")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := f.Create(tc.options)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Error '%v' even if wantErr is %t", err, tc.wantErr)
+			}
+
+			if tc.wantErr {
+				return
+			}
+
+			actual := map[string]hostConfig{}
+			for k, v := range s.(*HostWidget).hosts {
+				actual[k] = v.config
+			}
+
+			if !reflect.DeepEqual(tc.expected, actual) {
+				t.Errorf("Expected %v, actual %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+func Test_HostWidgetUnknownHost(t *testing.T) {
+	ms, err := NewRemoteHostWidget(&hostServiceConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ms.host(Widget{Options: map[string]string{}}); err == nil {
+		t.Error("Expected an error without default host")
+	}
+
+	if _, err := ms.host(Widget{Options: map[string]string{optionHost: "web1"}}); err == nil {
+		t.Error("Expected an error for an unknown host")
+	}
+}
+
+func Test_deviceFilters(t *testing.T) {
+	testCases := []struct {
+		name            string
+		options         map[string]string
+		expectedInclude []string
+		expectedExclude []string
+	}{
+		{
+			name:            "default exclude",
+			options:         map[string]string{},
+			expectedInclude: []string{},
+			expectedExclude: []string{"lo"},
+		},
+		{
+			name:            "include and exclude",
+			options:         map[string]string{optionInclude: "eth*, wl*", optionExclude: "eth1"},
+			expectedInclude: []string{"eth*", "wl*"},
+			expectedExclude: []string{"eth1"},
+		},
+		{
+			name:            "empty exclude replaces the default",
+			options:         map[string]string{optionExclude: ""},
+			expectedInclude: []string{},
+			expectedExclude: []string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			include, exclude := deviceFilters(tc.options, []string{"lo"})
+
+			if !reflect.DeepEqual(include, tc.expectedInclude) {
+				t.Errorf("Expected %v, actual %v", tc.expectedInclude, include)
+			}
+			if !reflect.DeepEqual(exclude, tc.expectedExclude) {
+				t.Errorf("Expected %v, actual %v", tc.expectedExclude, exclude)
+			}
+		})
+	}
+}
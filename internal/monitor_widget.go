@@ -5,7 +5,7 @@ import (
 	"net/http"
 	"net/url"
 
-	goping "github.com/go-ping/ping"
+	"github.com/Phantas0s/devdash/internal/platform"
 	"github.com/pkg/errors"
 )
 
@@ -26,6 +26,23 @@ func NewMonitorWidget(address string) (*monitorWidget, error) {
 	}, nil
 }
 
+type monitorServiceConfig struct {
+	Address string `mapstructure:"address"`
+}
+
+func init() {
+	RegisterService(ServiceFactory{
+		ID:        "mon",
+		Name:      "Monitor",
+		ConfigKey: "monitor",
+		Widgets:   []string{boxPing, boxAvailability},
+		Config:    func() interface{} { return &monitorServiceConfig{} },
+		New: func(config interface{}) (Service, error) {
+			return NewMonitorWidget(config.(*monitorServiceConfig).Address)
+		},
+	})
+}
+
 // CreateWidgets for the monitor service.
 func (m *monitorWidget) CreateWidgets(widget Widget, tui *Tui) (f func() error, err error) {
 	m.tui = tui
@@ -54,13 +71,10 @@ func (m *monitorWidget) pingWidget(widget Widget) (f func() error, err error) {
 		return nil, err
 	}
 
-	pinger, err := goping.NewPinger(URL.Host)
+	stats, err := platform.Ping(URL.Host)
 	if err != nil {
 		return nil, err
 	}
-	pinger.Count = 1
-	pinger.Run()                 // blocks until finished
-	stats := pinger.Statistics() // get send/receive/rtt stats
 
 	title := " Availability "
 	if _, ok := widget.Options[optionTitle]; ok {
@@ -69,7 +83,7 @@ func (m *monitorWidget) pingWidget(widget Widget) (f func() error, err error) {
 
 	f = func() error {
 		return m.tui.AddTextBox(
-			fmt.Sprintf("Sent: %d / Received: %d / Time: %d", stats.PacketsSent, stats.PacketsRecv, stats.AvgRtt),
+			stats,
 			title,
 			widget.Options,
 		)
@@ -89,7 +103,7 @@ func (m *monitorWidget) availabilityWidget(widget Widget) (f func() error, err e
 		return nil, err
 	}
 
-	client := &http.Client{}
+	client := platform.RecordedHTTPClient()
 	res, err := client.Do(req)
 
 	status := "online"
@@ -0,0 +1,309 @@
+package platform
+
+// cache keeps the HTTP responses of the platform clients.
+// Widgets requesting the same data (same request with the same credentials) share the same response till the TTL expires.
+// Identical requests running at the same time are only sent once.
+// The responses can be persisted on disk, to avoid fetching everything again when DevDash restarts.
+// The last responses of the rate limited APIs are kept even after the TTL (or without TTL), to be served when the rate limit is exceeded.
+// The expired responses are dropped when a new one is kept, and the number of responses kept in memory is capped.
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// cacheMaxEntries is the number of responses kept in memory, and the number of stale responses.
+const cacheMaxEntries = 500
+
+type noCacheKey struct{}
+
+// withoutCache returns a context for the requests which always need a fresh response: they are never cached.
+func withoutCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noCacheKey{}, true)
+}
+
+// Cache of HTTP responses.
+type Cache struct {
+	ttl time.Duration
+	// max number of entries, and of stale entries.
+	max int
+	// directory where the responses are persisted. Not persisted if empty.
+	dir string
+
+	mu      sync.Mutex
+	entries map[string]*cacheEntry
+	// stale responses of rate limited APIs, whatever their expiry.
+	stale map[string]*cacheEntry
+	group singleflight.Group
+}
+
+type cacheEntry struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header"`
+	Body       []byte      `json:"body"`
+	Expire     time.Time   `json:"expire"`
+}
+
+// The cache used by every platform client.
+var httpCache = NewCache(0, "")
+
+// NewCache with a TTL for every response. If dir is not empty, the responses are persisted in this directory.
+func NewCache(ttl time.Duration, dir string) *Cache {
+	return &Cache{
+		ttl:     ttl,
+		max:     cacheMaxEntries,
+		dir:     dir,
+		entries: map[string]*cacheEntry{},
+		stale:   map[string]*cacheEntry{},
+	}
+}
+
+// UseCache for every client created afterwards.
+func UseCache(c *Cache) {
+	httpCache = c
+}
+
+// cachedHTTPClient wraps the transport of the client with the cache.
+// The namespace separates the responses of clients using different credentials.
+// The responses are recorded or replayed above the cache, if a recorder is used:
+// the responses served by the cache (even from the disk) are recorded too.
+func cachedHTTPClient(namespace string, client *http.Client) *http.Client {
+	return recordedHTTPClient(httpCache.Client(namespace, client))
+}
+
+// Client wraps the transport of the client with the cache.
+func (c *Cache) Client(namespace string, client *http.Client) *http.Client {
+	base := client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	cc := *client
+	cc.Transport = &cacheTransport{
+		cache:     c,
+		namespace: namespace,
+		base:      base,
+	}
+
+	return &cc
+}
+
+func (c *Cache) get(key string) (*cacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	e, ok := c.entries[key]
+	if !ok && c.dir != "" {
+		e, ok = c.read(key)
+		if ok && now.Before(e.Expire) {
+			c.evict(c.entries, now)
+			c.entries[key] = e
+		}
+	}
+
+	if !ok || now.After(e.Expire) {
+		return nil, false
+	}
+
+	return e, true
+}
+
+// set the entry. Without TTL, only the stale responses of the rate limited APIs are kept.
+func (c *Cache) set(key string, e *cacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	e.Expire = now.Add(c.ttl)
+
+	if e.Header.Get(headerRateRemaining) != "" {
+		delete(c.stale, key)
+		c.evict(c.stale, time.Time{})
+		c.stale[key] = e
+	}
+
+	if c.ttl <= 0 {
+		return
+	}
+
+	delete(c.entries, key)
+	c.evict(c.entries, now)
+	c.entries[key] = e
+	if c.dir != "" {
+		c.write(key, e)
+	}
+}
+
+// evict the entries expired at the time given, and the entries expiring first to make room for a new one.
+func (c *Cache) evict(entries map[string]*cacheEntry, now time.Time) {
+	for k, v := range entries {
+		if now.After(v.Expire) {
+			delete(entries, k)
+		}
+	}
+
+	for len(entries) >= c.max {
+		first := ""
+		for k, v := range entries {
+			if first == "" || v.Expire.Before(entries[first].Expire) {
+				first = k
+			}
+		}
+		delete(entries, first)
+	}
+}
+
+// getStale returns the last response known, even if expired.
+func (c *Cache) getStale(key string) (*cacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.stale[key]; ok {
+		return e, true
+	}
+
+	e, ok := c.entries[key]
+	if !ok && c.dir != "" {
+		e, ok = c.read(key)
+	}
+
+	return e, ok
+}
+
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.dir, key+".json")
+}
+
+func (c *Cache) read(key string) (*cacheEntry, bool) {
+	data, err := ioutil.ReadFile(c.path(key))
+	if err != nil {
+		return nil, false
+	}
+
+	e := &cacheEntry{}
+	if err := json.Unmarshal(data, e); err != nil {
+		return nil, false
+	}
+
+	return e, true
+}
+
+// write the entry on disk. The cache is only an optimization: errors are ignored.
+func (c *Cache) write(key string, e *cacheEntry) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+
+	if err := os.MkdirAll(c.dir, 0700); err != nil {
+		return
+	}
+
+	ioutil.WriteFile(c.path(key), data, 0600)
+}
+
+type cacheTransport struct {
+	cache     *Cache
+	namespace string
+	base      http.RoundTripper
+}
+
+// RoundTrip returns the response from the cache if possible, or send the request.
+func (t *cacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet && req.Method != http.MethodPost {
+		return t.base.RoundTrip(req)
+	}
+
+	if v, _ := req.Context().Value(noCacheKey{}).(bool); v {
+		return t.base.RoundTrip(req)
+	}
+
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	key := cacheKey(t.namespace, req.Method, req.URL.String(), body)
+	if e, ok := t.cache.get(key); ok {
+		return e.response(req), nil
+	}
+
+	v, err, _ := t.cache.group.Do(key, func() (interface{}, error) {
+		resp, err := t.base.RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		data, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+
+		e := &cacheEntry{
+			StatusCode: resp.StatusCode,
+			Header:     resp.Header,
+			Body:       data,
+		}
+
+		// Only successful responses are kept.
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			t.cache.set(key, e)
+		}
+
+		return e, nil
+	})
+	if err != nil {
+		// The last response known is better than nothing till the rate limit resets.
+		if _, ok := err.(*RateLimitError); ok {
+			if e, ok := t.cache.getStale(key); ok {
+				return e.response(req), nil
+			}
+		}
+		return nil, err
+	}
+
+	return v.(*cacheEntry).response(req), nil
+}
+
+func (e *cacheEntry) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode)),
+		StatusCode:    e.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        e.Header.Clone(),
+		Body:          ioutil.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
+
+// cacheKey is a hash, to avoid writing credentials on disk.
+func cacheKey(namespace, method, url string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(namespace + "\n" + method + "\n" + url + "\n"))
+	h.Write(body)
+
+	return hex.EncodeToString(h.Sum(nil))
+}
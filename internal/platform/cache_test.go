@@ -0,0 +1,150 @@
+package platform
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func Test_CacheClient(t *testing.T) {
+	testCases := []struct {
+		name         string
+		expectedHits int32
+		ttl          time.Duration
+		persist      bool
+		requests     []string
+		namespaces   []string
+	}{
+		{
+			name:         "same request cached",
+			expectedHits: 1,
+			ttl:          time.Minute,
+			requests:     []string{"/repos/devdash", "/repos/devdash"},
+			namespaces:   []string{"github token1", "github token1"},
+		},
+		{
+			name:         "different requests",
+			expectedHits: 2,
+			ttl:          time.Minute,
+			requests:     []string{"/repos/devdash", "/repos/termui"},
+			namespaces:   []string{"github token1", "github token1"},
+		},
+		{
+			name:         "different credentials",
+			expectedHits: 2,
+			ttl:          time.Minute,
+			requests:     []string{"/repos/devdash", "/repos/devdash"},
+			namespaces:   []string{"github token1", "github token2"},
+		},
+		{
+			name:         "no TTL",
+			expectedHits: 2,
+			ttl:          0,
+			requests:     []string{"/repos/devdash", "/repos/devdash"},
+			namespaces:   []string{"github token1", "github token1"},
+		},
+		{
+			name:         "persisted on disk",
+			expectedHits: 1,
+			ttl:          time.Minute,
+			persist:      true,
+			requests:     []string{"/repos/devdash", "/repos/devdash"},
+			namespaces:   []string{"github token1", "github token1"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var hits int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&hits, 1)
+				fmt.Fprint(w, r.URL.Path)
+			}))
+			defer server.Close()
+
+			dir := ""
+			if tc.persist {
+				var err error
+				dir, err = ioutil.TempDir("", "devdash_cache")
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer os.RemoveAll(dir)
+			}
+
+			cache := NewCache(tc.ttl, dir)
+			for k, r := range tc.requests {
+				// A new cache for each request, to verify that the responses are read from the disk.
+				if tc.persist {
+					cache = NewCache(tc.ttl, dir)
+				}
+
+				client := cache.Client(tc.namespaces[k], &http.Client{})
+				resp, err := client.Get(server.URL + r)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				body, _ := ioutil.ReadAll(resp.Body)
+				resp.Body.Close()
+				if !strings.HasSuffix(r, string(body)) {
+					t.Errorf("Expected %v, actual %v", r, string(body))
+				}
+			}
+
+			if actual := atomic.LoadInt32(&hits); actual != tc.expectedHits {
+				t.Errorf("Expected %v, actual %v", tc.expectedHits, actual)
+			}
+		})
+	}
+}
+
+func Test_CacheEviction(t *testing.T) {
+	header := http.Header{}
+	header.Set(headerRateRemaining, "10")
+
+	t.Run("no TTL", func(t *testing.T) {
+		cache := NewCache(0, "")
+		cache.set("devdash", &cacheEntry{Header: header})
+		cache.set("termui", &cacheEntry{Header: http.Header{}})
+
+		// Only the stale response of the rate limited API is kept.
+		if len(cache.entries) != 0 || len(cache.stale) != 1 {
+			t.Errorf("Expected %v entries and %v stale entries, actual %v and %v", 0, 1, len(cache.entries), len(cache.stale))
+		}
+	})
+
+	t.Run("expired entries dropped", func(t *testing.T) {
+		cache := NewCache(time.Minute, "")
+		cache.entries["expired"] = &cacheEntry{Expire: time.Now().Add(-time.Second)}
+		cache.set("devdash", &cacheEntry{})
+
+		if _, ok := cache.entries["expired"]; ok {
+			t.Errorf("Expected %v, actual %v", false, ok)
+		}
+	})
+
+	t.Run("size capped", func(t *testing.T) {
+		cache := NewCache(time.Minute, "")
+		cache.max = 2
+		for _, v := range []string{"devdash", "termui", "viper"} {
+			cache.set(v, &cacheEntry{Header: header})
+			time.Sleep(time.Millisecond)
+		}
+
+		for _, entries := range []map[string]*cacheEntry{cache.entries, cache.stale} {
+			if len(entries) != 2 {
+				t.Errorf("Expected %v, actual %v", 2, len(entries))
+			}
+			if _, ok := entries["devdash"]; ok {
+				t.Errorf("Expected %v, actual %v", false, ok)
+			}
+		}
+	})
+}
@@ -224,3 +224,51 @@ func missingDays(start time.Time, end time.Time) []time.Time {
 
 	return result
 }
+
+// countPerWeek counts the dates of each week between startDate and endDate.
+// A week begins on Sunday, like the weeks of Github.
+func countPerWeek(dates []time.Time, startDate, endDate time.Time) ([]string, []int) {
+	start := time.Date(startDate.Year(), startDate.Month(), startDate.Day(), 0, 0, 0, 0, startDate.Location())
+	start = start.AddDate(0, 0, -int(start.Weekday()))
+
+	dim := []string{}
+	val := []int{}
+	for week := start; !week.After(endDate); week = week.AddDate(0, 0, 7) {
+		next := week.AddDate(0, 0, 7)
+
+		count := 0
+		for _, d := range dates {
+			if !d.Before(week) && d.Before(next) {
+				count++
+			}
+		}
+
+		dim = append(dim, week.Format("01-02"))
+		val = append(val, count)
+	}
+
+	return dim, val
+}
+
+// countPerDay counts the dates of each day between startDate and endDate.
+func countPerDay(dates []time.Time, startDate, endDate time.Time) ([]string, []int) {
+	start := time.Date(startDate.Year(), startDate.Month(), startDate.Day(), 0, 0, 0, 0, startDate.Location())
+
+	dim := []string{}
+	val := []int{}
+	for day := start; !day.After(endDate); day = day.AddDate(0, 0, 1) {
+		next := day.AddDate(0, 0, 1)
+
+		count := 0
+		for _, d := range dates {
+			if !d.Before(day) && d.Before(next) {
+				count++
+			}
+		}
+
+		dim = append(dim, day.Format("01-02"))
+		val = append(val, count)
+	}
+
+	return dim, val
+}
@@ -2,13 +2,36 @@ package platform
 
 import (
 	"bytes"
+	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
 )
 
-const git = "git"
+const (
+	git = "git"
+
+	// gitSeparator between the fields of the output, which can contain commas (like the commit messages).
+	gitSeparator = "\x1f"
+
+	gitPeriodDay  = "day"
+	gitPeriodWeek = "week"
+
+	gitFilterDirty    = "dirty"
+	gitFilterUnpushed = "unpushed"
+	gitFilterBehind   = "behind"
+
+	// gitMaxWorkers running git at the same time for multiple repositories.
+	gitMaxWorkers = 8
+)
 
 type Git struct {
 	Path string
@@ -30,16 +53,457 @@ func (g *Git) Branches() ([][]string, error) {
 	)
 	cmd.Dir = g.Path
 
-	cmdOutput := &bytes.Buffer{}
-	cmd.Stdout = cmdOutput
+	output, err := g.run(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	return formatBranches(output), nil
+}
+
+// CountCommits of the current branch between startDate and endDate, per day or per week.
+func (g *Git) CountCommits(startDate, endDate time.Time, period string) ([]string, []int, error) {
+	if period != gitPeriodDay && period != gitPeriodWeek {
+		return nil, nil, errors.Errorf("the time period %s should be %s or %s", period, gitPeriodDay, gitPeriodWeek)
+	}
+
+	cmd := exec.Command(
+		git,
+		"log",
+		"--since="+startDate.Format("2006-01-02")+" 00:00:00",
+		"--until="+endDate.Format("2006-01-02")+" 23:59:59",
+		"--format=%ct",
+	)
+	cmd.Dir = g.Path
+
+	output, err := g.run(cmd)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dates, err := parseTimestamps(output)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if period == gitPeriodWeek {
+		dim, val := countPerWeek(dates, startDate, endDate)
+		return dim, val, nil
+	}
+
+	dim, val := countPerDay(dates, startDate, endDate)
+
+	return dim, val, nil
+}
 
-	err := cmd.Run()
+func parseTimestamps(data string) ([]time.Time, error) {
+	dates := []time.Time{}
+	for _, v := range strings.Fields(data) {
+		ts, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s is not a valid timestamp", v)
+		}
+		dates = append(dates, time.Unix(ts, 0))
+	}
+
+	return dates, nil
+}
+
+// Commits of the current branch, from the newest.
+func (g *Git) Commits(limit int) ([][]string, error) {
+	cmd := exec.Command(
+		git,
+		"log",
+		"-n",
+		strconv.Itoa(limit),
+		"--date=short",
+		"--format="+strings.Join([]string{"%h", "%an", "%ad", "%s"}, gitSeparator),
+	)
+	cmd.Dir = g.Path
+
+	output, err := g.run(cmd)
 	if err != nil {
-		return nil, errors.Wrapf(err, "can't run %v", git+" "+strings.Join(cmd.Args, " "))
+		return nil, err
 	}
 
-	output := cmdOutput.Bytes()
-	return formatBranches(string(output)), nil
+	return formatGitFields([]string{"commit", "author", "date", "message"}, output), nil
+}
+
+// Authors of the commits of the current branch, from the author with the most commits.
+func (g *Git) Authors(limit int) ([][]string, error) {
+	// Without a revision, shortlog reads the standard input.
+	cmd := exec.Command(git, "shortlog", "--summary", "--numbered", "--email", "HEAD")
+	cmd.Dir = g.Path
+
+	output, err := g.run(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	return formatAuthors(output, limit), nil
+}
+
+// formatAuthors from lines like "    42\tMatthieu <matthieu@example.com>".
+func formatAuthors(data string, limit int) [][]string {
+	result := [][]string{{"author", "email", "commits"}}
+	for _, v := range strings.Split(strings.TrimSpace(data), "\n") {
+		if len(result) > limit {
+			break
+		}
+
+		f := strings.SplitN(strings.TrimSpace(v), "\t", 2)
+		if len(f) != 2 {
+			continue
+		}
+
+		author, email := f[1], ""
+		if i := strings.LastIndex(f[1], " <"); i >= 0 {
+			author = f[1][:i]
+			email = strings.Trim(f[1][i+1:], "<>")
+		}
+
+		result = append(result, []string{author, email, f[0]})
+	}
+
+	return result
+}
+
+// Status of the working tree: the current branch, the changed files and the commits ahead and behind its upstream.
+func (g *Git) Status() (string, error) {
+	st, err := g.status()
+	if err != nil {
+		return "", err
+	}
+
+	return st.String(), nil
+}
+
+func (g *Git) status() (gitStatus, error) {
+	cmd := exec.Command(git, "status", "--porcelain=v1", "--branch")
+	cmd.Dir = g.Path
+
+	output, err := g.run(cmd)
+	if err != nil {
+		return gitStatus{}, err
+	}
+
+	return parseStatus(output), nil
+}
+
+type gitStatus struct {
+	branch  string
+	changed int
+	ahead   int
+	behind  int
+}
+
+func (s gitStatus) String() string {
+	return fmt.Sprintf("%s: %d changed, %d ahead, %d behind", s.branch, s.changed, s.ahead, s.behind)
+}
+
+// parseStatus from the porcelain output, like "## master...origin/master [ahead 1, behind 2]" followed by the changed files.
+func parseStatus(data string) gitStatus {
+	lines := strings.Split(strings.TrimRight(data, "\n"), "\n")
+
+	st := gitStatus{branch: "unknown"}
+	for _, v := range lines {
+		if !strings.HasPrefix(v, "## ") {
+			if strings.TrimSpace(v) != "" {
+				st.changed++
+			}
+			continue
+		}
+
+		h := strings.TrimPrefix(strings.TrimPrefix(v, "## "), "No commits yet on ")
+		if i := strings.Index(h, " ["); i >= 0 {
+			for _, c := range strings.Split(strings.Trim(h[i+2:], "]"), ", ") {
+				if n := strings.TrimPrefix(c, "ahead "); n != c {
+					st.ahead, _ = strconv.Atoi(n)
+				}
+				if n := strings.TrimPrefix(c, "behind "); n != c {
+					st.behind, _ = strconv.Atoi(n)
+				}
+			}
+			h = h[:i]
+		}
+
+		st.branch = strings.Split(h, "...")[0]
+	}
+
+	return st
+}
+
+// lastCommit date of the current branch.
+func (g *Git) lastCommit() (string, error) {
+	cmd := exec.Command(git, "log", "-1", "--date=short", "--format=%cd")
+	cmd.Dir = g.Path
+
+	output, err := g.run(cmd)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(output), nil
+}
+
+// Tags of the repository, from the newest.
+func (g *Git) Tags(limit int) ([][]string, error) {
+	cmd := exec.Command(
+		git,
+		"for-each-ref",
+		"--sort=-creatordate",
+		"--count="+strconv.Itoa(limit),
+		"refs/tags/",
+		"--format="+strings.Join([]string{"%(refname:short)", "%(creatordate:short)", "%(subject)"}, "%1f"),
+	)
+	cmd.Dir = g.Path
+
+	output, err := g.run(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	return formatGitFields([]string{"tag", "date", "message"}, output), nil
+}
+
+// formatGitFields from lines of fields separated by gitSeparator.
+func formatGitFields(headers []string, data string) [][]string {
+	result := [][]string{headers}
+	for _, v := range strings.Split(data, "\n") {
+		if v == "" {
+			continue
+		}
+
+		f := strings.Split(v, gitSeparator)
+		for len(f) < len(headers) {
+			f = append(f, "")
+		}
+		result = append(result, f[:len(headers)])
+	}
+
+	return result
+}
+
+// GitRepositories are local repositories matching paths or globs, like "~/src/*".
+// The globs are expanded each time, to include the new repositories.
+type GitRepositories struct {
+	patterns []string
+}
+
+// gitSummary of one repository.
+type gitSummary struct {
+	name       string
+	status     gitStatus
+	lastCommit string
+	// err when the status of the repository can't be read.
+	err error
+}
+
+func NewGitRepositories(patterns []string) *GitRepositories {
+	return &GitRepositories{
+		patterns: patterns,
+	}
+}
+
+// ListRepositories with their branch, their changed files, the commits ahead and behind their upstream,
+// and their last commit, from the last commit.
+// With filters ("dirty", "unpushed", "behind"), only the repositories matching one of the filters are listed.
+// The remotes are not fetched: the commits behind are the ones already fetched.
+func (r *GitRepositories) ListRepositories(filters []string, limit int) ([][]string, error) {
+	for _, f := range filters {
+		if f != gitFilterDirty && f != gitFilterUnpushed && f != gitFilterBehind {
+			return nil, errors.Errorf("the filter %s should be %s, %s or %s", f, gitFilterDirty, gitFilterUnpushed, gitFilterBehind)
+		}
+	}
+
+	ss, err := r.summaries()
+	if err != nil {
+		return nil, err
+	}
+
+	return formatGitSummaries(ss, filters, limit), nil
+}
+
+func formatGitSummaries(ss []gitSummary, filters []string, limit int) [][]string {
+	sort.SliceStable(ss, func(i, j int) bool {
+		return ss[i].lastCommit > ss[j].lastCommit
+	})
+
+	result := [][]string{{"repository", "branch", "changed", "ahead", "behind", "last commit"}}
+	for _, v := range ss {
+		if len(result) > limit {
+			break
+		}
+
+		// The errors are never filtered out, to be noticed.
+		if len(filters) > 0 && v.err == nil && !v.matches(filters) {
+			continue
+		}
+
+		if v.err != nil {
+			result = append(result, []string{v.name, v.err.Error(), "", "", "", ""})
+			continue
+		}
+
+		lastCommit := v.lastCommit
+		if lastCommit == "" {
+			lastCommit = "none"
+		}
+
+		result = append(result, []string{
+			v.name,
+			v.status.branch,
+			strconv.Itoa(v.status.changed),
+			strconv.Itoa(v.status.ahead),
+			strconv.Itoa(v.status.behind),
+			lastCommit,
+		})
+	}
+
+	return result
+}
+
+func (s gitSummary) matches(filters []string) bool {
+	for _, f := range filters {
+		switch {
+		case f == gitFilterDirty && s.status.changed > 0:
+			return true
+		case f == gitFilterUnpushed && s.status.ahead > 0:
+			return true
+		case f == gitFilterBehind && s.status.behind > 0:
+			return true
+		}
+	}
+
+	return false
+}
+
+// Summary counts the repositories dirty, with unpushed commits and behind their upstream.
+func (r *GitRepositories) Summary() (string, error) {
+	ss, err := r.summaries()
+	if err != nil {
+		return "", err
+	}
+
+	return formatGitSummary(ss), nil
+}
+
+func formatGitSummary(ss []gitSummary) string {
+	var dirty, unpushed, behind, failed int
+	for _, v := range ss {
+		if v.err != nil {
+			failed++
+		}
+		if v.matches([]string{gitFilterDirty}) {
+			dirty++
+		}
+		if v.matches([]string{gitFilterUnpushed}) {
+			unpushed++
+		}
+		if v.matches([]string{gitFilterBehind}) {
+			behind++
+		}
+	}
+
+	summary := fmt.Sprintf("%d repositories: %d dirty, %d unpushed, %d behind", len(ss), dirty, unpushed, behind)
+	if failed > 0 {
+		summary += fmt.Sprintf(", %d with errors", failed)
+	}
+
+	return summary
+}
+
+func (r *GitRepositories) summaries() ([]gitSummary, error) {
+	paths, err := r.paths()
+	if err != nil {
+		return nil, err
+	}
+
+	var lock sync.Mutex
+	ss := []gitSummary{}
+
+	var eg errgroup.Group
+	sem := make(chan bool, gitMaxWorkers)
+	for _, v := range paths {
+		sem <- true
+		path := v
+		eg.Go(func() error {
+			defer func() { <-sem }()
+			g := NewGit(path)
+			s := gitSummary{name: filepath.Base(path)}
+
+			// One repository which can't be read doesn't hide the others.
+			s.status, s.err = g.status()
+			if s.err == nil {
+				// The repository has no commit yet.
+				s.lastCommit, _ = g.lastCommit()
+			}
+
+			lock.Lock()
+			defer lock.Unlock()
+			ss = append(ss, s)
+			return nil
+		})
+	}
+
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(ss, func(i, j int) bool { return ss[i].name < ss[j].name })
+
+	return ss, nil
+}
+
+// paths of the repositories matching the patterns, without the directories which are not repositories.
+func (r *GitRepositories) paths() ([]string, error) {
+	if len(r.patterns) == 0 {
+		return nil, errors.New("you need to specify the paths of the repositories in the git service")
+	}
+
+	home, _ := os.UserHomeDir()
+
+	found := map[string]bool{}
+	paths := []string{}
+	for _, p := range r.patterns {
+		if strings.HasPrefix(p, "~/") && home != "" {
+			p = filepath.Join(home, p[2:])
+		}
+
+		matches, err := filepath.Glob(p)
+		if err != nil {
+			return nil, errors.Wrapf(err, "the path %s is not valid", p)
+		}
+
+		for _, m := range matches {
+			// .git is a file for the worktrees and the submodules.
+			if _, err := os.Stat(filepath.Join(m, ".git")); err != nil || found[m] {
+				continue
+			}
+
+			found[m] = true
+			paths = append(paths, m)
+		}
+	}
+
+	sort.Strings(paths)
+
+	return paths, nil
+}
+
+// run the git command in the repository and return its output.
+func (g *Git) run(cmd *exec.Cmd) (string, error) {
+	return recordCommand(g.Path, strings.Join(cmd.Args, " "), func() (string, error) {
+		cmdOutput := &bytes.Buffer{}
+		cmd.Stdout = cmdOutput
+
+		err := cmd.Run()
+		if err != nil {
+			return "", errors.Wrapf(err, "can't run %v", strings.Join(cmd.Args, " "))
+		}
+
+		return cmdOutput.String(), nil
+	})
 }
 
 func formatBranches(data string) [][]string {
@@ -0,0 +1,424 @@
+package platform
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_formatAuthors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		expected [][]string
+		limit    int
+		data     string
+	}{
+		{
+			name: "happy case",
+			expected: [][]string{
+				{"author", "email", "commits"},
+				{"Matthieu Cneude", "matthieu@example.com", "42"},
+				{"bob", "", "3"},
+			},
+			limit: 5,
+			data:  "    42\tMatthieu Cneude <matthieu@example.com>\n     3\tbob\n",
+		},
+		{
+			name: "limit",
+			expected: [][]string{
+				{"author", "email", "commits"},
+				{"Matthieu Cneude", "matthieu@example.com", "42"},
+			},
+			limit: 1,
+			data:  "    42\tMatthieu Cneude <matthieu@example.com>\n     3\tbob\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := formatAuthors(tc.data, tc.limit)
+			if !reflect.DeepEqual(tc.expected, actual) {
+				t.Errorf("Expected %v, actual %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_parseStatus(t *testing.T) {
+	testCases := []struct {
+		name     string
+		expected string
+		data     string
+	}{
+		{
+			name:     "clean without upstream",
+			expected: "master: 0 changed, 0 ahead, 0 behind",
+			data:     "## master\n",
+		},
+		{
+			name:     "dirty, ahead and behind",
+			expected: "feature: 2 changed, 1 ahead, 3 behind",
+			data:     "## feature...origin/feature [ahead 1, behind 3]\n M git.go\n?? git_test.go\n",
+		},
+		{
+			name:     "behind only",
+			expected: "master: 0 changed, 0 ahead, 2 behind",
+			data:     "## master...origin/master [behind 2]\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := parseStatus(tc.data).String()
+			if tc.expected != actual {
+				t.Errorf("Expected %v, actual %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_formatGitFields(t *testing.T) {
+	expected := [][]string{
+		{"commit", "author", "date", "message"},
+		{"abc1234", "Matthieu", "2020-01-02", "Add a widget, with a comma"},
+		{"def5678", "bob", "2020-01-01", ""},
+	}
+
+	data := "abc1234\x1fMatthieu\x1f2020-01-02\x1fAdd a widget, with a comma\ndef5678\x1fbob\x1f2020-01-01\n"
+	actual := formatGitFields(expected[0], data)
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Expected %v, actual %v", expected, actual)
+	}
+}
+
+func Test_GitRepository(t *testing.T) {
+	if _, err := exec.LookPath(git); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir, err := ioutil.TempDir("", "devdash_git")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	run := func(date string, args ...string) {
+		cmd := exec.Command(git, args...)
+		cmd.Dir = dir
+		cmd.Env = append(
+			os.Environ(),
+			"GIT_AUTHOR_NAME=Matthieu",
+			"GIT_AUTHOR_EMAIL=matthieu@example.com",
+			"GIT_COMMITTER_NAME=Matthieu",
+			"GIT_COMMITTER_EMAIL=matthieu@example.com",
+			"GIT_AUTHOR_DATE="+date,
+			"GIT_COMMITTER_DATE="+date,
+		)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("%v: %s", err, out)
+		}
+	}
+
+	run("", "init", "-q")
+	run("2020-01-01T10:00:00", "commit", "-q", "--allow-empty", "-m", "First commit")
+	run("2020-01-01T12:00:00", "commit", "-q", "--allow-empty", "-m", "Second commit")
+	run("2020-01-03T10:00:00", "commit", "-q", "--allow-empty", "-m", "Third commit, with a comma")
+	run("2020-01-03T10:00:00", "tag", "-a", "v0.1.0", "-m", "First release")
+	if err := ioutil.WriteFile(filepath.Join(dir, "README.md"), []byte("devdash"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	g := NewGit(dir)
+
+	t.Run("count commits per day", func(t *testing.T) {
+		dim, val, err := g.CountCommits(
+			time.Date(2020, 01, 01, 00, 00, 00, 00, time.Local),
+			time.Date(2020, 01, 03, 00, 00, 00, 00, time.Local),
+			"day",
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expectedDim := []string{"01-01", "01-02", "01-03"}
+		expectedVal := []int{2, 0, 1}
+		if !reflect.DeepEqual(expectedDim, dim) {
+			t.Errorf("Expected %v, actual %v", expectedDim, dim)
+		}
+
+		if !reflect.DeepEqual(expectedVal, val) {
+			t.Errorf("Expected %v, actual %v", expectedVal, val)
+		}
+	})
+
+	t.Run("commits", func(t *testing.T) {
+		cs, err := g.Commits(2)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(cs) != 3 || cs[1][1] != "Matthieu" || cs[1][2] != "2020-01-03" || cs[1][3] != "Third commit, with a comma" {
+			t.Errorf("Unexpected commits %v", cs)
+		}
+	})
+
+	t.Run("authors", func(t *testing.T) {
+		expected := [][]string{
+			{"author", "email", "commits"},
+			{"Matthieu", "matthieu@example.com", "3"},
+		}
+
+		actual, err := g.Authors(5)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(expected, actual) {
+			t.Errorf("Expected %v, actual %v", expected, actual)
+		}
+	})
+
+	t.Run("status", func(t *testing.T) {
+		actual, err := g.Status()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// The name of the default branch depends on the configuration of git.
+		if ok, _ := filepath.Match("*: 1 changed, 0 ahead, 0 behind", actual); !ok {
+			t.Errorf("Unexpected status %v", actual)
+		}
+	})
+
+	t.Run("tags", func(t *testing.T) {
+		expected := [][]string{
+			{"tag", "date", "message"},
+			{"v0.1.0", "2020-01-03", "First release"},
+		}
+
+		actual, err := g.Tags(5)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(expected, actual) {
+			t.Errorf("Expected %v, actual %v", expected, actual)
+		}
+	})
+}
+
+func Test_formatGitSummaries(t *testing.T) {
+	ss := []gitSummary{
+		{name: "devdash", status: gitStatus{branch: "master", changed: 2}, lastCommit: "2020-01-02"},
+		{name: "termui", status: gitStatus{branch: "master", ahead: 1, behind: 3}, lastCommit: "2020-01-03"},
+		{name: "tview", status: gitStatus{branch: "main"}, lastCommit: "2020-01-01"},
+	}
+
+	testCases := []struct {
+		name     string
+		filters  []string
+		limit    int
+		expected [][]string
+	}{
+		{
+			name:  "every repository",
+			limit: 5,
+			expected: [][]string{
+				{"repository", "branch", "changed", "ahead", "behind", "last commit"},
+				{"termui", "master", "0", "1", "3", "2020-01-03"},
+				{"devdash", "master", "2", "0", "0", "2020-01-02"},
+				{"tview", "main", "0", "0", "0", "2020-01-01"},
+			},
+		},
+		{
+			name:  "limited",
+			limit: 1,
+			expected: [][]string{
+				{"repository", "branch", "changed", "ahead", "behind", "last commit"},
+				{"termui", "master", "0", "1", "3", "2020-01-03"},
+			},
+		},
+		{
+			name:    "dirty or unpushed",
+			filters: []string{"dirty", "unpushed"},
+			limit:   5,
+			expected: [][]string{
+				{"repository", "branch", "changed", "ahead", "behind", "last commit"},
+				{"termui", "master", "0", "1", "3", "2020-01-03"},
+				{"devdash", "master", "2", "0", "0", "2020-01-02"},
+			},
+		},
+		{
+			name:    "dirty",
+			filters: []string{"dirty"},
+			limit:   5,
+			expected: [][]string{
+				{"repository", "branch", "changed", "ahead", "behind", "last commit"},
+				{"devdash", "master", "2", "0", "0", "2020-01-02"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := formatGitSummaries(ss, tc.filters, tc.limit)
+			if !reflect.DeepEqual(tc.expected, actual) {
+				t.Errorf("Expected %v, actual %v", tc.expected, actual)
+			}
+		})
+	}
+
+	expected := "3 repositories: 1 dirty, 1 unpushed, 1 behind"
+	if actual := formatGitSummary(ss); expected != actual {
+		t.Errorf("Expected %v, actual %v", expected, actual)
+	}
+}
+
+func Test_GitRepositories(t *testing.T) {
+	if _, err := exec.LookPath(git); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir, err := ioutil.TempDir("", "devdash_git")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	run := func(repo string, args ...string) {
+		cmd := exec.Command(git, args...)
+		cmd.Dir = filepath.Join(dir, repo)
+		cmd.Env = append(
+			os.Environ(),
+			"GIT_AUTHOR_NAME=Matthieu",
+			"GIT_AUTHOR_EMAIL=matthieu@example.com",
+			"GIT_COMMITTER_NAME=Matthieu",
+			"GIT_COMMITTER_EMAIL=matthieu@example.com",
+			"GIT_AUTHOR_DATE=2020-01-01T10:00:00",
+			"GIT_COMMITTER_DATE=2020-01-01T10:00:00",
+		)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("%v: %s", err, out)
+		}
+	}
+
+	for _, v := range []string{"clean", "dirty", "empty", "not_a_repo"} {
+		if err := os.Mkdir(filepath.Join(dir, v), 0700); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	run("clean", "init", "-q")
+	run("clean", "commit", "-q", "--allow-empty", "-m", "First commit")
+	run("dirty", "init", "-q")
+	run("dirty", "commit", "-q", "--allow-empty", "-m", "First commit")
+	run("empty", "init", "-q")
+	if err := ioutil.WriteFile(filepath.Join(dir, "dirty", "README.md"), []byte("devdash"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewGitRepositories([]string{filepath.Join(dir, "*"), filepath.Join(dir, "clean")})
+
+	t.Run("repositories", func(t *testing.T) {
+		actual, err := r.ListRepositories(nil, 5)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(actual) != 4 {
+			t.Fatalf("Expected %v rows, actual %v", 4, actual)
+		}
+
+		if actual[3][0] != "empty" || actual[3][5] != "none" {
+			t.Errorf("Unexpected repository without commit %v", actual[3])
+		}
+	})
+
+	t.Run("dirty repositories", func(t *testing.T) {
+		actual, err := r.ListRepositories([]string{"dirty"}, 5)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(actual) != 2 || actual[1][0] != "dirty" || actual[1][2] != "1" || actual[1][5] != "2020-01-01" {
+			t.Errorf("Unexpected dirty repositories %v", actual)
+		}
+	})
+
+	t.Run("unknown filter", func(t *testing.T) {
+		if _, err := r.ListRepositories([]string{"stale"}, 5); err == nil {
+			t.Error("Expected an error for an unknown filter")
+		}
+	})
+
+	t.Run("summary", func(t *testing.T) {
+		expected := "3 repositories: 1 dirty, 0 unpushed, 0 behind"
+		actual, err := r.Summary()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if expected != actual {
+			t.Errorf("Expected %v, actual %v", expected, actual)
+		}
+	})
+
+	t.Run("no path", func(t *testing.T) {
+		if _, err := NewGitRepositories(nil).Summary(); err == nil {
+			t.Error("Expected an error without paths")
+		}
+	})
+}
+
+func Test_GitRepositoriesError(t *testing.T) {
+	if _, err := exec.LookPath(git); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir, err := ioutil.TempDir("", "devdash_git")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, v := range []string{"broken", "clean"} {
+		if err := os.Mkdir(filepath.Join(dir, v), 0700); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if out, err := exec.Command(git, "init", "-q", filepath.Join(dir, "clean")).CombinedOutput(); err != nil {
+		t.Fatalf("%v: %s", err, out)
+	}
+
+	// The git directory of the repository doesn't exist.
+	gitdir := []byte("gitdir: " + filepath.Join(dir, "missing"))
+	if err := ioutil.WriteFile(filepath.Join(dir, "broken", ".git"), gitdir, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewGitRepositories([]string{filepath.Join(dir, "*")})
+
+	actual, err := r.ListRepositories([]string{"dirty"}, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(actual) != 2 || actual[1][0] != "broken" || actual[1][1] == "" {
+		t.Errorf("Unexpected repositories %v", actual)
+	}
+
+	expected := "2 repositories: 0 dirty, 0 unpushed, 0 behind, 1 with errors"
+	summary, err := r.Summary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected != summary {
+		t.Errorf("Expected %v, actual %v", expected, summary)
+	}
+}
@@ -0,0 +1,324 @@
+package platform
+
+// gitea connects to the REST API of a Gitea (or Forgejo) instance.
+// See https://docs.gitea.io/en-us/api-usage/
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	giteaMaxPerPage = 50
+	// giteaMaxPages limits the number of requests to count commits.
+	giteaMaxPages = 10
+)
+
+// Gitea connects to the Gitea API.
+type Gitea struct {
+	client   *http.Client
+	baseURL  string
+	token    string
+	owner    string
+	repoName string
+}
+
+type giteaRepository struct {
+	StarsCount      int `json:"stars_count"`
+	OpenIssuesCount int `json:"open_issues_count"`
+}
+
+type giteaUser struct {
+	Login string `json:"login"`
+}
+
+type giteaIssue struct {
+	Title     string     `json:"title"`
+	State     string     `json:"state"`
+	CreatedAt *time.Time `json:"created_at"`
+}
+
+type giteaPullRequest struct {
+	Title     string     `json:"title"`
+	State     string     `json:"state"`
+	CreatedAt *time.Time `json:"created_at"`
+	Merged    bool       `json:"merged"`
+	User      *giteaUser `json:"user"`
+}
+
+type giteaRelease struct {
+	TagName     string     `json:"tag_name"`
+	Name        string     `json:"name"`
+	Draft       bool       `json:"draft"`
+	Prerelease  bool       `json:"prerelease"`
+	PublishedAt *time.Time `json:"published_at"`
+}
+
+type giteaCommit struct {
+	Created time.Time `json:"created"`
+}
+
+// NewGiteaClient for the Gitea instance at baseURL.
+func NewGiteaClient(baseURL string, token string, owner string, repoName string) (*Gitea, error) {
+	if baseURL == "" {
+		return nil, errors.New("you need to specify the base_url of your Gitea instance in the gitea service")
+	}
+
+	return &Gitea{
+		client:   cachedHTTPClient("gitea "+token, &http.Client{}),
+		baseURL:  strings.TrimSuffix(baseURL, "/"),
+		token:    token,
+		owner:    owner,
+		repoName: repoName,
+	}, nil
+}
+
+// TotalStars of a repository.
+func (g *Gitea) TotalStars(repository string) (int, error) {
+	r := giteaRepository{}
+	if err := g.getRepo(repository, "", nil, &r); err != nil {
+		return 0, err
+	}
+
+	return r.StarsCount, nil
+}
+
+// ListIssues of a repository, without the pull requests.
+func (g *Gitea) ListIssues(repository string, limit int) ([][]string, error) {
+	is := []giteaIssue{}
+	params := url.Values{"state": {"all"}, "type": {"issues"}, "limit": {strconv.Itoa(limit)}}
+	if err := g.getRepo(repository, "issues", params, &is); err != nil {
+		return nil, err
+	}
+
+	return formatGiteaIssues(is, limit), nil
+}
+
+func formatGiteaIssues(is []giteaIssue, limit int) [][]string {
+	if limit > len(is) {
+		limit = len(is)
+	}
+
+	issues := make([][]string, limit+1)
+	issues[0] = []string{"name", "state"}
+	for k, v := range is {
+		if k < limit {
+			issues[k+1] = append(issues[k+1], v.Title, v.State)
+		}
+	}
+
+	return issues
+}
+
+// ListPullRequests of a repository.
+func (g *Gitea) ListPullRequests(repository string, limit int) ([][]string, error) {
+	prs := []giteaPullRequest{}
+	params := url.Values{"state": {"all"}, "limit": {strconv.Itoa(limit)}}
+	if err := g.getRepo(repository, "pulls", params, &prs); err != nil {
+		return nil, err
+	}
+
+	return formatGiteaPullRequests(prs, limit), nil
+}
+
+func formatGiteaPullRequests(prs []giteaPullRequest, limit int) [][]string {
+	if limit > len(prs) {
+		limit = len(prs)
+	}
+
+	headers := []string{"title", "state", "created at", "merged", "author"}
+
+	defaultHeader := "unknown"
+	table := make([][]string, limit+1)
+	table[0] = headers
+	for k, v := range prs {
+		n := defaultHeader
+		if v.Title != "" {
+			n = v.Title
+		}
+
+		state := defaultHeader
+		if v.State != "" {
+			state = v.State
+		}
+
+		createdAt := defaultHeader
+		if v.CreatedAt != nil {
+			createdAt = v.CreatedAt.String()
+		}
+
+		author := defaultHeader
+		if v.User != nil {
+			author = v.User.Login
+		}
+
+		if k < limit {
+			table[k+1] = append(table[k+1], n, state, createdAt, strconv.FormatBool(v.Merged), author)
+		}
+	}
+
+	return table
+}
+
+// ListReleases of a repository.
+func (g *Gitea) ListReleases(repository string, limit int) ([][]string, error) {
+	rs := []giteaRelease{}
+	params := url.Values{"limit": {strconv.Itoa(limit)}}
+	if err := g.getRepo(repository, "releases", params, &rs); err != nil {
+		return nil, err
+	}
+
+	return formatGiteaReleases(rs, limit), nil
+}
+
+func formatGiteaReleases(rs []giteaRelease, limit int) [][]string {
+	if limit > len(rs) {
+		limit = len(rs)
+	}
+
+	defaultHeader := "unknown"
+	table := make([][]string, limit+1)
+	table[0] = []string{"tag", "name", "published at", "prerelease"}
+	for k, v := range rs {
+		publishedAt := defaultHeader
+		if v.Draft {
+			publishedAt = "draft"
+		} else if v.PublishedAt != nil {
+			publishedAt = v.PublishedAt.Format("2006-01-02")
+		}
+
+		if k < limit {
+			table[k+1] = append(table[k+1], v.TagName, v.Name, publishedAt, strconv.FormatBool(v.Prerelease))
+		}
+	}
+
+	return table
+}
+
+// CountCommits of the default branch of a repository per week, between startDate and endDate.
+func (g *Gitea) CountCommits(repository string, startDate, endDate time.Time) ([]string, []int, error) {
+	commits := []giteaCommit{}
+	for page := 1; ; page++ {
+		cs := []giteaCommit{}
+		params := url.Values{
+			"since": {startDate.Format(time.RFC3339)},
+			"until": {endDate.Format(time.RFC3339)},
+			"limit": {strconv.Itoa(giteaMaxPerPage)},
+			"page":  {strconv.Itoa(page)},
+			"stat":  {"false"},
+		}
+		if err := g.getRepo(repository, "commits", params, &cs); err != nil {
+			return nil, nil, err
+		}
+
+		// One more page is fetched to know if every commit has been counted.
+		if page > giteaMaxPages {
+			if len(cs) > 0 {
+				return nil, nil, errors.Errorf(
+					"more than %d commits between %s and %s: reduce the time period",
+					len(commits),
+					startDate.Format("2006-01-02"),
+					endDate.Format("2006-01-02"),
+				)
+			}
+			break
+		}
+
+		commits = append(commits, cs...)
+
+		// The commits are sorted from the newest to the oldest.
+		// The old instances of Gitea don't filter them with the dates.
+		if len(cs) < giteaMaxPerPage || cs[len(cs)-1].Created.Before(startDate) {
+			break
+		}
+	}
+
+	dim, val := formatGiteaCountCommits(commits, startDate, endDate)
+
+	return dim, val, nil
+}
+
+func formatGiteaCountCommits(commits []giteaCommit, startDate, endDate time.Time) ([]string, []int) {
+	dates := make([]time.Time, 0, len(commits))
+	for _, c := range commits {
+		dates = append(dates, c.Created)
+	}
+
+	return countPerWeek(dates, startDate, endDate)
+}
+
+// repoPath of the repository, like "owner/repository".
+func (g *Gitea) repoPath(repository string) (string, error) {
+	repo := g.repoName
+	if repository != "" {
+		repo = repository
+	}
+
+	if repo == "" {
+		return "", errors.New("you need to specify a repository in the gitea service or in the widget")
+	}
+
+	if strings.Contains(repo, "/") {
+		return repo, nil
+	}
+
+	if g.owner == "" {
+		return "", errors.New("you need to specify an owner in the gitea service")
+	}
+
+	return g.owner + "/" + repo, nil
+}
+
+// getRepo decodes the response of an endpoint of the repository in v.
+func (g *Gitea) getRepo(repository string, endpoint string, params url.Values, v interface{}) error {
+	repo, err := g.repoPath(repository)
+	if err != nil {
+		return err
+	}
+
+	u := fmt.Sprintf("%s/api/v1/repos/%s", g.baseURL, repo)
+	if endpoint != "" {
+		u += "/" + endpoint
+	}
+	if len(params) > 0 {
+		u += "?" + params.Encode()
+	}
+
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+
+	if g.token != "" {
+		req.Header.Set("Authorization", "token "+g.token)
+	}
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "can't fetch repo %s", repo)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return errors.Wrapf(err, "can't read response for repo %s", repo)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("can't find repo %s: gitea API returned %s", repo, resp.Status)
+	}
+
+	if err := json.Unmarshal(body, v); err != nil {
+		return errors.Wrapf(err, "can't read response for repo %s", repo)
+	}
+
+	return nil
+}
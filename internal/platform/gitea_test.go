@@ -0,0 +1,301 @@
+package platform
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func Test_formatGiteaPullRequests(t *testing.T) {
+	testCases := []struct {
+		name        string
+		expected    [][]string
+		limit       int
+		fixtureFile string
+	}{
+		{
+			name: "happy case",
+			expected: [][]string{
+				{"title", "state", "created at", "merged", "author"},
+				{"Add a gitea service", "closed", "2020-01-02 10:00:00 +0000 UTC", "true", "phantas0s"},
+				{"Fix the documentation", "open", "2020-01-01 10:00:00 +0000 UTC", "false", "unknown"},
+			},
+			limit:       5,
+			fixtureFile: "./testdata/fixtures/gitea_pulls.json",
+		},
+		{
+			name: "limit",
+			expected: [][]string{
+				{"title", "state", "created at", "merged", "author"},
+				{"Add a gitea service", "closed", "2020-01-02 10:00:00 +0000 UTC", "true", "phantas0s"},
+			},
+			limit:       1,
+			fixtureFile: "./testdata/fixtures/gitea_pulls.json",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			prs := []giteaPullRequest{}
+			fixtures := ReadFixtureFile(tc.fixtureFile, t)
+			if err := json.Unmarshal(fixtures, &prs); err != nil {
+				t.Error(err)
+			}
+
+			actual := formatGiteaPullRequests(prs, tc.limit)
+			if !reflect.DeepEqual(tc.expected, actual) {
+				t.Errorf("Expected %v, actual %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_formatGiteaReleases(t *testing.T) {
+	testCases := []struct {
+		name        string
+		expected    [][]string
+		limit       int
+		fixtureFile string
+	}{
+		{
+			name: "happy case",
+			expected: [][]string{
+				{"tag", "name", "published at", "prerelease"},
+				{"v0.3.0", "Next", "draft", "false"},
+				{"v0.2.0-rc1", "Release candidate", "2020-01-02", "true"},
+				{"v0.1.0", "First release", "2020-01-01", "false"},
+			},
+			limit:       5,
+			fixtureFile: "./testdata/fixtures/gitea_releases.json",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rs := []giteaRelease{}
+			fixtures := ReadFixtureFile(tc.fixtureFile, t)
+			if err := json.Unmarshal(fixtures, &rs); err != nil {
+				t.Error(err)
+			}
+
+			actual := formatGiteaReleases(rs, tc.limit)
+			if !reflect.DeepEqual(tc.expected, actual) {
+				t.Errorf("Expected %v, actual %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_formatGiteaCountCommits(t *testing.T) {
+	testCases := []struct {
+		name        string
+		expectedDim []string
+		expectedVal []int
+		startDate   time.Time
+		endDate     time.Time
+		fixtureFile string
+	}{
+		{
+			name:        "weeks beginning on sunday",
+			expectedDim: []string{"12-29", "01-05", "01-12"},
+			expectedVal: []int{1, 1, 2},
+			startDate:   time.Date(2020, 01, 01, 00, 00, 00, 00, time.UTC),
+			endDate:     time.Date(2020, 01, 15, 00, 00, 00, 00, time.UTC),
+			fixtureFile: "./testdata/fixtures/gitea_commits.json",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cs := []giteaCommit{}
+			fixtures := ReadFixtureFile(tc.fixtureFile, t)
+			if err := json.Unmarshal(fixtures, &cs); err != nil {
+				t.Error(err)
+			}
+
+			dim, val := formatGiteaCountCommits(cs, tc.startDate, tc.endDate)
+			if !reflect.DeepEqual(tc.expectedDim, dim) {
+				t.Errorf("Expected %v, actual %v", tc.expectedDim, dim)
+			}
+
+			if !reflect.DeepEqual(tc.expectedVal, val) {
+				t.Errorf("Expected %v, actual %v", tc.expectedVal, val)
+			}
+		})
+	}
+}
+
+func Test_GiteaClient(t *testing.T) {
+	fixtures := map[string][]byte{
+		"/api/v1/repos/phantas0s/devdash":        ReadFixtureFile("./testdata/fixtures/gitea_repo.json", t),
+		"/api/v1/repos/phantas0s/devdash/issues": ReadFixtureFile("./testdata/fixtures/gitea_issues.json", t),
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "token token" {
+			t.Errorf("Unexpected authorization %s", r.Header.Get("Authorization"))
+		}
+
+		f, ok := fixtures[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Write(f)
+	}))
+	defer server.Close()
+
+	t.Run("total stars", func(t *testing.T) {
+		testCases := []struct {
+			name       string
+			expected   int
+			owner      string
+			repository string
+			wantErr    bool
+		}{
+			{
+				name:       "owner and repository",
+				expected:   42,
+				owner:      "phantas0s",
+				repository: "devdash",
+			},
+			{
+				name:       "repository with owner",
+				expected:   42,
+				repository: "phantas0s/devdash",
+			},
+			{
+				name:       "unknown repository",
+				owner:      "phantas0s",
+				repository: "termui",
+				wantErr:    true,
+			},
+			{
+				name:       "no owner",
+				repository: "devdash",
+				wantErr:    true,
+			},
+			{
+				name:    "no repository",
+				owner:   "phantas0s",
+				wantErr: true,
+			},
+		}
+
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				g, err := NewGiteaClient(server.URL, "token", tc.owner, "")
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				actual, err := g.TotalStars(tc.repository)
+				if (err != nil) != tc.wantErr {
+					t.Errorf("Error '%v' even if wantErr is %t", err, tc.wantErr)
+					return
+				}
+
+				if tc.wantErr == false && actual != tc.expected {
+					t.Errorf("Expected %v, actual %v", tc.expected, actual)
+				}
+			})
+		}
+	})
+
+	t.Run("list issues", func(t *testing.T) {
+		g, err := NewGiteaClient(server.URL+"/", "token", "phantas0s", "devdash")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := [][]string{
+			{"name", "state"},
+			{"Add a gitea service", "open"},
+		}
+
+		actual, err := g.ListIssues("", 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(expected, actual) {
+			t.Errorf("Expected %v, actual %v", expected, actual)
+		}
+	})
+
+	t.Run("no base url", func(t *testing.T) {
+		if _, err := NewGiteaClient("", "token", "phantas0s", "devdash"); err == nil {
+			t.Error("Expected an error without base url")
+		}
+	})
+}
+
+func Test_GiteaCountCommits(t *testing.T) {
+	testCases := []struct {
+		name        string
+		expectedVal []int
+		commits     int
+		wantErr     bool
+	}{
+		{
+			name:        "last page not full",
+			expectedVal: []int{70, 0, 0},
+			commits:     70,
+		},
+		{
+			name:        "every page full",
+			expectedVal: []int{giteaMaxPages * giteaMaxPerPage, 0, 0},
+			commits:     giteaMaxPages * giteaMaxPerPage,
+		},
+		{
+			name:    "more commits than the pages fetched",
+			commits: giteaMaxPages*giteaMaxPerPage + 1,
+			wantErr: true,
+		},
+	}
+
+	startDate := time.Date(2020, 01, 01, 00, 00, 00, 00, time.UTC)
+	endDate := time.Date(2020, 01, 15, 00, 00, 00, 00, time.UTC)
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Query().Get("since") != startDate.Format(time.RFC3339) {
+					t.Errorf("Expected %v, actual %v", startDate.Format(time.RFC3339), r.URL.Query().Get("since"))
+				}
+
+				page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+				n := tc.commits - (page-1)*giteaMaxPerPage
+				if n > giteaMaxPerPage {
+					n = giteaMaxPerPage
+				}
+
+				cs := []giteaCommit{}
+				for i := 0; i < n; i++ {
+					cs = append(cs, giteaCommit{Created: startDate.Add(time.Hour)})
+				}
+				json.NewEncoder(w).Encode(cs)
+			}))
+			defer server.Close()
+
+			g, err := NewGiteaClient(server.URL, "token", "phantas0s", "devdash")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, val, err := g.CountCommits("", startDate, endDate)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Error '%v' even if wantErr is %t", err, tc.wantErr)
+			}
+
+			if !reflect.DeepEqual(tc.expectedVal, val) {
+				t.Errorf("Expected %v, actual %v", tc.expectedVal, val)
+			}
+		})
+	}
+}
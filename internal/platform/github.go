@@ -6,8 +6,15 @@ package platform
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
 	"sort"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -27,34 +34,294 @@ const (
 	githubScopeAll   = "all"
 
 	githubMaxPerPage = 100
+
+	githubDownloadsPerRelease = "release"
+	githubDownloadsPerAsset   = "asset"
 )
 
+// GithubRunFilter filters the workflow runs of Github Actions.
+// Workflow can be the name of the workflow or its file, like "ci.yml".
+type GithubRunFilter struct {
+	Workflow string
+	Branch   string
+	Event    string
+}
+
+// go-github v28 doesn't support the Actions API; the runs are decoded here.
+type githubWorkflowRuns struct {
+	TotalCount   int                  `json:"total_count"`
+	WorkflowRuns []*githubWorkflowRun `json:"workflow_runs"`
+}
+
+type githubWorkflowRun struct {
+	ID           int64      `json:"id"`
+	RunNumber    int        `json:"run_number"`
+	Name         string     `json:"name"`
+	HeadBranch   string     `json:"head_branch"`
+	Event        string     `json:"event"`
+	Status       string     `json:"status"`
+	Conclusion   string     `json:"conclusion"`
+	CreatedAt    *time.Time `json:"created_at"`
+	UpdatedAt    *time.Time `json:"updated_at"`
+	RunStartedAt *time.Time `json:"run_started_at"`
+}
+
+// result of a run: its conclusion when completed, its status otherwise.
+func (r *githubWorkflowRun) result() string {
+	if r.Status == "completed" && r.Conclusion != "" {
+		return r.Conclusion
+	}
+
+	return r.Status
+}
+
+// duration of a completed run.
+func (r *githubWorkflowRun) duration() (time.Duration, bool) {
+	start := r.RunStartedAt
+	if start == nil {
+		start = r.CreatedAt
+	}
+
+	if r.Status != "completed" || start == nil || r.UpdatedAt == nil {
+		return 0, false
+	}
+
+	return r.UpdatedAt.Sub(*start), true
+}
+
 // Github structure connects to the Github API.
 type Github struct {
-	client   *github.Client
-	repo     *github.Repository
-	repoName string
-	owner    string
+	client     *github.Client
+	repo       *github.Repository
+	repoName   string
+	owner      string
+	rateLimits *RateLimits
+	// graphQL API used instead of the REST API for the heaviest widgets.
+	graphQL    bool
+	graphQLURL string
+}
+
+const (
+	githubResourceCore    = "core"
+	githubResourceSearch  = "search"
+	githubResourceGraphQL = "graphql"
+)
+
+// githubRateLimits per token: the services using the same token share the same rate limits.
+var (
+	githubRateLimitsMu sync.Mutex
+	githubRateLimits   = map[string]*RateLimits{}
+)
+
+func githubRateLimit(token string) *RateLimits {
+	githubRateLimitsMu.Lock()
+	defer githubRateLimitsMu.Unlock()
+
+	if _, ok := githubRateLimits[token]; !ok {
+		githubRateLimits[token] = NewRateLimits(githubResource)
+	}
+
+	return githubRateLimits[token]
+}
+
+// githubResource of the rate limit used by the request.
+func githubResource(req *http.Request) string {
+	switch {
+	case strings.HasPrefix(req.URL.Path, "/search/") || strings.Contains(req.URL.Path, "/api/v3/search/"):
+		return githubResourceSearch
+	case strings.HasSuffix(req.URL.Path, "/graphql"):
+		return githubResourceGraphQL
+	default:
+		return githubResourceCore
+	}
+}
+
+// githubRateLimitRequest fetches the rate limits: it's not rate limited and it's never cached.
+func githubRateLimitRequest(req *http.Request) bool {
+	return strings.HasSuffix(req.URL.Path, "/rate_limit")
+}
+
+// githubQuotaTransport hides the reset of the rate limit from go-github.
+// go-github refuses to send any request once a response says that no request remains, without knowing the resource
+// (the GraphQL API is counted as the core API): the rate limits are already tracked for each resource by devdash,
+// which serves the last responses known from the cache till the reset.
+// The cache and the recordings keep the original headers.
+type githubQuotaTransport struct {
+	base http.RoundTripper
+}
+
+func (t *githubQuotaTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.Header.Get(headerRateRemaining) == "0" {
+		resp.Header = resp.Header.Clone()
+		resp.Header.Del(headerRateReset)
+	}
+
+	return resp, nil
+}
+
+// GithubEnterprise is the location of a Github Enterprise Server, with the CA certificates of its TLS connection.
+// Github.com is used if BaseURL is empty.
+type GithubEnterprise struct {
+	// BaseURL of the API, like https://github.example.com/ (the path /api/v3/ is added if missing).
+	BaseURL string
+	// UploadURL of the API, deduced from BaseURL if empty.
+	UploadURL string
+	// CACert is a PEM file of certificates, added to the certificates of the system.
+	CACert string
 }
 
 // GithubClient to fetch Github related data.
-func NewGithubClient(token string, owner string, repoName string) (*Github, error) {
+// The api can be "rest" (default) or "graphql", to fetch the stars, the issues, the pull requests and the commits with the GraphQL API.
+func NewGithubClient(token string, owner string, repoName string, api string, enterprise GithubEnterprise) (*Github, error) {
+	if api != "" && api != githubAPIRest && api != githubAPIGraphQL {
+		return nil, errors.Errorf("the api %s of the github service should be %s or %s", api, githubAPIRest, githubAPIGraphQL)
+	}
+
 	ctx := context.Background()
+	if enterprise.CACert != "" {
+		hc, err := caCertHTTPClient(enterprise.CACert)
+		if err != nil {
+			return nil, err
+		}
+		ctx = context.WithValue(ctx, oauth2.HTTPClient, hc)
+	}
+
 	ts := oauth2.StaticTokenSource(
 		&oauth2.Token{AccessToken: token},
 	)
 	tc := oauth2.NewClient(ctx, ts)
 
+	// Fetching the rate limit doesn't count in the rate limit.
+	rl := githubRateLimit(enterprise.BaseURL + " " + token)
+	tc.Transport = &rateLimitTransport{
+		rateLimits: rl,
+		base:       tc.Transport,
+		exempt:     githubRateLimitRequest,
+	}
+
 	// get go-github client
-	client := github.NewClient(tc)
+	hc := cachedHTTPClient("github "+token, tc)
+	hc.Transport = &githubQuotaTransport{base: hc.Transport}
+	client := github.NewClient(hc)
+
+	graphQLURL := githubGraphQLEndpoint
+	if enterprise.BaseURL != "" {
+		var uploadURL string
+		var err error
+		graphQLURL, uploadURL, err = enterpriseURLs(&enterprise)
+		if err != nil {
+			return nil, err
+		}
+
+		client, err = github.NewEnterpriseClient(enterprise.BaseURL, uploadURL, hc)
+		if err != nil {
+			return nil, errors.Wrapf(err, "can't use the base_url %s of the github service", enterprise.BaseURL)
+		}
+	}
 
 	return &Github{
-		client:   client,
-		repoName: repoName,
-		owner:    owner,
+		client:     client,
+		repoName:   repoName,
+		owner:      owner,
+		rateLimits: rl,
+		graphQL:    api == githubAPIGraphQL,
+		graphQLURL: graphQLURL,
 	}, nil
 }
 
+// enterpriseURLs completes the base URL of a Github Enterprise Server with the path of the API,
+// and returns the URLs of its GraphQL API and its upload API.
+func enterpriseURLs(enterprise *GithubEnterprise) (graphQLURL string, uploadURL string, err error) {
+	u, err := url.Parse(enterprise.BaseURL)
+	if err != nil || u.Host == "" {
+		return "", "", errors.Errorf("the base_url %s of the github service is not a valid URL", enterprise.BaseURL)
+	}
+
+	if strings.Trim(u.Path, "/") == "" {
+		u.Path = "/api/v3/"
+	}
+	enterprise.BaseURL = u.String()
+
+	root := *u
+	root.Path = strings.TrimSuffix(strings.TrimSuffix(u.Path, "/"), "/v3")
+
+	uploadURL = enterprise.UploadURL
+	if uploadURL == "" {
+		upload := root
+		upload.Path += "/uploads/"
+		uploadURL = upload.String()
+	}
+
+	root.Path += "/graphql"
+
+	return root.String(), uploadURL, nil
+}
+
+// caCertHTTPClient trusts the certificates of the PEM file, in addition to the certificates of the system.
+func caCertHTTPClient(caCert string) (*http.Client, error) {
+	pem, err := ioutil.ReadFile(caCert)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't read the CA certificates %s", caCert)
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, errors.Errorf("can't find any PEM certificate in %s", caCert)
+	}
+
+	tr := http.DefaultTransport.(*http.Transport).Clone()
+	tr.TLSClientConfig = &tls.Config{RootCAs: pool}
+
+	return &http.Client{Transport: tr}, nil
+}
+
+// RateLimit of a resource of the API (core, search or graphql): the requests remaining, the maximum and the time of the reset.
+func (g *Github) RateLimit(resource string) (remaining int, limit int, reset time.Time, err error) {
+	if resource != githubResourceCore && resource != githubResourceSearch && resource != githubResourceGraphQL {
+		return 0, 0, time.Time{}, errors.Errorf(
+			"unknown resource %s (possible resources: %s, %s or %s)",
+			resource,
+			githubResourceCore,
+			githubResourceSearch,
+			githubResourceGraphQL,
+		)
+	}
+
+	// The rate limit tracked is updated by every request, even when the response of the API is cached.
+	if l, r, rs := g.rateLimits.Get(resource).State(); l > 0 && rs.After(time.Now()) {
+		return r, l, rs, nil
+	}
+
+	// The resource has not been requested yet: its rate limit is fetched.
+	req, err := g.client.NewRequest("GET", "rate_limit", nil)
+	if err != nil {
+		return 0, 0, time.Time{}, err
+	}
+
+	rl := struct {
+		Resources map[string]*github.Rate `json:"resources"`
+	}{}
+	if _, err := g.client.Do(withoutCache(context.Background()), req, &rl); err != nil {
+		return 0, 0, time.Time{}, errors.Wrap(err, "can't fetch the rate limit")
+	}
+
+	rate, ok := rl.Resources[resource]
+	if !ok || rate == nil {
+		return 0, 0, time.Time{}, errors.Errorf("can't find the rate limit of the %s API", resource)
+	}
+
+	return rate.Remaining, rate.Limit, rate.Reset.Time, nil
+}
+
 // TotalStars of a repository.
 func (g *Github) TotalStars(repository string) (int, error) {
 	r, err := g.fetchRepo(repository)
@@ -272,6 +539,350 @@ func formatListPullRequests(is []*github.PullRequest, limit int) [][]string {
 	return prs
 }
 
+// ListWorkflowRuns of Github Actions for a repository.
+func (g *Github) ListWorkflowRuns(repository string, filter GithubRunFilter, limit int) ([][]string, error) {
+	rs, err := g.fetchWorkflowRuns(repository, filter, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return formatWorkflowRuns(rs, limit), nil
+}
+
+func formatWorkflowRuns(rs []*githubWorkflowRun, limit int) [][]string {
+	if limit > len(rs) {
+		limit = len(rs)
+	}
+
+	defaultHeader := "unknown"
+	runs := make([][]string, limit+1)
+	runs[0] = []string{"run", "workflow", "branch", "event", "status", "created at", "duration"}
+	for k, v := range rs {
+		createdAt := defaultHeader
+		if v.CreatedAt != nil {
+			createdAt = v.CreatedAt.Format("2006-01-02 15:04")
+		}
+
+		duration := "-"
+		if d, ok := v.duration(); ok {
+			duration = d.String()
+		}
+
+		if k < limit {
+			runs[k+1] = append(
+				runs[k+1],
+				"#"+strconv.Itoa(v.RunNumber),
+				v.Name,
+				v.HeadBranch,
+				v.Event,
+				v.result(),
+				createdAt,
+				duration,
+			)
+		}
+	}
+
+	return runs
+}
+
+// LastRunStatus of Github Actions for a repository.
+func (g *Github) LastRunStatus(repository string, filter GithubRunFilter) (string, error) {
+	rs, err := g.fetchWorkflowRuns(repository, filter, 1)
+	if err != nil {
+		return "", err
+	}
+
+	if len(rs) == 0 {
+		return "no run", nil
+	}
+
+	return rs[0].result(), nil
+}
+
+// RunDurations of the last completed runs of Github Actions for a repository, in seconds.
+func (g *Github) RunDurations(repository string, filter GithubRunFilter, limit int) ([]string, []int, error) {
+	rs, err := g.fetchWorkflowRuns(repository, filter, githubMaxPerPage)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dim, val := formatRunDurations(rs, limit)
+
+	return dim, val, nil
+}
+
+func formatRunDurations(rs []*githubWorkflowRun, limit int) (dim []string, val []int) {
+	// The runs are sorted from the newest to the oldest.
+	for _, v := range rs {
+		if len(dim) >= limit {
+			break
+		}
+
+		if d, ok := v.duration(); ok {
+			dim = append([]string{"#" + strconv.Itoa(v.RunNumber)}, dim...)
+			val = append([]int{int(d.Seconds())}, val...)
+		}
+	}
+
+	return dim, val
+}
+
+// ListReleases of a repository, with their assets and downloads.
+func (g *Github) ListReleases(repository string, limit int) ([][]string, error) {
+	rs, err := g.fetchReleases(repository, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return formatListReleases(rs, limit), nil
+}
+
+func formatListReleases(rs []*github.RepositoryRelease, limit int) [][]string {
+	if limit > len(rs) {
+		limit = len(rs)
+	}
+
+	headers := []string{"tag", "date", "assets", "downloads"}
+
+	defaultHeader := "unknown"
+	releases := make([][]string, limit+1)
+	releases[0] = headers
+	for k, v := range rs {
+		date := defaultHeader
+		if v.PublishedAt != nil {
+			date = v.PublishedAt.Format("2006-01-02")
+		} else if v.GetDraft() {
+			date = "draft"
+		}
+
+		if k < limit {
+			releases[k+1] = append(
+				releases[k+1],
+				v.GetTagName(),
+				date,
+				strconv.Itoa(len(v.Assets)),
+				strconv.Itoa(releaseDownloads(v)),
+			)
+		}
+	}
+
+	return releases
+}
+
+// CountDownloads of the assets of a repository, per release or per asset.
+// Per asset, the assets of the release with the tag given are counted (the latest release if empty).
+func (g *Github) CountDownloads(repository string, per string, tag string, limit int) ([]string, []int, error) {
+	switch per {
+	case githubDownloadsPerRelease:
+		rs, err := g.fetchReleases(repository, limit)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		dim, val := formatDownloadsPerRelease(rs, limit)
+		return dim, val, nil
+	case githubDownloadsPerAsset:
+		r, err := g.fetchRelease(repository, tag)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		dim, val := formatDownloadsPerAsset(r, limit)
+		return dim, val, nil
+	default:
+		return nil, nil, errors.Errorf("can't count the downloads per %s: it should be %s or %s", per, githubDownloadsPerRelease, githubDownloadsPerAsset)
+	}
+}
+
+func formatDownloadsPerRelease(rs []*github.RepositoryRelease, limit int) (dim []string, val []int) {
+	if limit > len(rs) {
+		limit = len(rs)
+	}
+
+	// The releases are sorted from the newest to the oldest.
+	for i := limit - 1; i >= 0; i-- {
+		dim = append(dim, rs[i].GetTagName())
+		val = append(val, releaseDownloads(rs[i]))
+	}
+
+	return dim, val
+}
+
+func formatDownloadsPerAsset(r *github.RepositoryRelease, limit int) (dim []string, val []int) {
+	for k, v := range r.Assets {
+		if k < limit {
+			dim = append(dim, v.GetName())
+			val = append(val, v.GetDownloadCount())
+		}
+	}
+
+	return dim, val
+}
+
+func releaseDownloads(r *github.RepositoryRelease) int {
+	downloads := 0
+	for _, a := range r.Assets {
+		downloads += a.GetDownloadCount()
+	}
+
+	return downloads
+}
+
+// reviewRequest is a pull request waiting for a review.
+type reviewRequest struct {
+	title     string
+	repo      string
+	number    int
+	createdAt time.Time
+	ci        string
+	review    string
+	mergeable string
+}
+
+// ListReviewRequests lists the open pull requests where the user or the team is a requested reviewer.
+// The authenticated user is used if both the user and the team are empty.
+// The team should be like "org/team-slug".
+func (g *Github) ListReviewRequests(repository string, user string, team string, limit int) ([][]string, error) {
+	rrs, err := g.fetchReviewRequests(repository, user, team, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return formatReviewRequests(rrs, limit, time.Now()), nil
+}
+
+func formatReviewRequests(rrs []reviewRequest, limit int, now time.Time) [][]string {
+	if limit > len(rrs) {
+		limit = len(rrs)
+	}
+
+	table := make([][]string, limit+1)
+	table[0] = []string{"title", "repository", "age", "ci", "review", "mergeable"}
+	for k, v := range rrs {
+		if k < limit {
+			table[k+1] = append(
+				table[k+1],
+				v.title,
+				v.repo+"#"+strconv.Itoa(v.number),
+				formatAge(now.Sub(v.createdAt)),
+				v.ci,
+				v.review,
+				v.mergeable,
+			)
+		}
+	}
+
+	return table
+}
+
+// formatAge like "3d" or "5h".
+func formatAge(d time.Duration) string {
+	switch {
+	case d >= 24*time.Hour:
+		return strconv.Itoa(int(d.Hours()/24)) + "d"
+	case d >= time.Hour:
+		return strconv.Itoa(int(d.Hours())) + "h"
+	default:
+		return strconv.Itoa(int(d.Minutes())) + "m"
+	}
+}
+
+// reviewQuery to search the pull requests waiting for a review.
+func reviewQuery(owner string, repo string, user string, team string) string {
+	q := []string{"is:pr", "is:open", "archived:false"}
+
+	switch {
+	case team != "":
+		q = append(q, "team-review-requested:"+team)
+	case user != "":
+		q = append(q, "review-requested:"+user)
+	default:
+		q = append(q, "review-requested:@me")
+	}
+
+	switch {
+	case owner != "" && repo != "":
+		q = append(q, "repo:"+owner+"/"+repo)
+	case owner != "":
+		q = append(q, "user:"+owner)
+	}
+
+	return strings.Join(q, " ")
+}
+
+// reviewState of a pull request, from the last review of each reviewer.
+func reviewState(reviews []*github.PullRequestReview) string {
+	last := map[string]string{}
+	commented := false
+	for _, v := range reviews {
+		switch v.GetState() {
+		case "APPROVED", "CHANGES_REQUESTED", "DISMISSED":
+			last[v.GetUser().GetLogin()] = v.GetState()
+		case "COMMENTED":
+			commented = true
+		}
+	}
+
+	approved := false
+	for _, v := range last {
+		if v == "CHANGES_REQUESTED" {
+			return "changes requested"
+		}
+		if v == "APPROVED" {
+			approved = true
+		}
+	}
+
+	switch {
+	case approved:
+		return "approved"
+	case commented:
+		return "commented"
+	default:
+		return "pending"
+	}
+}
+
+// ciState of a commit, from its statuses and its check runs.
+func ciState(status *github.CombinedStatus, checks *github.ListCheckRunsResults) string {
+	states := []string{}
+	if status != nil && status.GetTotalCount() > 0 {
+		states = append(states, status.GetState())
+	}
+
+	if checks != nil {
+		for _, v := range checks.CheckRuns {
+			if v.GetStatus() != "completed" {
+				states = append(states, "pending")
+				continue
+			}
+
+			switch v.GetConclusion() {
+			case "success", "neutral", "skipped":
+				states = append(states, "success")
+			default:
+				states = append(states, "failure")
+			}
+		}
+	}
+
+	if len(states) == 0 {
+		return "none"
+	}
+
+	ci := "success"
+	for _, v := range states {
+		if v == "failure" || v == "error" {
+			return "failure"
+		}
+		if v == "pending" {
+			ci = "pending"
+		}
+	}
+
+	return ci
+}
+
 // Views on a github repository the last 7 days.
 func (g *Github) Views(repository string, days int) ([]string, []int, error) {
 	tv, err := g.fetchViews(repository)
@@ -300,6 +911,28 @@ func (g *Github) CountCommits(
 	endWeek int64,
 	startDate time.Time,
 ) ([]string, []int, error) {
+	if g.graphQL {
+		author := ""
+		if scope != githubScopeAll {
+			var err error
+			author, err = g.fetchOwnerUserIDGraphQL()
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+
+		// The commits of an organization are counted with the REST API.
+		if scope == githubScopeAll || author != "" {
+			cm, err := g.fetchCommitCountGraphQL(repository, author, startWeek, startDate)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			d, co := formatCountCommits(cm, startWeek, endWeek, startDate)
+			return d, co, nil
+		}
+	}
+
 	c, err := g.fetchCommitCount(repository)
 	if err != nil {
 		return nil, nil, err
@@ -364,7 +997,12 @@ func formatCountCommits(
 // CountStars of a repository overtime.
 // Only on a daily basis for now.
 func (g *Github) CountStars(repository string, startDate, endDate time.Time) (dim []string, val []int, err error) {
-	se, err := g.fetchStars(repository)
+	var se []*github.Stargazer
+	if g.graphQL {
+		se, err = g.fetchStarsGraphQL(repository, startDate)
+	} else {
+		se, err = g.fetchStars(repository)
+	}
 	if err != nil {
 		return nil, nil, err
 	}
@@ -472,6 +1110,20 @@ func (g *Github) fetchStars(repository string) (s []*github.Stargazer, err error
 	return s, nil
 }
 
+// repository given, or the repository of the service.
+func (g *Github) repository(repository string) (string, error) {
+	repo := g.repoName
+	if repository != "" {
+		repo = repository
+	}
+
+	if repo == "" {
+		return "", errors.New("you need to specify a repository in the github service or in the widget")
+	}
+
+	return repo, nil
+}
+
 func (g *Github) fetchRepo(repository string) (*github.Repository, error) {
 	// TODO add a TTL
 	if g.repo != nil {
@@ -554,6 +1206,10 @@ func (g *Github) fetchBranches(repository string, limit int) ([]*github.Branch,
 
 // Possibility to add options to filter quite a lot
 func (g *Github) fetchIssues(repository string, limit int) ([]*github.Issue, error) {
+	if g.graphQL {
+		return g.fetchIssuesGraphQL(repository, limit)
+	}
+
 	repo := g.repoName
 	if repository != "" {
 		repo = repository
@@ -577,6 +1233,10 @@ func (g *Github) fetchIssues(repository string, limit int) ([]*github.Issue, err
 
 // TODO add sorting
 func (g *Github) fetchPullRequests(repository string, limit int) ([]*github.PullRequest, error) {
+	if g.graphQL {
+		return g.fetchPullRequestsGraphQL(repository, limit)
+	}
+
 	ctx := context.Background()
 	repo := g.repoName
 	if repository != "" {
@@ -594,6 +1254,205 @@ func (g *Github) fetchPullRequests(repository string, limit int) ([]*github.Pull
 	return prs, nil
 }
 
+// fetchWorkflowRuns from the newest to the oldest.
+// If the workflow is a name, the runs are filtered here: the pages are fetched till enough runs are found.
+func (g *Github) fetchWorkflowRuns(repository string, filter GithubRunFilter, limit int) ([]*githubWorkflowRun, error) {
+	repo, err := g.repository(repository)
+	if err != nil {
+		return nil, err
+	}
+
+	byName := filter.Workflow != "" && !isWorkflowFile(filter.Workflow)
+
+	u := fmt.Sprintf("repos/%s/%s/actions/runs", g.owner, repo)
+	if filter.Workflow != "" && !byName {
+		u = fmt.Sprintf("repos/%s/%s/actions/workflows/%s/runs", g.owner, repo, url.PathEscape(filter.Workflow))
+	}
+
+	perPage := limit
+	if byName || perPage > githubMaxPerPage {
+		perPage = githubMaxPerPage
+	}
+
+	params := url.Values{"per_page": {strconv.Itoa(perPage)}}
+	if filter.Branch != "" {
+		params.Set("branch", filter.Branch)
+	}
+	if filter.Event != "" {
+		params.Set("event", filter.Event)
+	}
+
+	rs := []*githubWorkflowRun{}
+	for page := 1; page <= githubMaxPages; page++ {
+		if byName {
+			params.Set("page", strconv.Itoa(page))
+		}
+
+		req, err := g.client.NewRequest("GET", u+"?"+params.Encode(), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		runs := githubWorkflowRuns{}
+		if _, err := g.client.Do(context.Background(), req, &runs); err != nil {
+			return nil, errors.Wrapf(err, "can't find workflow runs of owner %s for repo %s", g.owner, repo)
+		}
+
+		if !byName {
+			return runs.WorkflowRuns, nil
+		}
+
+		for _, v := range runs.WorkflowRuns {
+			if strings.EqualFold(v.Name, filter.Workflow) {
+				rs = append(rs, v)
+			}
+		}
+
+		if len(rs) >= limit || len(runs.WorkflowRuns) < perPage {
+			break
+		}
+	}
+
+	if limit < len(rs) {
+		rs = rs[:limit]
+	}
+
+	return rs, nil
+}
+
+// isWorkflowFile returns true if the workflow is a file name or an ID, which can be used with the API.
+func isWorkflowFile(workflow string) bool {
+	if _, err := strconv.ParseInt(workflow, 10, 64); err == nil {
+		return true
+	}
+
+	return strings.HasSuffix(workflow, ".yml") || strings.HasSuffix(workflow, ".yaml")
+}
+
+func (g *Github) fetchReleases(repository string, limit int) ([]*github.RepositoryRelease, error) {
+	repo, err := g.repository(repository)
+	if err != nil {
+		return nil, err
+	}
+
+	opt := github.ListOptions{PerPage: limit}
+	rs, _, err := g.client.Repositories.ListReleases(context.Background(), g.owner, repo, &opt)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't find releases of owner %s for repo %s", g.owner, repo)
+	}
+
+	return rs, nil
+}
+
+// fetchRelease with the tag given, or the latest release if empty.
+func (g *Github) fetchRelease(repository string, tag string) (*github.RepositoryRelease, error) {
+	repo, err := g.repository(repository)
+	if err != nil {
+		return nil, err
+	}
+
+	var r *github.RepositoryRelease
+	if tag == "" {
+		r, _, err = g.client.Repositories.GetLatestRelease(context.Background(), g.owner, repo)
+	} else {
+		r, _, err = g.client.Repositories.GetReleaseByTag(context.Background(), g.owner, repo, tag)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't find release %s of owner %s for repo %s", tag, g.owner, repo)
+	}
+
+	return r, nil
+}
+
+// fetchReviewRequests from the oldest to the newest, with the state of their CI, of their reviews and their mergeability.
+func (g *Github) fetchReviewRequests(repository string, user string, team string, limit int) ([]reviewRequest, error) {
+	ctx := context.Background()
+	repo := g.repoName
+	if repository != "" {
+		repo = repository
+	}
+
+	res, _, err := g.client.Search.Issues(ctx, reviewQuery(g.owner, repo, user, team), &github.SearchOptions{
+		Sort:        "created",
+		Order:       "asc",
+		ListOptions: github.ListOptions{PerPage: limit},
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't find review requests of owner %s", g.owner)
+	}
+
+	is := res.Issues
+	if limit < len(is) {
+		is = is[:limit]
+	}
+
+	rrs := make([]reviewRequest, len(is))
+
+	// A pull request which can't be fetched doesn't hide the others: its row shows the error.
+	forEachIndex(len(is), func(k int) error {
+		rr, err := g.fetchReviewRequest(ctx, is[k])
+		if err != nil {
+			rr.ci, rr.review, rr.mergeable = errors.Cause(err).Error(), "", ""
+		}
+
+		rrs[k] = rr
+		return nil
+	})
+
+	return rrs, nil
+}
+
+func (g *Github) fetchReviewRequest(ctx context.Context, i github.Issue) (reviewRequest, error) {
+	rr := reviewRequest{
+		title:     i.GetTitle(),
+		number:    i.GetNumber(),
+		createdAt: i.GetCreatedAt(),
+		mergeable: "unknown",
+	}
+
+	// The repository URL is like https://api.github.com/repos/owner/repo.
+	p := strings.Split(i.GetRepositoryURL(), "/")
+	if len(p) < 2 {
+		return rr, errors.Errorf("can't find the repository of the pull request %s", i.GetTitle())
+	}
+	owner, repo := p[len(p)-2], p[len(p)-1]
+	rr.repo = repo
+
+	pr, _, err := g.client.PullRequests.Get(ctx, owner, repo, rr.number)
+	if err != nil {
+		return rr, errors.Wrapf(err, "can't find pull request %d of repo %s", rr.number, repo)
+	}
+
+	if pr.Mergeable != nil {
+		rr.mergeable = strconv.FormatBool(pr.GetMergeable())
+		if pr.GetMergeableState() != "" {
+			rr.mergeable = pr.GetMergeableState()
+		}
+	}
+
+	reviews, _, err := g.client.PullRequests.ListReviews(ctx, owner, repo, rr.number, &github.ListOptions{PerPage: githubMaxPerPage})
+	if err != nil {
+		return rr, errors.Wrapf(err, "can't find reviews of pull request %d of repo %s", rr.number, repo)
+	}
+	rr.review = reviewState(reviews)
+
+	sha := pr.GetHead().GetSHA()
+	status, _, err := g.client.Repositories.GetCombinedStatus(ctx, owner, repo, sha, nil)
+	if err != nil {
+		return rr, errors.Wrapf(err, "can't find status of pull request %d of repo %s", rr.number, repo)
+	}
+
+	checks, _, err := g.client.Checks.ListCheckRunsForRef(ctx, owner, repo, sha, &github.ListCheckRunsOptions{
+		ListOptions: github.ListOptions{PerPage: githubMaxPerPage},
+	})
+	if err != nil {
+		return rr, errors.Wrapf(err, "can't find checks of pull request %d of repo %s", rr.number, repo)
+	}
+	rr.ci = ciState(status, checks)
+
+	return rr, nil
+}
+
 // TODO possibility to add filters / ordering
 func (g *Github) fetchAllRepo(order string) ([]*github.Repository, error) {
 	ctx := context.Background()
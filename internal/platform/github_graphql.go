@@ -0,0 +1,310 @@
+package platform
+
+// github_graphql fetches the data of the heaviest widgets with the GraphQL API of Github.
+// The data is converted into the go-github structures, to be formatted like the data from the REST API.
+// See https://docs.github.com/en/graphql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v28/github"
+	"github.com/pkg/errors"
+)
+
+const (
+	githubAPIRest    = "rest"
+	githubAPIGraphQL = "graphql"
+
+	githubGraphQLEndpoint = "graphql"
+)
+
+type graphQLRequest struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables,omitempty"`
+}
+
+type graphQLResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+type graphQLPageInfo struct {
+	HasNextPage bool   `json:"hasNextPage"`
+	EndCursor   string `json:"endCursor"`
+}
+
+// queryGraphQL sends the query and decodes its data in v.
+func (g *Github) queryGraphQL(query string, variables map[string]interface{}, v interface{}) error {
+	req, err := g.client.NewRequest("POST", g.graphQLURL, graphQLRequest{Query: query, Variables: variables})
+	if err != nil {
+		return err
+	}
+
+	resp := graphQLResponse{}
+	if _, err := g.client.Do(context.Background(), req, &resp); err != nil {
+		return err
+	}
+
+	if len(resp.Errors) > 0 {
+		msg := []string{}
+		for _, e := range resp.Errors {
+			msg = append(msg, e.Message)
+		}
+		return errors.Errorf("github GraphQL API returned: %s", strings.Join(msg, ", "))
+	}
+
+	return json.Unmarshal(resp.Data, v)
+}
+
+const graphQLStargazersQuery = `query($owner: String!, $repo: String!, $cursor: String) {
+  repository(owner: $owner, name: $repo) {
+    stargazers(first: 100, after: $cursor, orderBy: {field: STARRED_AT, direction: DESC}) {
+      edges { starredAt }
+      pageInfo { hasNextPage endCursor }
+    }
+  }
+}`
+
+// fetchStarsGraphQL starred since the date given.
+// The stargazers are fetched from the newest: the pages before the date are not fetched.
+func (g *Github) fetchStarsGraphQL(repository string, since time.Time) ([]*github.Stargazer, error) {
+	repo, err := g.repository(repository)
+	if err != nil {
+		return nil, err
+	}
+
+	s := []*github.Stargazer{}
+	variables := map[string]interface{}{"owner": g.owner, "repo": repo}
+	for {
+		data := struct {
+			Repository struct {
+				Stargazers struct {
+					Edges []struct {
+						StarredAt time.Time `json:"starredAt"`
+					} `json:"edges"`
+					PageInfo graphQLPageInfo `json:"pageInfo"`
+				} `json:"stargazers"`
+			} `json:"repository"`
+		}{}
+
+		if err := g.queryGraphQL(graphQLStargazersQuery, variables, &data); err != nil {
+			return nil, errors.Wrapf(err, "can't find stargazers of repo %s of owner %s", repo, g.owner)
+		}
+
+		sg := data.Repository.Stargazers
+		for _, v := range sg.Edges {
+			if v.StarredAt.Before(since) {
+				return s, nil
+			}
+			s = append(s, &github.Stargazer{StarredAt: &github.Timestamp{Time: v.StarredAt}})
+		}
+
+		if !sg.PageInfo.HasNextPage {
+			return s, nil
+		}
+		variables["cursor"] = sg.PageInfo.EndCursor
+	}
+}
+
+const graphQLIssuesQuery = `query($owner: String!, $repo: String!, $limit: Int!) {
+  repository(owner: $owner, name: $repo) {
+    issues(first: $limit, orderBy: {field: CREATED_AT, direction: DESC}) {
+      nodes { title state createdAt }
+    }
+  }
+}`
+
+func (g *Github) fetchIssuesGraphQL(repository string, limit int) ([]*github.Issue, error) {
+	repo, err := g.repository(repository)
+	if err != nil {
+		return nil, err
+	}
+
+	data := struct {
+		Repository struct {
+			Issues struct {
+				Nodes []struct {
+					Title     string    `json:"title"`
+					State     string    `json:"state"`
+					CreatedAt time.Time `json:"createdAt"`
+				} `json:"nodes"`
+			} `json:"issues"`
+		} `json:"repository"`
+	}{}
+
+	variables := map[string]interface{}{"owner": g.owner, "repo": repo, "limit": graphQLLimit(limit)}
+	if err := g.queryGraphQL(graphQLIssuesQuery, variables, &data); err != nil {
+		return nil, errors.Wrapf(err, "can't find issues of owner %s for repo %s", g.owner, repo)
+	}
+
+	is := []*github.Issue{}
+	for _, v := range data.Repository.Issues.Nodes {
+		createdAt := v.CreatedAt
+		is = append(is, &github.Issue{
+			Title:     github.String(v.Title),
+			State:     github.String(strings.ToLower(v.State)),
+			CreatedAt: &createdAt,
+		})
+	}
+
+	return is, nil
+}
+
+const graphQLPullRequestsQuery = `query($owner: String!, $repo: String!, $limit: Int!) {
+  repository(owner: $owner, name: $repo) {
+    pullRequests(first: $limit, orderBy: {field: CREATED_AT, direction: DESC}) {
+      nodes { title state createdAt merged commits { totalCount } }
+    }
+  }
+}`
+
+func (g *Github) fetchPullRequestsGraphQL(repository string, limit int) ([]*github.PullRequest, error) {
+	repo, err := g.repository(repository)
+	if err != nil {
+		return nil, err
+	}
+
+	data := struct {
+		Repository struct {
+			PullRequests struct {
+				Nodes []struct {
+					Title     string    `json:"title"`
+					State     string    `json:"state"`
+					CreatedAt time.Time `json:"createdAt"`
+					Merged    bool      `json:"merged"`
+					Commits   struct {
+						TotalCount int `json:"totalCount"`
+					} `json:"commits"`
+				} `json:"nodes"`
+			} `json:"pullRequests"`
+		} `json:"repository"`
+	}{}
+
+	variables := map[string]interface{}{"owner": g.owner, "repo": repo, "limit": graphQLLimit(limit)}
+	if err := g.queryGraphQL(graphQLPullRequestsQuery, variables, &data); err != nil {
+		return nil, errors.Wrapf(err, "can't find pull requests of owner %s for repo %s", g.owner, repo)
+	}
+
+	prs := []*github.PullRequest{}
+	for _, v := range data.Repository.PullRequests.Nodes {
+		// The REST API has no "merged" state: the merged pull requests are closed.
+		state := strings.ToLower(v.State)
+		if state == "merged" {
+			state = "closed"
+		}
+
+		createdAt := v.CreatedAt
+		prs = append(prs, &github.PullRequest{
+			Title:     github.String(v.Title),
+			State:     github.String(state),
+			CreatedAt: &createdAt,
+			Merged:    github.Bool(v.Merged),
+			Commits:   github.Int(v.Commits.TotalCount),
+		})
+	}
+
+	return prs, nil
+}
+
+// fetchCommitCountGraphQL of the last weeks of the default branch, like the participation of the REST API:
+// the weeks begin on Sunday, from the oldest to the current week.
+// If the ID of an author is given, only the commits of this author are counted.
+func (g *Github) fetchCommitCountGraphQL(repository string, author string, weeks int64, now time.Time) ([]int, error) {
+	repo, err := g.repository(repository)
+	if err != nil {
+		return nil, err
+	}
+
+	data := struct {
+		Repository struct {
+			DefaultBranchRef struct {
+				Target map[string]struct {
+					TotalCount int `json:"totalCount"`
+				} `json:"target"`
+			} `json:"defaultBranchRef"`
+		} `json:"repository"`
+	}{}
+
+	variables := map[string]interface{}{"owner": g.owner, "repo": repo}
+	if err := g.queryGraphQL(commitCountQuery(weeks, author, now), variables, &data); err != nil {
+		return nil, errors.Wrapf(err, "can't find commits of repo %s of owner %s", repo, g.owner)
+	}
+
+	counts := make([]int, weeks)
+	for k := range counts {
+		// The week 0 is the current week, the last of the counts.
+		counts[len(counts)-1-k] = data.Repository.DefaultBranchRef.Target[fmt.Sprintf("w%d", k)].TotalCount
+	}
+
+	return counts, nil
+}
+
+// fetchOwnerUserIDGraphQL returns the ID of the owner if it's a user.
+// The ID is empty if the owner is an organization: the commits can only be filtered by user.
+func (g *Github) fetchOwnerUserIDGraphQL() (string, error) {
+	data := struct {
+		RepositoryOwner struct {
+			Typename string `json:"__typename"`
+			ID       string `json:"id"`
+		} `json:"repositoryOwner"`
+	}{}
+
+	q := `query($owner: String!) { repositoryOwner(login: $owner) { __typename id } }`
+	if err := g.queryGraphQL(q, map[string]interface{}{"owner": g.owner}, &data); err != nil {
+		return "", errors.Wrapf(err, "can't find owner %s", g.owner)
+	}
+
+	if data.RepositoryOwner.Typename != "User" {
+		return "", nil
+	}
+
+	return data.RepositoryOwner.ID, nil
+}
+
+// commitCountQuery counts the commits of each week with an alias "w0" (current week), "w1" (last week)...
+func commitCountQuery(weeks int64, author string, now time.Time) string {
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	start = start.AddDate(0, 0, -int(start.Weekday()))
+
+	filter := ""
+	if author != "" {
+		filter = fmt.Sprintf(", author: {id: %q}", author)
+	}
+
+	var b strings.Builder
+	b.WriteString("query($owner: String!, $repo: String!) {\n")
+	b.WriteString("  repository(owner: $owner, name: $repo) {\n")
+	b.WriteString("    defaultBranchRef { target { ... on Commit {\n")
+	for k := 0; k < int(weeks); k++ {
+		since := start.AddDate(0, 0, -7*k)
+		until := since.AddDate(0, 0, 7)
+		fmt.Fprintf(
+			&b,
+			"      w%d: history(since: %q, until: %q%s) { totalCount }\n",
+			k,
+			since.Format(time.RFC3339),
+			until.Format(time.RFC3339),
+			filter,
+		)
+	}
+	b.WriteString("    } } }\n")
+	b.WriteString("  }\n")
+	b.WriteString("}")
+
+	return b.String()
+}
+
+// graphQLLimit of the nodes: the GraphQL API returns 100 nodes at most.
+func graphQLLimit(limit int) int {
+	if limit > githubMaxPerPage {
+		return githubMaxPerPage
+	}
+
+	return limit
+}
@@ -0,0 +1,214 @@
+package platform
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// graphQLServer answers the queries containing a key with its response.
+// The key can be followed by the cursor of the page, like "stargazers page2".
+func graphQLServer(t *testing.T, responses map[string]string) (*Github, *httptest.Server) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/graphql" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+
+		req := graphQLRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Error(err)
+		}
+
+		cursor, _ := req.Variables["cursor"].(string)
+		for k, v := range responses {
+			p := strings.SplitN(k, " page", 2)
+			if strings.Contains(req.Query, p[0]) && (len(p) == 1 && cursor == "" || len(p) == 2 && "page"+p[1] == cursor) {
+				w.Write([]byte(v))
+				return
+			}
+		}
+
+		w.Write([]byte(`{"errors": [{"message": "unexpected query"}]}`))
+	}))
+
+	g, err := NewGithubClient("token", "phantas0s", "devdash", "graphql", GithubEnterprise{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.client.BaseURL, _ = url.Parse(server.URL + "/")
+
+	return g, server
+}
+
+func Test_fetchStarsGraphQL(t *testing.T) {
+	g, server := graphQLServer(t, map[string]string{
+		"stargazers": `{"data": {"repository": {"stargazers": {
+			"edges": [{"starredAt": "2020-01-10T10:00:00Z"}, {"starredAt": "2020-01-09T10:00:00Z"}],
+			"pageInfo": {"hasNextPage": true, "endCursor": "page2"}
+		}}}}`,
+		"stargazers page2": `{"data": {"repository": {"stargazers": {
+			"edges": [{"starredAt": "2020-01-08T10:00:00Z"}, {"starredAt": "2020-01-01T10:00:00Z"}],
+			"pageInfo": {"hasNextPage": true, "endCursor": "page3"}
+		}}}}`,
+	})
+	defer server.Close()
+
+	s, err := g.fetchStarsGraphQL("", time.Date(2020, 01, 05, 00, 00, 00, 00, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dim, val := formatCountStars(s, "01-02", time.Date(2020, 01, 05, 00, 00, 00, 00, time.UTC), time.Date(2020, 01, 11, 00, 00, 00, 00, time.UTC), false)
+	expectedDim := []string{"01-08", "01-09", "01-10"}
+	expectedVal := []int{1, 1, 1}
+	if !reflect.DeepEqual(expectedDim, dim) {
+		t.Errorf("Expected %v, actual %v", expectedDim, dim)
+	}
+
+	if !reflect.DeepEqual(expectedVal, val) {
+		t.Errorf("Expected %v, actual %v", expectedVal, val)
+	}
+}
+
+func Test_fetchPullRequestsGraphQL(t *testing.T) {
+	g, server := graphQLServer(t, map[string]string{
+		"pullRequests": `{"data": {"repository": {"pullRequests": {"nodes": [
+			{"title": "super pull request", "state": "MERGED", "createdAt": "2018-10-19T21:12:25Z", "merged": true, "commits": {"totalCount": 3}}
+		]}}}}`,
+	})
+	defer server.Close()
+
+	prs, err := g.fetchPullRequestsGraphQL("", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := [][]string{
+		{"title", "state", "created at", "merged", "commits"},
+		{"super pull request", "closed", "2018-10-19 21:12:25 +0000 UTC", "true", "3"},
+	}
+
+	actual := formatListPullRequests(prs, 5)
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Expected %v, actual %v", expected, actual)
+	}
+}
+
+func Test_fetchCommitCountGraphQL(t *testing.T) {
+	g, server := graphQLServer(t, map[string]string{
+		"author: {id: \"owner-id\"}": `{"data": {"repository": {"defaultBranchRef": {"target": {
+			"w0": {"totalCount": 3}, "w1": {"totalCount": 0}, "w2": {"totalCount": 5}
+		}}}}}`,
+	})
+	defer server.Close()
+
+	actual, err := g.fetchCommitCountGraphQL("", "owner-id", 3, time.Date(2020, 01, 15, 10, 00, 00, 00, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []int{5, 0, 3}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Expected %v, actual %v", expected, actual)
+	}
+}
+
+func Test_CountCommitsOwnerGraphQL(t *testing.T) {
+	testCases := []struct {
+		name      string
+		expected  []int
+		ownerType string
+	}{
+		{
+			name:      "user",
+			expected:  []int{5, 0, 3},
+			ownerType: "User",
+		},
+		{
+			name:      "organization counted with the REST API",
+			expected:  []int{1, 2, 4},
+			ownerType: "Organization",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/repos/phantas0s/devdash/stats/participation" {
+					owner := make([]int, 52)
+					copy(owner[49:], []int{1, 2, 4})
+					json.NewEncoder(w).Encode(map[string][]int{"all": make([]int, 52), "owner": owner})
+					return
+				}
+
+				req := graphQLRequest{}
+				if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+					t.Error(err)
+				}
+
+				if strings.Contains(req.Query, "repositoryOwner") {
+					fmt.Fprintf(w, `{"data": {"repositoryOwner": {"__typename": %q, "id": "owner-id"}}}`, tc.ownerType)
+					return
+				}
+
+				if tc.ownerType != "User" || !strings.Contains(req.Query, `author: {id: "owner-id"}`) {
+					t.Errorf("Unexpected query %s", req.Query)
+				}
+				w.Write([]byte(`{"data": {"repository": {"defaultBranchRef": {"target": {
+					"w0": {"totalCount": 3}, "w1": {"totalCount": 0}, "w2": {"totalCount": 5}
+				}}}}}`))
+			}))
+			defer server.Close()
+
+			g, err := NewGithubClient("token", "phantas0s", "devdash", "graphql", GithubEnterprise{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			g.client.BaseURL, _ = url.Parse(server.URL + "/")
+
+			_, actual, err := g.CountCommits("", githubScopeOwner, 3, 0, time.Date(2020, 01, 15, 10, 00, 00, 00, time.UTC))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(tc.expected, actual) {
+				t.Errorf("Expected %v, actual %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_commitCountQuery(t *testing.T) {
+	q := commitCountQuery(2, "", time.Date(2020, 01, 15, 10, 00, 00, 00, time.UTC))
+
+	expected := []string{
+		`w0: history(since: "2020-01-12T00:00:00Z", until: "2020-01-19T00:00:00Z") { totalCount }`,
+		`w1: history(since: "2020-01-05T00:00:00Z", until: "2020-01-12T00:00:00Z") { totalCount }`,
+	}
+	for _, v := range expected {
+		if !strings.Contains(q, v) {
+			t.Errorf("Expected %v in %v", v, q)
+		}
+	}
+}
+
+func Test_GraphQLErrors(t *testing.T) {
+	g, server := graphQLServer(t, map[string]string{})
+	defer server.Close()
+
+	if _, err := g.fetchIssuesGraphQL("", 5); err == nil || !strings.Contains(err.Error(), "unexpected query") {
+		t.Errorf("Expected the error of the API, actual %v", err)
+	}
+}
+
+func Test_NewGithubClientAPI(t *testing.T) {
+	if _, err := NewGithubClient("token", "phantas0s", "devdash", "soap", GithubEnterprise{}); err == nil {
+		t.Error("Expected an error for an unknown API")
+	}
+}
@@ -0,0 +1,350 @@
+package platform
+
+// github_org aggregates the data of every repository of an owner (organization or user), or of a list of repositories.
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v28/github"
+	"github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// githubMaxWorkers fetching the repositories at the same time, to stay under the rate limits.
+	githubMaxWorkers = 4
+	// githubMaxPages limits the number of requests for one list.
+	githubMaxPages = 10
+)
+
+// orgPullRequest is an open pull request of one of the repositories.
+type orgPullRequest struct {
+	repo string
+	pr   *github.PullRequest
+}
+
+// OrgOpenIssues counts the open issues of the repositories, without the pull requests.
+// Every repository of the owner is used if repos is empty.
+func (g *Github) OrgOpenIssues(owner string, repos []string) (int, error) {
+	rs, err := g.fetchOrgRepos(owner, repos)
+	if err != nil {
+		return 0, err
+	}
+
+	prs, err := g.fetchOrgPullRequests(owner, rs)
+	if err != nil {
+		return 0, err
+	}
+
+	// The open issues of a repository include its pull requests.
+	count := 0
+	for _, v := range rs {
+		count += v.GetOpenIssuesCount()
+	}
+
+	return count - len(prs), nil
+}
+
+// OrgPullRequests counts the open pull requests per repository, from the repository with the most of them.
+func (g *Github) OrgPullRequests(owner string, repos []string, limit int) ([]string, []int, error) {
+	rs, err := g.fetchOrgRepos(owner, repos)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	prs, err := g.fetchOrgPullRequests(owner, rs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dim, val := formatOrgPullRequests(prs, limit)
+
+	return dim, val, nil
+}
+
+func formatOrgPullRequests(prs []orgPullRequest, limit int) (dim []string, val []int) {
+	counts := map[string]int{}
+	for _, v := range prs {
+		if _, ok := counts[v.repo]; !ok {
+			dim = append(dim, v.repo)
+		}
+		counts[v.repo]++
+	}
+
+	sort.SliceStable(dim, func(i, j int) bool {
+		if counts[dim[i]] == counts[dim[j]] {
+			return dim[i] < dim[j]
+		}
+		return counts[dim[i]] > counts[dim[j]]
+	})
+
+	if limit < len(dim) {
+		dim = dim[:limit]
+	}
+
+	for _, v := range dim {
+		val = append(val, counts[v])
+	}
+
+	return dim, val
+}
+
+// OrgStalePullRequests lists the open pull requests without update since the date given, from the oldest update.
+func (g *Github) OrgStalePullRequests(owner string, repos []string, since time.Time, limit int) ([][]string, error) {
+	rs, err := g.fetchOrgRepos(owner, repos)
+	if err != nil {
+		return nil, err
+	}
+
+	prs, err := g.fetchOrgPullRequests(owner, rs)
+	if err != nil {
+		return nil, err
+	}
+
+	return formatOrgStalePullRequests(prs, since, limit, time.Now()), nil
+}
+
+func formatOrgStalePullRequests(prs []orgPullRequest, since time.Time, limit int, now time.Time) [][]string {
+	stale := []orgPullRequest{}
+	for _, v := range prs {
+		if v.pr.GetUpdatedAt().Before(since) {
+			stale = append(stale, v)
+		}
+	}
+
+	sort.SliceStable(stale, func(i, j int) bool {
+		return stale[i].pr.GetUpdatedAt().Before(stale[j].pr.GetUpdatedAt())
+	})
+
+	if limit > len(stale) {
+		limit = len(stale)
+	}
+
+	table := make([][]string, limit+1)
+	table[0] = []string{"repository", "title", "author", "last update"}
+	for k, v := range stale {
+		if k < limit {
+			table[k+1] = append(
+				table[k+1],
+				v.repo+"#"+strconv.Itoa(v.pr.GetNumber()),
+				v.pr.GetTitle(),
+				v.pr.GetUser().GetLogin(),
+				formatAge(now.Sub(v.pr.GetUpdatedAt())),
+			)
+		}
+	}
+
+	return table
+}
+
+// OrgCountCommits of the repositories per week, added together.
+func (g *Github) OrgCountCommits(
+	owner string,
+	repos []string,
+	scope string,
+	startWeek int64,
+	endWeek int64,
+	startDate time.Time,
+) ([]string, []int, error) {
+	rs, err := g.fetchOrgRepos(owner, repos)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var lock sync.Mutex
+	// The participation covers the last 52 weeks.
+	counts := make([]int, 52)
+	err = forEachRepo(rs, func(r *github.Repository) error {
+		p, _, err := g.client.Repositories.ListParticipation(context.Background(), r.GetOwner().GetLogin(), r.GetName())
+		if err != nil {
+			return errors.Wrapf(err, "can't find repo %s of owner %s", r.GetName(), owner)
+		}
+
+		c := p.Owner
+		if scope == githubScopeAll {
+			c = p.All
+		}
+
+		lock.Lock()
+		defer lock.Unlock()
+		addCounts(counts, c)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	d, co := formatCountCommits(counts, startWeek, endWeek, startDate)
+
+	return d, co, nil
+}
+
+// addCounts of c to counts, aligned on the last week.
+func addCounts(counts []int, c []int) {
+	offset := len(counts) - len(c)
+	for k, v := range c {
+		if k+offset >= 0 {
+			counts[k+offset] += v
+		}
+	}
+}
+
+// fetchOrgRepos given, or every repository of the owner if repos is empty.
+// The archived repositories of the owner are ignored.
+func (g *Github) fetchOrgRepos(owner string, repos []string) ([]*github.Repository, error) {
+	if owner == "" {
+		owner = g.owner
+	}
+
+	if owner == "" {
+		return nil, errors.New("you need to specify an owner in the github service or in the widget")
+	}
+
+	if len(repos) > 0 {
+		return g.fetchReposByName(owner, repos)
+	}
+
+	rs, err := g.fetchOwnerRepos(owner)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []*github.Repository{}
+	for _, v := range rs {
+		if !v.GetArchived() {
+			result = append(result, v)
+		}
+	}
+
+	return result, nil
+}
+
+func (g *Github) fetchReposByName(owner string, repos []string) ([]*github.Repository, error) {
+	rs := make([]*github.Repository, len(repos))
+
+	err := forEachIndex(len(repos), func(k int) error {
+		r, _, err := g.client.Repositories.Get(context.Background(), owner, repos[k])
+		if err != nil {
+			return errors.Wrapf(err, "can't find repo %s of owner %s", repos[k], owner)
+		}
+
+		rs[k] = r
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return rs, nil
+}
+
+// fetchOwnerRepos of an organization, or of a user if the owner is not an organization.
+func (g *Github) fetchOwnerRepos(owner string) ([]*github.Repository, error) {
+	ctx := context.Background()
+
+	rs := []*github.Repository{}
+	opt := &github.RepositoryListByOrgOptions{ListOptions: github.ListOptions{PerPage: githubMaxPerPage}}
+	for page := 1; page <= githubMaxPages; page++ {
+		opt.Page = page
+		r, resp, err := g.client.Repositories.ListByOrg(ctx, owner, opt)
+		if resp != nil && resp.StatusCode == http.StatusNotFound && page == 1 {
+			return g.fetchUserRepos(owner)
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "can't find all repo of owner %s", owner)
+		}
+
+		rs = append(rs, r...)
+		if resp.NextPage == 0 {
+			break
+		}
+	}
+
+	return rs, nil
+}
+
+func (g *Github) fetchUserRepos(owner string) ([]*github.Repository, error) {
+	ctx := context.Background()
+
+	rs := []*github.Repository{}
+	opt := &github.RepositoryListOptions{ListOptions: github.ListOptions{PerPage: githubMaxPerPage}}
+	for page := 1; page <= githubMaxPages; page++ {
+		opt.Page = page
+		r, resp, err := g.client.Repositories.List(ctx, owner, opt)
+		if err != nil {
+			return nil, errors.Wrapf(err, "can't find all repo of owner %s", owner)
+		}
+
+		rs = append(rs, r...)
+		if resp.NextPage == 0 {
+			break
+		}
+	}
+
+	return rs, nil
+}
+
+// fetchOrgPullRequests open in the repositories.
+func (g *Github) fetchOrgPullRequests(owner string, rs []*github.Repository) ([]orgPullRequest, error) {
+	var lock sync.Mutex
+	prs := []orgPullRequest{}
+	err := forEachRepo(rs, func(r *github.Repository) error {
+		opt := &github.PullRequestListOptions{
+			State:       "open",
+			ListOptions: github.ListOptions{PerPage: githubMaxPerPage},
+		}
+
+		for page := 1; page <= githubMaxPages; page++ {
+			opt.Page = page
+			p, resp, err := g.client.PullRequests.List(context.Background(), r.GetOwner().GetLogin(), r.GetName(), opt)
+			if err != nil {
+				return errors.Wrapf(err, "can't find pull requests of owner %s for repo %s", owner, r.GetName())
+			}
+
+			lock.Lock()
+			for _, v := range p {
+				prs = append(prs, orgPullRequest{repo: r.GetName(), pr: v})
+			}
+			lock.Unlock()
+
+			if resp.NextPage == 0 {
+				break
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return prs, nil
+}
+
+// forEachRepo calls f for every repository, with a bounded number of workers.
+func forEachRepo(rs []*github.Repository, f func(r *github.Repository) error) error {
+	return forEachIndex(len(rs), func(k int) error {
+		return f(rs[k])
+	})
+}
+
+// forEachIndex calls f for every index from 0 to n, with a bounded number of workers.
+func forEachIndex(n int, f func(k int) error) error {
+	var eg errgroup.Group
+	sem := make(chan bool, githubMaxWorkers)
+	for k := 0; k < n; k++ {
+		sem <- true
+		k := k
+		eg.Go(func() error {
+			defer func() { <-sem }()
+			return f(k)
+		})
+	}
+
+	return eg.Wait()
+}
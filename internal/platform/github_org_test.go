@@ -0,0 +1,185 @@
+package platform
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v28/github"
+)
+
+func orgPullRequestsFixture(t *testing.T) []orgPullRequest {
+	ps := []*github.PullRequest{}
+	fixtures := ReadFixtureFile("./testdata/fixtures/github_org_pulls.json", t)
+	if err := json.Unmarshal(fixtures, &ps); err != nil {
+		t.Fatal(err)
+	}
+
+	return []orgPullRequest{
+		{repo: "devdash", pr: ps[0]},
+		{repo: "termui", pr: ps[0]},
+		{repo: "devdash", pr: ps[1]},
+	}
+}
+
+func Test_formatOrgPullRequests(t *testing.T) {
+	testCases := []struct {
+		name        string
+		expectedDim []string
+		expectedVal []int
+		limit       int
+	}{
+		{
+			name:        "from the repository with the most pull requests",
+			expectedDim: []string{"devdash", "termui"},
+			expectedVal: []int{2, 1},
+			limit:       5,
+		},
+		{
+			name:        "limit",
+			expectedDim: []string{"devdash"},
+			expectedVal: []int{2},
+			limit:       1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dim, val := formatOrgPullRequests(orgPullRequestsFixture(t), tc.limit)
+			if !reflect.DeepEqual(tc.expectedDim, dim) {
+				t.Errorf("Expected %v, actual %v", tc.expectedDim, dim)
+			}
+
+			if !reflect.DeepEqual(tc.expectedVal, val) {
+				t.Errorf("Expected %v, actual %v", tc.expectedVal, val)
+			}
+		})
+	}
+}
+
+func Test_formatOrgStalePullRequests(t *testing.T) {
+	now := time.Date(2020, 01, 11, 10, 00, 00, 00, time.UTC)
+	testCases := []struct {
+		name     string
+		expected [][]string
+		since    time.Time
+	}{
+		{
+			name: "from the oldest update",
+			expected: [][]string{
+				{"repository", "title", "author", "last update"},
+				{"devdash#1", "Fix the documentation", "bob", "41d"},
+				{"devdash#2", "Add an org widget", "alice", "1d"},
+				{"termui#2", "Add an org widget", "alice", "1d"},
+			},
+			since: now,
+		},
+		{
+			name: "stale only",
+			expected: [][]string{
+				{"repository", "title", "author", "last update"},
+				{"devdash#1", "Fix the documentation", "bob", "41d"},
+			},
+			since: now.AddDate(0, 0, -14),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := formatOrgStalePullRequests(orgPullRequestsFixture(t), tc.since, 5, now)
+			if !reflect.DeepEqual(tc.expected, actual) {
+				t.Errorf("Expected %v, actual %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_addCounts(t *testing.T) {
+	counts := []int{1, 1, 1, 1}
+	addCounts(counts, []int{2, 3})
+
+	expected := []int{1, 1, 3, 4}
+	if !reflect.DeepEqual(expected, counts) {
+		t.Errorf("Expected %v, actual %v", expected, counts)
+	}
+}
+
+func Test_OrgOpenIssues(t *testing.T) {
+	testCases := []struct {
+		name     string
+		expected int
+		org      bool
+		repos    []string
+	}{
+		{
+			name:     "every repository of an organization",
+			expected: 4,
+			org:      true,
+		},
+		{
+			name:     "every repository of a user",
+			expected: 4,
+		},
+		{
+			name:     "list of repositories",
+			expected: 3,
+			org:      true,
+			repos:    []string{"devdash"},
+		},
+	}
+
+	repos := ReadFixtureFile("./testdata/fixtures/github_org_repos.json", t)
+	pulls := ReadFixtureFile("./testdata/fixtures/github_org_pulls.json", t)
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/orgs/phantas0s/repos":
+					if !tc.org {
+						http.NotFound(w, r)
+						return
+					}
+					// The second page is empty.
+					if r.URL.Query().Get("page") == "1" {
+						w.Header().Set("Link", fmt.Sprintf(`<http://%s/orgs/phantas0s/repos?page=2>; rel="next"`, r.Host))
+						w.Write(repos)
+						return
+					}
+					w.Write([]byte("[]"))
+				case "/users/phantas0s/repos":
+					w.Write(repos)
+				case "/repos/phantas0s/devdash":
+					w.Write([]byte(`{"name": "devdash", "owner": {"login": "phantas0s"}, "open_issues_count": 5}`))
+				case "/repos/phantas0s/devdash/pulls":
+					w.Write(pulls)
+				case "/repos/phantas0s/termui/pulls":
+					w.Write([]byte("[]"))
+				default:
+					t.Errorf("Unexpected request %s", r.URL.Path)
+					http.NotFound(w, r)
+				}
+			}))
+			defer server.Close()
+
+			g, err := NewGithubClient("token", "phantas0s", "", "", GithubEnterprise{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			g.client.BaseURL, _ = url.Parse(server.URL + "/")
+
+			actual, err := g.OrgOpenIssues("", tc.repos)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tc.expected != actual {
+				t.Errorf("Expected %v, actual %v", tc.expected, actual)
+			}
+		})
+	}
+}
@@ -2,6 +2,14 @@ package platform
 
 import (
 	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 	"time"
@@ -181,6 +189,497 @@ func Test_FomatListPullRequest(t *testing.T) {
 	}
 }
 
+func Test_formatWorkflowRuns(t *testing.T) {
+	testCases := []struct {
+		name        string
+		expected    [][]string
+		fixtureFile string
+		limit       int
+	}{
+		{
+			name: "happy case",
+			expected: [][]string{
+				{"run", "workflow", "branch", "event", "status", "created at", "duration"},
+				{"#12", "CI", "feature", "pull_request", "in_progress", "2020-01-03 10:00", "-"},
+				{"#11", "CI", "master", "push", "failure", "2020-01-02 10:00", "2m30s"},
+			},
+			fixtureFile: "./testdata/fixtures/github_workflow_runs.json",
+			limit:       2,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			runs := githubWorkflowRuns{}
+			fixtures := ReadFixtureFile(tc.fixtureFile, t)
+			if err := json.Unmarshal(fixtures, &runs); err != nil {
+				t.Error(err)
+			}
+
+			actual := formatWorkflowRuns(runs.WorkflowRuns, tc.limit)
+			if !reflect.DeepEqual(tc.expected, actual) {
+				t.Errorf("Expected %v, actual %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_formatRunDurations(t *testing.T) {
+	testCases := []struct {
+		name        string
+		expectedDim []string
+		expectedVal []int
+		fixtureFile string
+		limit       int
+	}{
+		{
+			name:        "completed runs from the oldest",
+			expectedDim: []string{"#10", "#3", "#11"},
+			expectedVal: []int{240, 600, 150},
+			fixtureFile: "./testdata/fixtures/github_workflow_runs.json",
+			limit:       10,
+		},
+		{
+			name:        "limit",
+			expectedDim: []string{"#3", "#11"},
+			expectedVal: []int{600, 150},
+			fixtureFile: "./testdata/fixtures/github_workflow_runs.json",
+			limit:       2,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			runs := githubWorkflowRuns{}
+			fixtures := ReadFixtureFile(tc.fixtureFile, t)
+			if err := json.Unmarshal(fixtures, &runs); err != nil {
+				t.Error(err)
+			}
+
+			dim, val := formatRunDurations(runs.WorkflowRuns, tc.limit)
+			if !reflect.DeepEqual(tc.expectedDim, dim) {
+				t.Errorf("Expected %v, actual %v", tc.expectedDim, dim)
+			}
+
+			if !reflect.DeepEqual(tc.expectedVal, val) {
+				t.Errorf("Expected %v, actual %v", tc.expectedVal, val)
+			}
+		})
+	}
+}
+
+func Test_fetchWorkflowRuns(t *testing.T) {
+	testCases := []struct {
+		name          string
+		filter        GithubRunFilter
+		expectedPath  string
+		expectedQuery url.Values
+		expectedRuns  int
+	}{
+		{
+			name:          "every run",
+			expectedPath:  "/repos/phantas0s/devdash/actions/runs",
+			expectedQuery: url.Values{"per_page": {"5"}},
+			expectedRuns:  4,
+		},
+		{
+			name:          "workflow file, branch and event",
+			filter:        GithubRunFilter{Workflow: "ci.yml", Branch: "master", Event: "push"},
+			expectedPath:  "/repos/phantas0s/devdash/actions/workflows/ci.yml/runs",
+			expectedQuery: url.Values{"per_page": {"5"}, "branch": {"master"}, "event": {"push"}},
+			expectedRuns:  4,
+		},
+		{
+			name:          "workflow name",
+			filter:        GithubRunFilter{Workflow: "ci"},
+			expectedPath:  "/repos/phantas0s/devdash/actions/runs",
+			expectedQuery: url.Values{"per_page": {"100"}, "page": {"1"}},
+			expectedRuns:  3,
+		},
+	}
+
+	fixtures := ReadFixtureFile("./testdata/fixtures/github_workflow_runs.json", t)
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != tc.expectedPath {
+					t.Errorf("Expected %v, actual %v", tc.expectedPath, r.URL.Path)
+				}
+
+				if !reflect.DeepEqual(tc.expectedQuery, r.URL.Query()) {
+					t.Errorf("Expected %v, actual %v", tc.expectedQuery, r.URL.Query())
+				}
+
+				w.Write(fixtures)
+			}))
+			defer server.Close()
+
+			g, err := NewGithubClient("token", "phantas0s", "devdash", "", GithubEnterprise{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			g.client.BaseURL, _ = url.Parse(server.URL + "/")
+
+			rs, err := g.fetchWorkflowRuns("", tc.filter, 5)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(rs) != tc.expectedRuns {
+				t.Errorf("Expected %v, actual %v", tc.expectedRuns, len(rs))
+			}
+		})
+	}
+}
+
+func Test_fetchWorkflowRunsPages(t *testing.T) {
+	var pages []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("page")
+		pages = append(pages, page)
+
+		// The first page is full of runs of another workflow.
+		runs := githubWorkflowRuns{}
+		for i := 0; i < githubMaxPerPage; i++ {
+			name := "lint"
+			if page != "1" {
+				name = "ci"
+			}
+			runs.WorkflowRuns = append(runs.WorkflowRuns, &githubWorkflowRun{Name: name})
+		}
+
+		json.NewEncoder(w).Encode(runs)
+	}))
+	defer server.Close()
+
+	g, err := NewGithubClient("token", "phantas0s", "devdash", "", GithubEnterprise{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.client.BaseURL, _ = url.Parse(server.URL + "/")
+
+	rs, err := g.fetchWorkflowRuns("", GithubRunFilter{Workflow: "ci"}, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rs) != 5 {
+		t.Errorf("Expected %v, actual %v", 5, len(rs))
+	}
+
+	if expected := []string{"1", "2"}; !reflect.DeepEqual(expected, pages) {
+		t.Errorf("Expected %v, actual %v", expected, pages)
+	}
+}
+
+func Test_formatListReleases(t *testing.T) {
+	testCases := []struct {
+		name        string
+		expected    [][]string
+		fixtureFile string
+		limit       int
+	}{
+		{
+			name: "happy case",
+			expected: [][]string{
+				{"tag", "date", "assets", "downloads"},
+				{"v0.2.0", "2020-01-02", "2", "150"},
+				{"v0.1.0", "2019-06-01", "1", "42"},
+				{"v0.3.0", "draft", "0", "0"},
+			},
+			fixtureFile: "./testdata/fixtures/github_releases.json",
+			limit:       5,
+		},
+		{
+			name: "limit",
+			expected: [][]string{
+				{"tag", "date", "assets", "downloads"},
+				{"v0.2.0", "2020-01-02", "2", "150"},
+			},
+			fixtureFile: "./testdata/fixtures/github_releases.json",
+			limit:       1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rs := []*github.RepositoryRelease{}
+			fixtures := ReadFixtureFile(tc.fixtureFile, t)
+			if err := json.Unmarshal(fixtures, &rs); err != nil {
+				t.Error(err)
+			}
+
+			actual := formatListReleases(rs, tc.limit)
+			if !reflect.DeepEqual(tc.expected, actual) {
+				t.Errorf("Expected %v, actual %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_formatDownloads(t *testing.T) {
+	rs := []*github.RepositoryRelease{}
+	fixtures := ReadFixtureFile("./testdata/fixtures/github_releases.json", t)
+	if err := json.Unmarshal(fixtures, &rs); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("per release from the oldest", func(t *testing.T) {
+		dim, val := formatDownloadsPerRelease(rs, 2)
+		expectedDim := []string{"v0.1.0", "v0.2.0"}
+		expectedVal := []int{42, 150}
+		if !reflect.DeepEqual(expectedDim, dim) {
+			t.Errorf("Expected %v, actual %v", expectedDim, dim)
+		}
+
+		if !reflect.DeepEqual(expectedVal, val) {
+			t.Errorf("Expected %v, actual %v", expectedVal, val)
+		}
+	})
+
+	t.Run("per asset", func(t *testing.T) {
+		dim, val := formatDownloadsPerAsset(rs[0], 5)
+		expectedDim := []string{"devdash_linux_amd64.tar.gz", "devdash_darwin_amd64.tar.gz"}
+		expectedVal := []int{120, 30}
+		if !reflect.DeepEqual(expectedDim, dim) {
+			t.Errorf("Expected %v, actual %v", expectedDim, dim)
+		}
+
+		if !reflect.DeepEqual(expectedVal, val) {
+			t.Errorf("Expected %v, actual %v", expectedVal, val)
+		}
+	})
+}
+
+func Test_formatReviewRequests(t *testing.T) {
+	now := time.Date(2020, 01, 03, 12, 00, 00, 00, time.UTC)
+	rrs := []reviewRequest{
+		{
+			title:     "Add a review queue",
+			repo:      "devdash",
+			number:    42,
+			createdAt: time.Date(2020, 01, 01, 10, 00, 00, 00, time.UTC),
+			ci:        "success",
+			review:    "approved",
+			mergeable: "clean",
+		},
+		{
+			title:     "Fix the documentation",
+			repo:      "termui",
+			number:    7,
+			createdAt: time.Date(2020, 01, 03, 9, 30, 00, 00, time.UTC),
+			ci:        "pending",
+			review:    "pending",
+			mergeable: "unknown",
+		},
+	}
+
+	expected := [][]string{
+		{"title", "repository", "age", "ci", "review", "mergeable"},
+		{"Add a review queue", "devdash#42", "2d", "success", "approved", "clean"},
+		{"Fix the documentation", "termui#7", "2h", "pending", "pending", "unknown"},
+	}
+
+	actual := formatReviewRequests(rrs, 5, now)
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Expected %v, actual %v", expected, actual)
+	}
+}
+
+func Test_reviewQuery(t *testing.T) {
+	testCases := []struct {
+		name     string
+		expected string
+		owner    string
+		repo     string
+		user     string
+		team     string
+	}{
+		{
+			name:     "authenticated user",
+			expected: "is:pr is:open archived:false review-requested:@me",
+		},
+		{
+			name:     "user and repository",
+			expected: "is:pr is:open archived:false review-requested:alice repo:phantas0s/devdash",
+			owner:    "phantas0s",
+			repo:     "devdash",
+			user:     "alice",
+		},
+		{
+			name:     "team and owner",
+			expected: "is:pr is:open archived:false team-review-requested:org/core user:phantas0s",
+			owner:    "phantas0s",
+			user:     "alice",
+			team:     "org/core",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := reviewQuery(tc.owner, tc.repo, tc.user, tc.team)
+			if tc.expected != actual {
+				t.Errorf("Expected %v, actual %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_reviewState(t *testing.T) {
+	reviews := []*github.PullRequestReview{}
+	fixtures := ReadFixtureFile("./testdata/fixtures/github_reviews.json", t)
+	if err := json.Unmarshal(fixtures, &reviews); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name     string
+		expected string
+		reviews  []*github.PullRequestReview
+	}{
+		{
+			name:     "last review of each reviewer",
+			expected: "approved",
+			reviews:  reviews,
+		},
+		{
+			name:     "changes requested",
+			expected: "changes requested",
+			reviews:  reviews[:2],
+		},
+		{
+			name:     "commented",
+			expected: "commented",
+			reviews:  reviews[1:2],
+		},
+		{
+			name:     "no review",
+			expected: "pending",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := reviewState(tc.reviews)
+			if tc.expected != actual {
+				t.Errorf("Expected %v, actual %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_ciState(t *testing.T) {
+	testCases := []struct {
+		name     string
+		expected string
+		status   *github.CombinedStatus
+		checks   *github.ListCheckRunsResults
+	}{
+		{
+			name:     "nothing",
+			expected: "none",
+			status:   &github.CombinedStatus{State: github.String("pending"), TotalCount: github.Int(0)},
+			checks:   &github.ListCheckRunsResults{},
+		},
+		{
+			name:     "status and checks successful",
+			expected: "success",
+			status:   &github.CombinedStatus{State: github.String("success"), TotalCount: github.Int(1)},
+			checks: &github.ListCheckRunsResults{CheckRuns: []*github.CheckRun{
+				{Status: github.String("completed"), Conclusion: github.String("success")},
+				{Status: github.String("completed"), Conclusion: github.String("skipped")},
+			}},
+		},
+		{
+			name:     "check running",
+			expected: "pending",
+			checks: &github.ListCheckRunsResults{CheckRuns: []*github.CheckRun{
+				{Status: github.String("completed"), Conclusion: github.String("success")},
+				{Status: github.String("in_progress")},
+			}},
+		},
+		{
+			name:     "check failed",
+			expected: "failure",
+			status:   &github.CombinedStatus{State: github.String("pending"), TotalCount: github.Int(1)},
+			checks: &github.ListCheckRunsResults{CheckRuns: []*github.CheckRun{
+				{Status: github.String("completed"), Conclusion: github.String("timed_out")},
+			}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := ciState(tc.status, tc.checks)
+			if tc.expected != actual {
+				t.Errorf("Expected %v, actual %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_fetchReviewRequests(t *testing.T) {
+	responses := map[string]string{
+		"/search/issues":                                  string(ReadFixtureFile("./testdata/fixtures/github_review_requests.json", t)),
+		"/repos/phantas0s/devdash/pulls/42":               `{"number": 42, "mergeable": true, "mergeable_state": "clean", "head": {"sha": "abc"}}`,
+		"/repos/phantas0s/devdash/pulls/42/reviews":       string(ReadFixtureFile("./testdata/fixtures/github_reviews.json", t)),
+		"/repos/phantas0s/devdash/commits/abc/status":     `{"state": "success", "total_count": 1}`,
+		"/repos/phantas0s/devdash/commits/abc/check-runs": `{"total_count": 0, "check_runs": []}`,
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/search/issues" {
+			expected := "is:pr is:open archived:false review-requested:alice repo:phantas0s/devdash"
+			if r.URL.Query().Get("q") != expected {
+				t.Errorf("Expected %v, actual %v", expected, r.URL.Query().Get("q"))
+			}
+		}
+
+		res, ok := responses[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Write([]byte(res))
+	}))
+	defer server.Close()
+
+	g, err := NewGithubClient("token", "phantas0s", "devdash", "", GithubEnterprise{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.client.BaseURL, _ = url.Parse(server.URL + "/")
+
+	actual, err := g.fetchReviewRequests("", "alice", "", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []reviewRequest{
+		{
+			title:     "Add a review queue",
+			repo:      "devdash",
+			number:    42,
+			createdAt: time.Date(2020, 01, 01, 10, 00, 00, 00, time.UTC),
+			ci:        "success",
+			review:    "approved",
+			mergeable: "clean",
+		},
+		{
+			title:     "Remove the review queue",
+			repo:      "devdash",
+			number:    43,
+			createdAt: time.Date(2020, 01, 02, 10, 00, 00, 00, time.UTC),
+			ci:        "GET " + server.URL + "/repos/phantas0s/devdash/pulls/43: 404  []",
+		},
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Expected %v, actual %v", expected, actual)
+	}
+}
+
 func Test_fillMissingDays(t *testing.T) {
 	testCases := []struct {
 		name           string
@@ -257,3 +756,190 @@ func Test_fillMissingDays(t *testing.T) {
 		})
 	}
 }
+
+func Test_enterpriseURLs(t *testing.T) {
+	testCases := []struct {
+		name              string
+		enterprise        GithubEnterprise
+		expectedBaseURL   string
+		expectedGraphQL   string
+		expectedUploadURL string
+		wantErr           bool
+	}{
+		{
+			name:              "host only",
+			enterprise:        GithubEnterprise{BaseURL: "https://github.example.com"},
+			expectedBaseURL:   "https://github.example.com/api/v3/",
+			expectedGraphQL:   "https://github.example.com/api/graphql",
+			expectedUploadURL: "https://github.example.com/api/uploads/",
+		},
+		{
+			name:              "path of the API",
+			enterprise:        GithubEnterprise{BaseURL: "https://github.example.com/api/v3/"},
+			expectedBaseURL:   "https://github.example.com/api/v3/",
+			expectedGraphQL:   "https://github.example.com/api/graphql",
+			expectedUploadURL: "https://github.example.com/api/uploads/",
+		},
+		{
+			name: "upload URL",
+			enterprise: GithubEnterprise{
+				BaseURL:   "https://github.example.com/",
+				UploadURL: "https://uploads.example.com/",
+			},
+			expectedBaseURL:   "https://github.example.com/api/v3/",
+			expectedGraphQL:   "https://github.example.com/api/graphql",
+			expectedUploadURL: "https://uploads.example.com/",
+		},
+		{
+			name:       "invalid URL",
+			enterprise: GithubEnterprise{BaseURL: "github.example.com"},
+			wantErr:    true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			graphQL, upload, err := enterpriseURLs(&tc.enterprise)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Error '%v' even if wantErr is %t", err, tc.wantErr)
+				return
+			}
+
+			if tc.wantErr {
+				return
+			}
+
+			actual := []string{tc.enterprise.BaseURL, graphQL, upload}
+			expected := []string{tc.expectedBaseURL, tc.expectedGraphQL, tc.expectedUploadURL}
+			if !reflect.DeepEqual(expected, actual) {
+				t.Errorf("Expected %v, actual %v", expected, actual)
+			}
+		})
+	}
+}
+
+func Test_GithubEnterprise(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v3/repos/phantas0s/devdash" {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Write([]byte(`{"name": "devdash", "stargazers_count": 42}`))
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "devdash_ca")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	caCert := filepath.Join(dir, "ca.pem")
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := ioutil.WriteFile(caCert, cert, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name     string
+		caCert   string
+		expected int
+		wantErr  bool
+	}{
+		{
+			name:     "custom CA certificate",
+			caCert:   caCert,
+			expected: 42,
+		},
+		{
+			name:    "unknown certificate",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g, err := NewGithubClient("token", "phantas0s", "devdash", "", GithubEnterprise{
+				BaseURL: server.URL,
+				CACert:  tc.caCert,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			actual, err := g.TotalStars("")
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Error '%v' even if wantErr is %t", err, tc.wantErr)
+				return
+			}
+
+			if tc.wantErr == false && actual != tc.expected {
+				t.Errorf("Expected %v, actual %v", tc.expected, actual)
+			}
+		})
+	}
+
+	t.Run("no certificate in the file", func(t *testing.T) {
+		empty := filepath.Join(dir, "empty.pem")
+		if err := ioutil.WriteFile(empty, []byte("nothing"), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := NewGithubClient("token", "phantas0s", "devdash", "", GithubEnterprise{BaseURL: server.URL, CACert: empty}); err == nil {
+			t.Error("Expected an error without certificate")
+		}
+	})
+}
+
+func Test_GithubRateLimit(t *testing.T) {
+	reset := time.Now().Add(time.Hour).Unix()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rate_limit" {
+			http.NotFound(w, r)
+			return
+		}
+
+		fmt.Fprintf(w, `{"resources": {
+			"core": {"limit": 5000, "remaining": 4999, "reset": %[1]d},
+			"graphql": {"limit": 5000, "remaining": 4200, "reset": %[1]d}
+		}}`, reset)
+	}))
+	defer server.Close()
+
+	// The GraphQL API has never been requested.
+	g, err := NewGithubClient("rate limit token", "phantas0s", "devdash", "", GithubEnterprise{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.client.BaseURL, _ = url.Parse(server.URL + "/")
+
+	testCases := []struct {
+		name              string
+		resource          string
+		expectedRemaining int
+		wantErr           bool
+	}{
+		{name: "graphql not requested yet", resource: githubResourceGraphQL, expectedRemaining: 4200},
+		{name: "core", resource: githubResourceCore, expectedRemaining: 4999},
+		{name: "resource not returned", resource: githubResourceSearch, wantErr: true},
+		{name: "unknown resource", resource: "integration_manifest", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			remaining, limit, _, err := g.RateLimit(tc.resource)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Error '%v' even if wantErr is %t", err, tc.wantErr)
+			}
+
+			if tc.wantErr {
+				return
+			}
+
+			if remaining != tc.expectedRemaining || limit != 5000 {
+				t.Errorf("Expected %v/%v, actual %v/%v", tc.expectedRemaining, 5000, remaining, limit)
+			}
+		})
+	}
+}
@@ -0,0 +1,388 @@
+package platform
+
+// gitlab connects to the REST API v4 of gitlab.com or of a self-managed GitLab instance.
+// See https://docs.gitlab.com/ee/api/
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	gitlabDefaultURL = "https://gitlab.com"
+	gitlabMaxPerPage = 100
+	// gitlabMaxPages limits the number of requests to count commits.
+	gitlabMaxPages = 10
+)
+
+// Gitlab connects to the Gitlab API.
+type Gitlab struct {
+	client   *http.Client
+	baseURL  string
+	token    string
+	owner    string
+	repoName string
+}
+
+type gitlabProject struct {
+	StarCount int `json:"star_count"`
+}
+
+type gitlabUser struct {
+	Username string `json:"username"`
+}
+
+type gitlabIssue struct {
+	Title     string     `json:"title"`
+	State     string     `json:"state"`
+	CreatedAt *time.Time `json:"created_at"`
+}
+
+type gitlabMergeRequest struct {
+	Title     string      `json:"title"`
+	State     string      `json:"state"`
+	CreatedAt *time.Time  `json:"created_at"`
+	MergedAt  *time.Time  `json:"merged_at"`
+	Author    *gitlabUser `json:"author"`
+}
+
+type gitlabBranch struct {
+	Name   string `json:"name"`
+	Commit *struct {
+		AuthorName    string     `json:"author_name"`
+		CommittedDate *time.Time `json:"committed_date"`
+	} `json:"commit"`
+}
+
+type gitlabCommit struct {
+	CommittedDate time.Time `json:"committed_date"`
+}
+
+type gitlabPipeline struct {
+	ID        int        `json:"id"`
+	Status    string     `json:"status"`
+	Ref       string     `json:"ref"`
+	CreatedAt *time.Time `json:"created_at"`
+}
+
+// NewGitlabClient for the Gitlab instance at baseURL (gitlab.com if empty).
+func NewGitlabClient(baseURL string, token string, owner string, repoName string) (*Gitlab, error) {
+	if baseURL == "" {
+		baseURL = gitlabDefaultURL
+	}
+
+	return &Gitlab{
+		client:   cachedHTTPClient("gitlab "+token, &http.Client{}),
+		baseURL:  strings.TrimSuffix(baseURL, "/"),
+		token:    token,
+		owner:    owner,
+		repoName: repoName,
+	}, nil
+}
+
+// TotalStars of a project.
+func (g *Gitlab) TotalStars(repository string) (int, error) {
+	p := gitlabProject{}
+	if err := g.getProject(repository, "", nil, &p); err != nil {
+		return 0, err
+	}
+
+	return p.StarCount, nil
+}
+
+// ListIssues of a project.
+func (g *Gitlab) ListIssues(repository string, limit int) ([][]string, error) {
+	is := []gitlabIssue{}
+	params := url.Values{"state": {"all"}, "per_page": {strconv.Itoa(limit)}}
+	if err := g.getProject(repository, "issues", params, &is); err != nil {
+		return nil, err
+	}
+
+	return formatGitlabIssues(is, limit), nil
+}
+
+func formatGitlabIssues(is []gitlabIssue, limit int) [][]string {
+	if limit > len(is) {
+		limit = len(is)
+	}
+
+	issues := make([][]string, limit+1)
+	issues[0] = []string{"name", "state"}
+	for k, v := range is {
+		if k < limit {
+			issues[k+1] = append(issues[k+1], v.Title, v.State)
+		}
+	}
+
+	return issues
+}
+
+// ListMergeRequests of a project.
+func (g *Gitlab) ListMergeRequests(repository string, limit int) ([][]string, error) {
+	mrs := []gitlabMergeRequest{}
+	params := url.Values{"state": {"all"}, "per_page": {strconv.Itoa(limit)}}
+	if err := g.getProject(repository, "merge_requests", params, &mrs); err != nil {
+		return nil, err
+	}
+
+	return formatListMergeRequests(mrs, limit), nil
+}
+
+func formatListMergeRequests(mrs []gitlabMergeRequest, limit int) [][]string {
+	if limit > len(mrs) {
+		limit = len(mrs)
+	}
+
+	headers := []string{"title", "state", "created at", "merged", "author"}
+
+	defaultHeader := "unknown"
+	table := make([][]string, limit+1)
+	table[0] = headers
+	for k, v := range mrs {
+		n := defaultHeader
+		if v.Title != "" {
+			n = v.Title
+		}
+
+		state := defaultHeader
+		if v.State != "" {
+			state = v.State
+		}
+
+		createdAt := defaultHeader
+		if v.CreatedAt != nil {
+			createdAt = v.CreatedAt.String()
+		}
+
+		merged := strconv.FormatBool(v.MergedAt != nil)
+
+		author := defaultHeader
+		if v.Author != nil {
+			author = v.Author.Username
+		}
+
+		if k < limit {
+			table[k+1] = append(table[k+1], n, state, createdAt, merged, author)
+		}
+	}
+
+	return table
+}
+
+// ListBranches of a project.
+func (g *Gitlab) ListBranches(repository string, limit int) ([][]string, error) {
+	bs := []gitlabBranch{}
+	params := url.Values{"per_page": {strconv.Itoa(limit)}}
+	if err := g.getProject(repository, "repository/branches", params, &bs); err != nil {
+		return nil, err
+	}
+
+	return formatGitlabBranches(bs, limit), nil
+}
+
+func formatGitlabBranches(bs []gitlabBranch, limit int) [][]string {
+	if limit > len(bs) {
+		limit = len(bs)
+	}
+
+	defaultHeader := "unknown"
+	branches := make([][]string, limit+1)
+	branches[0] = []string{"name", "last commit by", "last commit at"}
+	for k, v := range bs {
+		author := defaultHeader
+		date := defaultHeader
+		if v.Commit != nil {
+			author = v.Commit.AuthorName
+			if v.Commit.CommittedDate != nil {
+				date = v.Commit.CommittedDate.Format("2006-01-02")
+			}
+		}
+
+		if k < limit {
+			branches[k+1] = append(branches[k+1], v.Name, author, date)
+		}
+	}
+
+	return branches
+}
+
+// CountCommits of a project per week, between startDate and endDate.
+func (g *Gitlab) CountCommits(repository string, startDate, endDate time.Time) ([]string, []int, error) {
+	commits := []gitlabCommit{}
+	for page := 1; ; page++ {
+		cs := []gitlabCommit{}
+		params := url.Values{
+			"since":    {startDate.Format(time.RFC3339)},
+			"until":    {endDate.Format(time.RFC3339)},
+			"per_page": {strconv.Itoa(gitlabMaxPerPage)},
+			"page":     {strconv.Itoa(page)},
+		}
+		if err := g.getProject(repository, "repository/commits", params, &cs); err != nil {
+			return nil, nil, err
+		}
+
+		// One more page is fetched to know if every commit has been counted.
+		if page > gitlabMaxPages {
+			if len(cs) > 0 {
+				return nil, nil, errors.Errorf(
+					"more than %d commits between %s and %s: reduce the time period",
+					len(commits),
+					startDate.Format("2006-01-02"),
+					endDate.Format("2006-01-02"),
+				)
+			}
+			break
+		}
+
+		commits = append(commits, cs...)
+		if len(cs) < gitlabMaxPerPage {
+			break
+		}
+	}
+
+	dim, val := formatGitlabCountCommits(commits, startDate, endDate)
+
+	return dim, val, nil
+}
+
+func formatGitlabCountCommits(commits []gitlabCommit, startDate, endDate time.Time) ([]string, []int) {
+	dates := make([]time.Time, 0, len(commits))
+	for _, c := range commits {
+		dates = append(dates, c.CommittedDate)
+	}
+
+	return countPerWeek(dates, startDate, endDate)
+}
+
+// PipelineStatus of the last pipeline of a project, for the ref given (every ref if empty).
+func (g *Gitlab) PipelineStatus(repository string, ref string) (string, error) {
+	ps, err := g.fetchPipelines(repository, ref, 1)
+	if err != nil {
+		return "", err
+	}
+
+	if len(ps) == 0 {
+		return "no pipeline", nil
+	}
+
+	return ps[0].Status, nil
+}
+
+// ListPipelines of a project, for the ref given (every ref if empty).
+func (g *Gitlab) ListPipelines(repository string, ref string, limit int) ([][]string, error) {
+	ps, err := g.fetchPipelines(repository, ref, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return formatGitlabPipelines(ps, limit), nil
+}
+
+func formatGitlabPipelines(ps []gitlabPipeline, limit int) [][]string {
+	if limit > len(ps) {
+		limit = len(ps)
+	}
+
+	defaultHeader := "unknown"
+	table := make([][]string, limit+1)
+	table[0] = []string{"id", "status", "ref", "created at"}
+	for k, v := range ps {
+		createdAt := defaultHeader
+		if v.CreatedAt != nil {
+			createdAt = v.CreatedAt.String()
+		}
+
+		if k < limit {
+			table[k+1] = append(table[k+1], strconv.Itoa(v.ID), v.Status, v.Ref, createdAt)
+		}
+	}
+
+	return table
+}
+
+func (g *Gitlab) fetchPipelines(repository string, ref string, limit int) ([]gitlabPipeline, error) {
+	ps := []gitlabPipeline{}
+	params := url.Values{"per_page": {strconv.Itoa(limit)}}
+	if ref != "" {
+		params.Set("ref", ref)
+	}
+
+	if err := g.getProject(repository, "pipelines", params, &ps); err != nil {
+		return nil, err
+	}
+
+	return ps, nil
+}
+
+// projectID is the path of the project with its namespace, like "owner/repository".
+func (g *Gitlab) projectID(repository string) (string, error) {
+	repo := g.repoName
+	if repository != "" {
+		repo = repository
+	}
+
+	if repo == "" {
+		return "", errors.New("you need to specify a repository in the gitlab service or in the widget")
+	}
+
+	// The repository can include its namespace, with subgroups.
+	if strings.Contains(repo, "/") || g.owner == "" {
+		return repo, nil
+	}
+
+	return g.owner + "/" + repo, nil
+}
+
+// getProject decodes the response of an endpoint of the project in v.
+func (g *Gitlab) getProject(repository string, endpoint string, params url.Values, v interface{}) error {
+	id, err := g.projectID(repository)
+	if err != nil {
+		return err
+	}
+
+	u := fmt.Sprintf("%s/api/v4/projects/%s", g.baseURL, url.PathEscape(id))
+	if endpoint != "" {
+		u += "/" + endpoint
+	}
+	if len(params) > 0 {
+		u += "?" + params.Encode()
+	}
+
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+
+	if g.token != "" {
+		req.Header.Set("PRIVATE-TOKEN", g.token)
+	}
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "can't fetch project %s", id)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return errors.Wrapf(err, "can't read response for project %s", id)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("can't find project %s: gitlab API returned %s", id, resp.Status)
+	}
+
+	if err := json.Unmarshal(body, v); err != nil {
+		return errors.Wrapf(err, "can't read response for project %s", id)
+	}
+
+	return nil
+}
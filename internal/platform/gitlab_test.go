@@ -0,0 +1,300 @@
+package platform
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func Test_formatListMergeRequests(t *testing.T) {
+	testCases := []struct {
+		name        string
+		expected    [][]string
+		limit       int
+		fixtureFile string
+	}{
+		{
+			name: "happy case",
+			expected: [][]string{
+				{"title", "state", "created at", "merged", "author"},
+				{"Add a gitlab service", "merged", "2020-01-02 10:00:00 +0000 UTC", "true", "phantas0s"},
+				{"Fix the documentation", "opened", "2020-01-01 10:00:00 +0000 UTC", "false", "unknown"},
+			},
+			limit:       5,
+			fixtureFile: "./testdata/fixtures/gitlab_merge_requests.json",
+		},
+		{
+			name: "limit",
+			expected: [][]string{
+				{"title", "state", "created at", "merged", "author"},
+				{"Add a gitlab service", "merged", "2020-01-02 10:00:00 +0000 UTC", "true", "phantas0s"},
+			},
+			limit:       1,
+			fixtureFile: "./testdata/fixtures/gitlab_merge_requests.json",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mrs := []gitlabMergeRequest{}
+			fixtures := ReadFixtureFile(tc.fixtureFile, t)
+			if err := json.Unmarshal(fixtures, &mrs); err != nil {
+				t.Error(err)
+			}
+
+			actual := formatListMergeRequests(mrs, tc.limit)
+			if !reflect.DeepEqual(tc.expected, actual) {
+				t.Errorf("Expected %v, actual %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_formatGitlabBranches(t *testing.T) {
+	testCases := []struct {
+		name        string
+		expected    [][]string
+		limit       int
+		fixtureFile string
+	}{
+		{
+			name: "happy case",
+			expected: [][]string{
+				{"name", "last commit by", "last commit at"},
+				{"master", "Matthieu", "2020-01-03"},
+				{"feature", "unknown", "unknown"},
+			},
+			limit:       5,
+			fixtureFile: "./testdata/fixtures/gitlab_branches.json",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			bs := []gitlabBranch{}
+			fixtures := ReadFixtureFile(tc.fixtureFile, t)
+			if err := json.Unmarshal(fixtures, &bs); err != nil {
+				t.Error(err)
+			}
+
+			actual := formatGitlabBranches(bs, tc.limit)
+			if !reflect.DeepEqual(tc.expected, actual) {
+				t.Errorf("Expected %v, actual %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_formatGitlabPipelines(t *testing.T) {
+	testCases := []struct {
+		name        string
+		expected    [][]string
+		limit       int
+		fixtureFile string
+	}{
+		{
+			name: "happy case",
+			expected: [][]string{
+				{"id", "status", "ref", "created at"},
+				{"42", "failed", "master", "2020-01-03 10:00:00 +0000 UTC"},
+				{"41", "success", "feature", "unknown"},
+			},
+			limit:       5,
+			fixtureFile: "./testdata/fixtures/gitlab_pipelines.json",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ps := []gitlabPipeline{}
+			fixtures := ReadFixtureFile(tc.fixtureFile, t)
+			if err := json.Unmarshal(fixtures, &ps); err != nil {
+				t.Error(err)
+			}
+
+			actual := formatGitlabPipelines(ps, tc.limit)
+			if !reflect.DeepEqual(tc.expected, actual) {
+				t.Errorf("Expected %v, actual %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_formatGitlabCountCommits(t *testing.T) {
+	testCases := []struct {
+		name        string
+		expectedDim []string
+		expectedVal []int
+		startDate   time.Time
+		endDate     time.Time
+		fixtureFile string
+	}{
+		{
+			name:        "weeks beginning on sunday",
+			expectedDim: []string{"12-29", "01-05", "01-12"},
+			expectedVal: []int{1, 1, 2},
+			startDate:   time.Date(2020, 01, 01, 00, 00, 00, 00, time.UTC),
+			endDate:     time.Date(2020, 01, 15, 00, 00, 00, 00, time.UTC),
+			fixtureFile: "./testdata/fixtures/gitlab_commits.json",
+		},
+		{
+			name:        "weeks without commit",
+			expectedDim: []string{"01-19", "01-26"},
+			expectedVal: []int{0, 0},
+			startDate:   time.Date(2020, 01, 20, 00, 00, 00, 00, time.UTC),
+			endDate:     time.Date(2020, 01, 30, 00, 00, 00, 00, time.UTC),
+			fixtureFile: "./testdata/fixtures/gitlab_commits.json",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cs := []gitlabCommit{}
+			fixtures := ReadFixtureFile(tc.fixtureFile, t)
+			if err := json.Unmarshal(fixtures, &cs); err != nil {
+				t.Error(err)
+			}
+
+			dim, val := formatGitlabCountCommits(cs, tc.startDate, tc.endDate)
+			if !reflect.DeepEqual(tc.expectedDim, dim) {
+				t.Errorf("Expected %v, actual %v", tc.expectedDim, dim)
+			}
+
+			if !reflect.DeepEqual(tc.expectedVal, val) {
+				t.Errorf("Expected %v, actual %v", tc.expectedVal, val)
+			}
+		})
+	}
+}
+
+func Test_GitlabTotalStars(t *testing.T) {
+	testCases := []struct {
+		name       string
+		expected   int
+		owner      string
+		repository string
+		wantErr    bool
+	}{
+		{
+			name:       "owner and repository",
+			expected:   42,
+			owner:      "phantas0s",
+			repository: "devdash",
+		},
+		{
+			name:       "repository with namespace",
+			expected:   42,
+			repository: "phantas0s/devdash",
+		},
+		{
+			name:       "unknown project",
+			owner:      "phantas0s",
+			repository: "termui",
+			wantErr:    true,
+		},
+		{
+			name:    "no repository",
+			owner:   "phantas0s",
+			wantErr: true,
+		},
+	}
+
+	fixtures := ReadFixtureFile("./testdata/fixtures/gitlab_project.json", t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("PRIVATE-TOKEN") != "token" {
+			t.Errorf("Unexpected token %s", r.Header.Get("PRIVATE-TOKEN"))
+		}
+
+		if r.URL.EscapedPath() != "/api/v4/projects/phantas0s%2Fdevdash" {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Write(fixtures)
+	}))
+	defer server.Close()
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g, err := NewGitlabClient(server.URL, "token", tc.owner, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			actual, err := g.TotalStars(tc.repository)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Error '%v' even if wantErr is %t", err, tc.wantErr)
+				return
+			}
+
+			if tc.wantErr == false && actual != tc.expected {
+				t.Errorf("Expected %v, actual %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_GitlabCountCommits(t *testing.T) {
+	testCases := []struct {
+		name        string
+		expectedVal []int
+		commits     int
+		wantErr     bool
+	}{
+		{
+			name:        "last page not full",
+			expectedVal: []int{150, 0, 0},
+			commits:     150,
+		},
+		{
+			name:        "every page full",
+			expectedVal: []int{gitlabMaxPages * gitlabMaxPerPage, 0, 0},
+			commits:     gitlabMaxPages * gitlabMaxPerPage,
+		},
+		{
+			name:    "more commits than the pages fetched",
+			commits: gitlabMaxPages*gitlabMaxPerPage + 1,
+			wantErr: true,
+		},
+	}
+
+	startDate := time.Date(2020, 01, 01, 00, 00, 00, 00, time.UTC)
+	endDate := time.Date(2020, 01, 15, 00, 00, 00, 00, time.UTC)
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+				n := tc.commits - (page-1)*gitlabMaxPerPage
+				if n > gitlabMaxPerPage {
+					n = gitlabMaxPerPage
+				}
+
+				cs := []gitlabCommit{}
+				for i := 0; i < n; i++ {
+					cs = append(cs, gitlabCommit{CommittedDate: startDate})
+				}
+				json.NewEncoder(w).Encode(cs)
+			}))
+			defer server.Close()
+
+			g, err := NewGitlabClient(server.URL, "token", "phantas0s", "devdash")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, val, err := g.CountCommits("", startDate, endDate)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Error '%v' even if wantErr is %t", err, tc.wantErr)
+			}
+
+			if !reflect.DeepEqual(tc.expectedVal, val) {
+				t.Errorf("Expected %v, actual %v", tc.expectedVal, val)
+			}
+		})
+	}
+}
@@ -11,60 +11,88 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/Phantas0s/devdash/gokit"
 	"github.com/pkg/errors"
-	"golang.org/x/crypto/ssh"
 )
 
 const (
 	sshAgentEnv = "SSH_AUTH_SOCK"
+
+	// hostMaxWorkers collecting the data of the hosts at the same time.
+	hostMaxWorkers = 8
 )
 
 type Host struct {
-	sshClient *ssh.Client
+	// ssh connection, shared with the other Host connected to the same host.
+	ssh       *sshPooledConn
+	closeOnce sync.Once
 	localhost bool
+	// target of the commands, to record their outputs.
+	target string
+	// History of the metrics sampled by the widgets.
+	History *HostHistory
 }
 
 // syntactic sugar
 type runnerFunc func(cmd string) (string, error)
 
-func NewHost(username, addr string) (*Host, error) {
+func NewHost(username, addr string, opts SSHOptions) (*Host, error) {
 	if username == "localhost" && addr == "localhost" {
 		return &Host{
-			sshClient: nil,
+			ssh:       nil,
 			localhost: true,
+			target:    addr,
+			History:   NewHostHistory(hostHistorySize),
 		}, nil
 	}
 
-	sshClient, err := sshAgentAuth(username, addr)
-	if err != nil {
-		return nil, err
+	// No connection needed to replay the recordings.
+	if replaying() {
+		return &Host{target: username + "@" + addr, History: NewHostHistory(hostHistorySize)}, nil
 	}
+
+	// The connection is only dialed by the first command: a host down doesn't prevent the dashboard from being built.
+	// If the dial fails, the errors are reported by the widgets and the connection is retried with a backoff.
+	key := fmt.Sprintf("%s@%s %+v", username, addr, opts)
+	conn := sshPool.get(key, func() (*sshConnection, error) {
+		return sshConnect(username, addr, opts)
+	})
+
 	return &Host{
-		sshClient: sshClient,
+		ssh:       conn,
 		localhost: false,
+		target:    username + "@" + addr,
+		History:   NewHostHistory(hostHistorySize),
 	}, nil
 }
 
+// Close the connection to the host, if no other Host uses it.
+func (s *Host) Close() error {
+	if s.ssh != nil {
+		s.closeOnce.Do(func() { sshPool.release(s.ssh) })
+	}
+
+	return nil
+}
+
 // Run a command on remote server via SSH or on localhost
 func (s *Host) Runner(command string) (string, error) {
+	return recordCommand(s.target, command, func() (string, error) {
+		return s.run(command)
+	})
+}
+
+func (s *Host) run(command string) (string, error) {
 	if s.localhost {
 		return runLocalhost(command)
 	}
 
-	session, err := s.sshClient.NewSession()
-	if err != nil {
-		return "", errors.Wrapf(err, "can't create session with SSH client for command %s", command)
-	}
-	defer session.Close()
-
 	var buf bytes.Buffer
-	session.Stdout = &buf
-	err = session.Run(command)
-	if err != nil {
-		return "", errors.Wrapf(err, "can't run command %s on remote server", command)
+	if err := s.ssh.run(command, &buf); err != nil {
+		return "", err
 	}
 
 	return string(buf.Bytes()), nil
@@ -536,6 +564,92 @@ func HostTable(runner runnerFunc, command string, headers []string) (cells [][]s
 	return
 }
 
+// HostDiskRate of the filesystem mounted on the path, in percent.
+func HostDiskRate(runner runnerFunc, path string) (string, error) {
+	command := "/bin/df -P " + path
+	lines, err := runner(command)
+	if err != nil {
+		return "", err
+	}
+
+	// The header is followed by the filesystem.
+	res := strings.Split(strings.TrimSpace(lines), "\n")
+	parts := strings.Fields(res[len(res)-1])
+	if len(res) < 2 || len(parts) < 6 {
+		return "", errors.Errorf("command %s return unexpected %v", command, lines)
+	}
+
+	return parts[4], nil
+}
+
+// HostFleet summarizes the resources of each host, collected concurrently.
+// The runner returns the function running the commands on the host given.
+// A host failing doesn't fail the others: its row displays the error instead of its resources.
+func HostFleet(hosts []string, runner func(host string) (func(cmd string) (string, error), error)) [][]string {
+	rows := make([][]string, len(hosts))
+
+	var wg sync.WaitGroup
+	sem := make(chan bool, hostMaxWorkers)
+	for k, v := range hosts {
+		sem <- true
+		wg.Add(1)
+		go func(k int, host string) {
+			defer func() { <-sem }()
+			defer wg.Done()
+
+			row, err := hostFleetRow(host, runner)
+			if err != nil {
+				row = []string{host, errors.Cause(err).Error(), "", "", "", ""}
+			}
+			rows[k] = row
+		}(k, v)
+	}
+	wg.Wait()
+
+	return append([][]string{{"host", "cpu", "memory", "load", "disk", "uptime"}}, rows...)
+}
+
+func hostFleetRow(host string, runner func(host string) (func(cmd string) (string, error), error)) ([]string, error) {
+	r, err := runner(host)
+	if err != nil {
+		return nil, err
+	}
+
+	cpu, err := HostCPURate(r)
+	if err != nil {
+		return nil, err
+	}
+
+	mem, err := HostMemoryRate(r)
+	if err != nil {
+		return nil, err
+	}
+
+	load, err := HostLoad(r)
+	if err != nil {
+		return nil, err
+	}
+
+	disk, err := HostDiskRate(r, "/")
+	if err != nil {
+		return nil, err
+	}
+
+	uptime, err := HostUptime(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return []string{
+		host,
+		strconv.FormatFloat(cpu, 'f', 2, 64) + " %",
+		strconv.FormatFloat(mem, 'f', 2, 64) + " %",
+		load,
+		disk,
+		formatAge(time.Duration(uptime)),
+	}, nil
+}
+
 func formatToBar(data string) (val []uint64) {
 	data = strings.Trim(data, ",")
 	s := strings.Split(data, ",")
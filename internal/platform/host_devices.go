@@ -0,0 +1,235 @@
+package platform
+
+// host_devices gives the metrics of each core, network interface and block device of a host.
+// The rates are computed from the counters of the last sample kept in the history of the host.
+// The devices can be filtered with glob patterns, like "eth*" or "sd?".
+
+import (
+	"bufio"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Phantas0s/devdash/gokit"
+)
+
+type hostNetInterface struct {
+	name    string
+	rxBytes uint64
+	rxErrs  uint64
+	rxDrop  uint64
+	txBytes uint64
+	txErrs  uint64
+	txDrop  uint64
+}
+
+type hostDiskDevice struct {
+	name  string
+	read  uint64
+	write uint64
+}
+
+// HostCPUCores returns the usage in percent of each core, with their names.
+// The usage is computed since the last sample, or since the boot for the first one.
+func HostCPUCores(
+	runner runnerFunc,
+	history *HostHistory,
+	include []string,
+	exclude []string,
+	now time.Time,
+) ([]int, []string, error) {
+	raw, err := runner("/bin/cat /proc/stat")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	usage := []int{}
+	cores := []string{}
+	scanner := bufio.NewScanner(strings.NewReader(raw))
+	for scanner.Scan() {
+		parts := strings.Fields(scanner.Text())
+		// The first line is the aggregate of the cores: "cpu".
+		if len(parts) == 0 || !strings.HasPrefix(parts[0], "cpu") || parts[0] == "cpu" {
+			continue
+		}
+
+		name := parts[0]
+		if !hostDeviceMatches(name, include, exclude) {
+			continue
+		}
+
+		busy, total, err := parseCPUCounters(parts)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		b, t := float64(busy), float64(total)
+		if r, ok := history.rates("cpu/"+name, []uint64{busy, total}, now); ok {
+			b, t = r[0], r[1]
+		}
+
+		rate := 0
+		if t > 0 {
+			rate = int(b * 100 / t)
+		}
+
+		usage = append(usage, rate)
+		cores = append(cores, name)
+	}
+
+	return usage, cores, nil
+}
+
+// HostNetInterfaces returns a table with the bytes received and transmitted per second by each interface,
+// with their errors and drops since the boot.
+// The rates are only known from the second sample.
+func HostNetInterfaces(
+	runner runnerFunc,
+	history *HostHistory,
+	unit string,
+	include []string,
+	exclude []string,
+	now time.Time,
+) ([][]string, error) {
+	raw, err := runner("/bin/cat /proc/net/dev")
+	if err != nil {
+		return nil, err
+	}
+
+	table := [][]string{{"interface", "rx/s", "tx/s", "rx errors", "tx errors", "rx drops", "tx drops"}}
+	for _, v := range parseNetInterfaces(raw) {
+		if !hostDeviceMatches(v.name, include, exclude) {
+			continue
+		}
+
+		r, ok := history.rates("net/"+v.name, []uint64{v.rxBytes, v.txBytes}, now)
+		table = append(table, []string{
+			v.name,
+			formatHostRate(r, 0, ok, unit),
+			formatHostRate(r, 1, ok, unit),
+			strconv.FormatUint(v.rxErrs, 10),
+			strconv.FormatUint(v.txErrs, 10),
+			strconv.FormatUint(v.rxDrop, 10),
+			strconv.FormatUint(v.txDrop, 10),
+		})
+	}
+
+	return table, nil
+}
+
+// HostDiskDevices returns a table with the bytes read and written per second by each block device.
+// The rates are only known from the second sample.
+func HostDiskDevices(
+	runner runnerFunc,
+	history *HostHistory,
+	unit string,
+	include []string,
+	exclude []string,
+	now time.Time,
+) ([][]string, error) {
+	raw, err := runner("/bin/cat /proc/diskstats")
+	if err != nil {
+		return nil, err
+	}
+
+	table := [][]string{{"device", "read/s", "write/s"}}
+	for _, v := range parseDiskDevices(raw) {
+		if !hostDeviceMatches(v.name, include, exclude) {
+			continue
+		}
+
+		r, ok := history.rates("disk/"+v.name, []uint64{v.read, v.write}, now)
+		table = append(table, []string{
+			v.name,
+			formatHostRate(r, 0, ok, unit),
+			formatHostRate(r, 1, ok, unit),
+		})
+	}
+
+	return table, nil
+}
+
+// parseNetInterfaces of /proc/net/dev.
+func parseNetInterfaces(raw string) []hostNetInterface {
+	interfaces := []hostNetInterface{}
+	scanner := bufio.NewScanner(strings.NewReader(raw))
+	for scanner.Scan() {
+		// The interface can be stuck to its first counter, like "eth0:1234".
+		line := strings.SplitN(scanner.Text(), ":", 2)
+		if len(line) < 2 {
+			continue
+		}
+
+		parts := strings.Fields(line[1])
+		if len(parts) < 12 {
+			continue
+		}
+
+		counters := make([]uint64, len(parts))
+		for k, v := range parts {
+			counters[k], _ = strconv.ParseUint(v, 10, 64)
+		}
+
+		interfaces = append(interfaces, hostNetInterface{
+			name:    strings.TrimSpace(line[0]),
+			rxBytes: counters[0],
+			rxErrs:  counters[2],
+			rxDrop:  counters[3],
+			txBytes: counters[8],
+			txErrs:  counters[10],
+			txDrop:  counters[11],
+		})
+	}
+
+	return interfaces
+}
+
+// parseDiskDevices of /proc/diskstats, with the bytes read and written.
+func parseDiskDevices(raw string) []hostDiskDevice {
+	devices := []hostDiskDevice{}
+	scanner := bufio.NewScanner(strings.NewReader(raw))
+	for scanner.Scan() {
+		parts := strings.Fields(scanner.Text())
+		if len(parts) < 10 {
+			continue
+		}
+
+		// Sectors of 512 bytes, whatever the device.
+		r, _ := strconv.ParseUint(parts[5], 10, 64)
+		w, _ := strconv.ParseUint(parts[9], 10, 64)
+		devices = append(devices, hostDiskDevice{name: parts[2], read: r * 512, write: w * 512})
+	}
+
+	return devices
+}
+
+// hostDeviceMatches returns true if the name matches one of the include patterns (if any), and none of the exclude ones.
+func hostDeviceMatches(name string, include []string, exclude []string) bool {
+	for _, p := range exclude {
+		if ok, _ := path.Match(p, name); ok {
+			return false
+		}
+	}
+
+	if len(include) == 0 {
+		return true
+	}
+
+	for _, p := range include {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// formatHostRate of the rates at index k, converted from bytes to the unit. It's "-" when the rates are not known yet.
+func formatHostRate(rates []float64, k int, ok bool, unit string) string {
+	if !ok {
+		return "-"
+	}
+
+	return strconv.FormatFloat(gokit.ConvertBinUnit(rates[k], "b", unit), 'f', 2, 64)
+}
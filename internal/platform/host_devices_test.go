@@ -0,0 +1,188 @@
+package platform
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_HostCPUCores(t *testing.T) {
+	now := time.Date(2020, 6, 1, 10, 0, 0, 0, time.UTC)
+	cpu := string(ReadFixtureFile("./testdata/fixtures/host_cpu", t))
+
+	testCases := []struct {
+		name          string
+		expected      []int
+		expectedCores []string
+		include       []string
+		exclude       []string
+		runner        runnerFunc
+		wantErr       bool
+	}{
+		{
+			name:          "usage since the boot",
+			expected:      []int{12, 11, 12, 11},
+			expectedCores: []string{"cpu0", "cpu1", "cpu2", "cpu3"},
+			runner:        historyRunner(cpu),
+		},
+		{
+			name:          "filters",
+			expected:      []int{11, 12},
+			expectedCores: []string{"cpu1", "cpu2"},
+			include:       []string{"cpu[0-2]"},
+			exclude:       []string{"cpu0"},
+			runner:        historyRunner(cpu),
+		},
+		{
+			name:    "runner return error",
+			runner:  historyRunner(),
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, cores, err := HostCPUCores(tc.runner, NewHostHistory(10), tc.include, tc.exclude, now)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Error '%v' even if wantErr is %t", err, tc.wantErr)
+				return
+			}
+
+			if tc.wantErr {
+				return
+			}
+
+			if !reflect.DeepEqual(actual, tc.expected) {
+				t.Errorf("Expected %v, actual %v", tc.expected, actual)
+			}
+			if !reflect.DeepEqual(cores, tc.expectedCores) {
+				t.Errorf("Expected %v, actual %v", tc.expectedCores, cores)
+			}
+		})
+	}
+}
+
+func Test_HostCPUCoresRates(t *testing.T) {
+	now := time.Date(2020, 6, 1, 10, 0, 0, 0, time.UTC)
+	runner := historyRunner(
+		"cpu  200 0 200 1600 0 0 0 0 0 0\ncpu0 100 0 100 800 0 0 0 0 0 0\ncpu1 100 0 100 800 0 0 0 0 0 0\n",
+		"cpu  275 0 200 1825 0 0 0 0 0 0\ncpu0 175 0 100 825 0 0 0 0 0 0\ncpu1 100 0 100 1000 0 0 0 0 0 0\n",
+	)
+
+	history := NewHostHistory(10)
+	if _, _, err := HostCPUCores(runner, history, nil, nil, now); err != nil {
+		t.Errorf("Error '%v' even if wantErr is %t", err, false)
+	}
+
+	actual, _, err := HostCPUCores(runner, history, nil, nil, now.Add(5*time.Second))
+	if err != nil {
+		t.Errorf("Error '%v' even if wantErr is %t", err, false)
+	}
+
+	if expected := []int{75, 0}; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %v, actual %v", expected, actual)
+	}
+}
+
+func Test_HostNetInterfaces(t *testing.T) {
+	now := time.Date(2020, 6, 1, 10, 0, 0, 0, time.UTC)
+	first := string(ReadFixtureFile("./testdata/fixtures/host_net", t))
+	second := `Inter-|   Receive                                                |  Transmit
+ face |bytes    packets errs drop fifo frame compressed multicast|bytes    packets errs drop fifo colls carrier compressed
+    lo:  695120   12180    0    0    0     0          0         0   695120   12180    0    0    0     0       0          0
+enp0s25:       0       0    0    0    0     0          0         0        0       0    0    0    0     0       0          0
+wlp3s0:369680804  329430    1    2    0     0          0         0 19450230  149620    3    4    0     0       0          0
+`
+
+	testCases := []struct {
+		name     string
+		expected [][]string
+		include  []string
+		exclude  []string
+		times    []time.Time
+	}{
+		{
+			name: "first sample",
+			expected: [][]string{
+				{"interface", "rx/s", "tx/s", "rx errors", "tx errors", "rx drops", "tx drops"},
+				{"lo", "-", "-", "0", "0", "0", "0"},
+				{"enp0s25", "-", "-", "0", "0", "0", "0"},
+				{"wlp3s0", "-", "-", "0", "0", "2", "0"},
+				{"docker0", "-", "-", "0", "0", "0", "0"},
+			},
+			times: []time.Time{now},
+		},
+		{
+			name: "rates",
+			expected: [][]string{
+				{"interface", "rx/s", "tx/s", "rx errors", "tx errors", "rx drops", "tx drops"},
+				{"enp0s25", "0.00", "0.00", "0", "0", "0", "0"},
+				{"wlp3s0", "1.00", "0.20", "1", "3", "2", "4"},
+			},
+			exclude: []string{"lo"},
+			times:   []time.Time{now, now.Add(10 * time.Second)},
+		},
+		{
+			name: "include",
+			expected: [][]string{
+				{"interface", "rx/s", "tx/s", "rx errors", "tx errors", "rx drops", "tx drops"},
+				{"wlp3s0", "1.00", "0.20", "1", "3", "2", "4"},
+			},
+			include: []string{"wl*", "eth*"},
+			times:   []time.Time{now, now.Add(10 * time.Second)},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			runner := historyRunner(first, second)
+			history := NewHostHistory(10)
+
+			var actual [][]string
+			var err error
+			for _, v := range tc.times {
+				actual, err = HostNetInterfaces(runner, history, "kb", tc.include, tc.exclude, v)
+				if err != nil {
+					t.Errorf("Error '%v' even if wantErr is %t", err, false)
+					return
+				}
+			}
+
+			if !reflect.DeepEqual(actual, tc.expected) {
+				t.Errorf("Expected %v, actual %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_HostDiskDevices(t *testing.T) {
+	now := time.Date(2020, 6, 1, 10, 0, 0, 0, time.UTC)
+	first := string(ReadFixtureFile("./testdata/fixtures/host_disk_io", t))
+	second := `   8       0 sda 57930 25071 4073846 23677 107420 128303 5706476 198063 0 149017 109857 0 0 0 0 28575 17774
+   8       1 sda1 135 32 8688 46 7 1 28 12 0 127 7 0 0 0 0 0 0
+   8       2 sda2 60 7 4744 29 160 862 8176 153 0 254 80 0 0 0 0 0 0
+   8       3 sda3 31122 11213 2949090 12747 6031 4518 102640 4224 0 26097 3804 0 0 0 0 0 0
+   8       4 sda4 26460 13819 1108914 10516 100609 122922 5595632 193132 0 125517 105640 0 0 0 0 0 0
+`
+
+	runner := historyRunner(first, second)
+	history := NewHostHistory(10)
+	exclude := []string{"sda[1-3]"}
+	if _, err := HostDiskDevices(runner, history, "kb", nil, exclude, now); err != nil {
+		t.Errorf("Error '%v' even if wantErr is %t", err, false)
+	}
+
+	actual, err := HostDiskDevices(runner, history, "kb", nil, exclude, now.Add(4*time.Second))
+	if err != nil {
+		t.Errorf("Error '%v' even if wantErr is %t", err, false)
+	}
+
+	expected := [][]string{
+		{"device", "read/s", "write/s"},
+		{"sda", "5.00", "10.00"},
+		{"sda4", "5.00", "10.00"},
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %v, actual %v", expected, actual)
+	}
+}
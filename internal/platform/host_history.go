@@ -0,0 +1,344 @@
+package platform
+
+// host_history keeps the last samples of the metrics of a host, to display their evolution.
+// The rates (CPU, network and disks) are computed from the difference between two samples of their counters:
+// the first sample of a rate only gives the reference of the next ones.
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Phantas0s/devdash/gokit"
+	"github.com/pkg/errors"
+)
+
+const (
+	// hostHistorySize is the number of samples kept for each metric.
+	hostHistorySize = 100
+	// hostMinSampleInterval between two samples of the same metric.
+	// The widgets displaying the same metric are refreshed together: only the first one takes a sample.
+	hostMinSampleInterval = time.Second
+
+	HostMetricCPU       = "cpu"
+	HostMetricMemory    = "memory"
+	HostMetricNetRx     = "net_rx"
+	HostMetricNetTx     = "net_tx"
+	HostMetricDiskRead  = "disk_read"
+	HostMetricDiskWrite = "disk_write"
+)
+
+// HostHistory of the metrics of a host, in memory.
+type HostHistory struct {
+	mu     sync.Mutex
+	size   int
+	series map[string][]hostSample
+	// counters of the last sample of each rate, and the rates computed.
+	counters  map[string]hostCounters
+	lastRates map[string][]float64
+	// sampled is the time of the last sample of each metric.
+	sampled map[string]time.Time
+}
+
+type hostSample struct {
+	value float64
+	at    time.Time
+}
+
+type hostCounters struct {
+	values []uint64
+	at     time.Time
+}
+
+func NewHostHistory(size int) *HostHistory {
+	return &HostHistory{
+		size:      size,
+		series:    map[string][]hostSample{},
+		counters:  map[string]hostCounters{},
+		lastRates: map[string][]float64{},
+		sampled:   map[string]time.Time{},
+	}
+}
+
+// Values of the metric, from the oldest, converted from bytes to the unit given.
+// The unit is ignored if empty, for the metrics which are not in bytes.
+func (h *HostHistory) Values(metric string, unit string) []float64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	values := make([]float64, 0, len(h.series[metric]))
+	for _, v := range h.series[metric] {
+		if unit != "" {
+			v.value = gokit.ConvertBinUnit(v.value, "b", unit)
+		}
+		values = append(values, v.value)
+	}
+
+	return values
+}
+
+// Times of the samples of the metric, formatted with the layout given.
+func (h *HostHistory) Times(metric string, layout string) []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	times := make([]string, 0, len(h.series[metric]))
+	for _, v := range h.series[metric] {
+		times = append(times, v.at.Format(layout))
+	}
+
+	return times
+}
+
+// sample returns true if a new sample of the metric needs to be taken.
+func (h *HostHistory) sample(metric string, now time.Time) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if last, ok := h.sampled[metric]; ok && now.Sub(last) < hostMinSampleInterval {
+		return false
+	}
+	h.sampled[metric] = now
+
+	return true
+}
+
+// add a value to the metric, removing the oldest values beyond the size of the history.
+func (h *HostHistory) add(metric string, value float64, at time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	s := append(h.series[metric], hostSample{value: value, at: at})
+	if len(s) > h.size {
+		s = s[len(s)-h.size:]
+	}
+	h.series[metric] = s
+}
+
+// rates per second of the counters since their last sample, for the key given.
+// It returns false for the first sample, or if the counters have been reset (after a reboot for example).
+// If the counters are sampled again too soon, the rates of the last sample are returned.
+func (h *HostHistory) rates(key string, values []uint64, now time.Time) ([]float64, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	last, ok := h.counters[key]
+	if ok && now.Sub(last.at) < hostMinSampleInterval {
+		r, ok := h.lastRates[key]
+		return r, ok
+	}
+
+	h.counters[key] = hostCounters{values: values, at: now}
+	delete(h.lastRates, key)
+
+	elapsed := now.Sub(last.at).Seconds()
+	if !ok || len(last.values) != len(values) {
+		return nil, false
+	}
+
+	rates := make([]float64, len(values))
+	for k, v := range values {
+		if v < last.values[k] {
+			return nil, false
+		}
+		rates[k] = float64(v-last.values[k]) / elapsed
+	}
+	h.lastRates[key] = rates
+
+	return rates, true
+}
+
+// HostCPUHistory of the CPU usage in percent, between each sample.
+func HostCPUHistory(runner runnerFunc, history *HostHistory, now time.Time) ([]float64, error) {
+	if !history.sample(HostMetricCPU, now) {
+		return history.Values(HostMetricCPU, ""), nil
+	}
+
+	raw, err := runner("/bin/cat /proc/stat")
+	if err != nil {
+		return nil, err
+	}
+
+	busy, total, err := parseCPUCounters(strings.Fields(strings.SplitN(raw, "\n", 2)[0]))
+	if err != nil {
+		return nil, err
+	}
+
+	// The rates of the busy and total times give their ratio.
+	if r, ok := history.rates(HostMetricCPU, []uint64{busy, total}, now); ok && r[1] > 0 {
+		history.add(HostMetricCPU, r[0]*100/r[1], now)
+	}
+
+	return history.Values(HostMetricCPU, ""), nil
+}
+
+// parseCPUCounters of a line of /proc/stat (the aggregate of the CPUs or a core): the time busy and the total time.
+func parseCPUCounters(cpu []string) (busy uint64, total uint64, err error) {
+	if len(cpu) < 5 || !strings.HasPrefix(cpu[0], "cpu") {
+		return 0, 0, errors.Errorf("needs 5 fields for cpu: header, user, nice, system, idle. Instead, having %s", cpu)
+	}
+
+	var idle uint64
+	for k, v := range cpu[1:] {
+		// guest and guest_nice are already counted in user and nice.
+		if k >= 8 {
+			break
+		}
+
+		n, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return 0, 0, errors.Wrapf(err, "can't parse cpu field %s", v)
+		}
+
+		total += n
+		// idle and iowait
+		if k == 3 || k == 4 {
+			idle += n
+		}
+	}
+
+	return total - idle, total, nil
+}
+
+// HostMemoryHistory of the memory usage in percent.
+func HostMemoryHistory(runner runnerFunc, history *HostHistory, now time.Time) ([]float64, error) {
+	if !history.sample(HostMetricMemory, now) {
+		return history.Values(HostMetricMemory, ""), nil
+	}
+
+	rate, err := HostMemoryRate(runner)
+	if err != nil {
+		return nil, err
+	}
+	history.add(HostMetricMemory, rate, now)
+
+	return history.Values(HostMetricMemory, ""), nil
+}
+
+// HostNetIOHistory of the bytes received and transmitted per second, by every interface except the loopback.
+// The rates are converted to the unit given (b, kb, mb...).
+func HostNetIOHistory(
+	runner runnerFunc,
+	history *HostHistory,
+	unit string,
+	now time.Time,
+) (rx []float64, tx []float64, err error) {
+	if history.sample(HostMetricNetRx, now) {
+		raw, err := runner("/bin/cat /proc/net/dev")
+		if err != nil {
+			return nil, nil, err
+		}
+
+		r, t := parseNetDev(raw)
+		if rates, ok := history.rates(HostMetricNetRx, []uint64{r, t}, now); ok {
+			history.add(HostMetricNetRx, rates[0], now)
+			history.add(HostMetricNetTx, rates[1], now)
+		}
+	}
+
+	return history.Values(HostMetricNetRx, unit), history.Values(HostMetricNetTx, unit), nil
+}
+
+// parseNetDev returns the bytes received and transmitted by every interface of /proc/net/dev, except the loopback.
+func parseNetDev(raw string) (rx uint64, tx uint64) {
+	for _, v := range parseNetInterfaces(raw) {
+		if v.name == "lo" {
+			continue
+		}
+		rx += v.rxBytes
+		tx += v.txBytes
+	}
+
+	return rx, tx
+}
+
+// HostDiskIOHistory of the bytes read and written per second, by every disk.
+// The rates are converted to the unit given (b, kb, mb...).
+func HostDiskIOHistory(
+	runner runnerFunc,
+	history *HostHistory,
+	unit string,
+	now time.Time,
+) (read []float64, write []float64, err error) {
+	if history.sample(HostMetricDiskRead, now) {
+		raw, err := runner("/bin/cat /proc/diskstats")
+		if err != nil {
+			return nil, nil, err
+		}
+
+		r, w := parseDiskStats(raw)
+		if rates, ok := history.rates(HostMetricDiskRead, []uint64{r, w}, now); ok {
+			history.add(HostMetricDiskRead, rates[0], now)
+			history.add(HostMetricDiskWrite, rates[1], now)
+		}
+	}
+
+	return history.Values(HostMetricDiskRead, unit), history.Values(HostMetricDiskWrite, unit), nil
+}
+
+// parseDiskStats returns the bytes read and written by the disks of /proc/diskstats.
+// The partitions are listed after their disk: they are ignored, to count the bytes only once.
+// The virtual devices (loop, ram) and the devices stacked on the disks (device mapper, software RAID) are ignored too.
+func parseDiskStats(raw string) (read uint64, write uint64) {
+	disks := []string{}
+	for _, v := range parseDiskDevices(raw) {
+		if isVirtualDisk(v.name) || isPartition(v.name, disks) {
+			continue
+		}
+		disks = append(disks, v.name)
+
+		read += v.read
+		write += v.write
+	}
+
+	return read, write
+}
+
+func isVirtualDisk(name string) bool {
+	for _, p := range []string{"loop", "ram", "dm-", "md"} {
+		if strings.HasPrefix(name, p) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isPartition of one of the disks: the name of the disk followed by a number (sda1),
+// or by "p" and a number if the name of the disk ends with a digit (nvme0n1p1).
+func isPartition(name string, disks []string) bool {
+	for _, d := range disks {
+		if !strings.HasPrefix(name, d) {
+			continue
+		}
+
+		n := name[len(d):]
+		if last := d[len(d)-1]; last >= '0' && last <= '9' {
+			if !strings.HasPrefix(n, "p") {
+				continue
+			}
+			n = n[1:]
+		}
+
+		if isDigits(n) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+
+	return true
+}
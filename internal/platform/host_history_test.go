@@ -0,0 +1,267 @@
+package platform
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// historyRunner returns the outputs given, one for each command run.
+func historyRunner(outputs ...string) runnerFunc {
+	i := 0
+	return func(cmd string) (string, error) {
+		if i >= len(outputs) {
+			return "", errors.New("no more output")
+		}
+		i++
+		return outputs[i-1], nil
+	}
+}
+
+func Test_HostCPUHistory(t *testing.T) {
+	now := time.Date(2020, 6, 1, 10, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name     string
+		expected []float64
+		outputs  []string
+		times    []time.Time
+		wantErr  bool
+	}{
+		{
+			name:     "first sample only gives the reference",
+			expected: []float64{},
+			outputs:  []string{"cpu  100 0 100 800 0 0 0 0 0 0\n"},
+			times:    []time.Time{now},
+		},
+		{
+			name:     "usage between the samples",
+			expected: []float64{25, 50},
+			outputs: []string{
+				"cpu  100 0 100 800 0 0 0 0 0 0\n",
+				"cpu  125 0 100 875 0 0 0 0 0 0\n",
+				"cpu  150 0 125 925 0 0 0 0 0 0\n",
+			},
+			times: []time.Time{now, now.Add(5 * time.Second), now.Add(10 * time.Second)},
+		},
+		{
+			name:     "samples too close are ignored",
+			expected: []float64{25},
+			outputs: []string{
+				"cpu  100 0 100 800 0 0 0 0 0 0\n",
+				"cpu  125 0 100 875 0 0 0 0 0 0\n",
+			},
+			times: []time.Time{now, now.Add(5 * time.Second), now.Add(5*time.Second + time.Millisecond)},
+		},
+		{
+			name:     "counters reset",
+			expected: []float64{},
+			outputs: []string{
+				"cpu  100 0 100 800 0 0 0 0 0 0\n",
+				"cpu  10 0 10 80 0 0 0 0 0 0\n",
+			},
+			times: []time.Time{now, now.Add(5 * time.Second)},
+		},
+		{
+			name:    "wrong result",
+			outputs: []string{"intr 1 2 3\n"},
+			times:   []time.Time{now},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			runner := historyRunner(tc.outputs...)
+			history := NewHostHistory(10)
+
+			var actual []float64
+			var err error
+			for _, v := range tc.times {
+				actual, err = HostCPUHistory(runner, history, v)
+				if err != nil {
+					break
+				}
+			}
+
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Error '%v' even if wantErr is %t", err, tc.wantErr)
+				return
+			}
+
+			if tc.wantErr == false && !reflect.DeepEqual(actual, tc.expected) {
+				t.Errorf("Expected %v, actual %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_HostNetIOHistory(t *testing.T) {
+	now := time.Date(2020, 6, 1, 10, 0, 0, 0, time.UTC)
+	first := string(ReadFixtureFile("./testdata/fixtures/host_net", t))
+	// 10240 bytes received and 2048 bytes sent by wlp3s0, 5120 bytes by the loopback.
+	second := `Inter-|   Receive                                                |  Transmit
+ face |bytes    packets errs drop fifo frame compressed multicast|bytes    packets errs drop fifo colls carrier compressed
+    lo:  695120   12180    0    0    0     0          0         0   695120   12180    0    0    0     0       0          0
+enp0s25:       0       0    0    0    0     0          0         0        0       0    0    0    0     0       0          0
+wlp3s0:369680804  329430    0    2    0     0          0         0 19450230  149620    0    0    0     0       0          0
+`
+
+	testCases := []struct {
+		name       string
+		expectedRx []float64
+		expectedTx []float64
+		unit       string
+		runner     runnerFunc
+		wantErr    bool
+	}{
+		{
+			name:       "happy case",
+			expectedRx: []float64{1},
+			expectedTx: []float64{0.2},
+			unit:       "kb",
+			runner:     historyRunner(first, second),
+		},
+		{
+			name:       "bytes",
+			expectedRx: []float64{1024},
+			expectedTx: []float64{204.8},
+			unit:       "b",
+			runner:     historyRunner(first, second),
+		},
+		{
+			name:    "runner return error",
+			unit:    "kb",
+			runner:  historyRunner(),
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			history := NewHostHistory(10)
+			_, _, err := HostNetIOHistory(tc.runner, history, tc.unit, now)
+			if err == nil {
+				_, _, err = HostNetIOHistory(tc.runner, history, tc.unit, now.Add(10*time.Second))
+			}
+
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Error '%v' even if wantErr is %t", err, tc.wantErr)
+				return
+			}
+
+			if tc.wantErr {
+				return
+			}
+
+			rx := history.Values(HostMetricNetRx, tc.unit)
+			tx := history.Values(HostMetricNetTx, tc.unit)
+			if !reflect.DeepEqual(rx, tc.expectedRx) {
+				t.Errorf("Expected %v, actual %v", tc.expectedRx, rx)
+			}
+			if !reflect.DeepEqual(tx, tc.expectedTx) {
+				t.Errorf("Expected %v, actual %v", tc.expectedTx, tx)
+			}
+		})
+	}
+}
+
+func Test_HostDiskIOHistory(t *testing.T) {
+	now := time.Date(2020, 6, 1, 10, 0, 0, 0, time.UTC)
+	first := string(ReadFixtureFile("./testdata/fixtures/host_disk_io", t))
+	// 40 sectors read and 80 sectors written on sda, counted again in its partition sda4.
+	second := `   8       0 sda 57930 25071 4073846 23677 107420 128303 5706476 198063 0 149017 109857 0 0 0 0 28575 17774
+   8       1 sda1 135 32 8688 46 7 1 28 12 0 127 7 0 0 0 0 0 0
+   8       2 sda2 60 7 4744 29 160 862 8176 153 0 254 80 0 0 0 0 0 0
+   8       3 sda3 31122 11213 2949090 12747 6031 4518 102640 4224 0 26097 3804 0 0 0 0 0 0
+   8       4 sda4 26460 13819 1108914 10516 100609 122922 5595632 193132 0 125517 105640 0 0 0 0 0 0
+   7       0 loop0 100 0 2000 0 0 0 0 0 0 0 0 0 0 0 0 0 0
+`
+
+	history := NewHostHistory(10)
+	runner := historyRunner(first, second)
+	if _, _, err := HostDiskIOHistory(runner, history, "b", now); err != nil {
+		t.Errorf("Error '%v' even if wantErr is %t", err, false)
+	}
+
+	read, write, err := HostDiskIOHistory(runner, history, "b", now.Add(4*time.Second))
+	if err != nil {
+		t.Errorf("Error '%v' even if wantErr is %t", err, false)
+	}
+
+	if expected := []float64{5120}; !reflect.DeepEqual(read, expected) {
+		t.Errorf("Expected %v, actual %v", expected, read)
+	}
+	if expected := []float64{10240}; !reflect.DeepEqual(write, expected) {
+		t.Errorf("Expected %v, actual %v", expected, write)
+	}
+}
+
+func Test_HostHistorySize(t *testing.T) {
+	now := time.Date(2020, 6, 1, 10, 0, 0, 0, time.UTC)
+	history := NewHostHistory(3)
+	for k := 0; k < 5; k++ {
+		history.add(HostMetricMemory, float64(k), now.Add(time.Duration(k)*time.Minute))
+	}
+
+	if expected, actual := []float64{2, 3, 4}, history.Values(HostMetricMemory, ""); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %v, actual %v", expected, actual)
+	}
+
+	expected := []string{"10:02", "10:03", "10:04"}
+	if actual := history.Times(HostMetricMemory, "15:04"); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %v, actual %v", expected, actual)
+	}
+}
+
+func Test_isPartition(t *testing.T) {
+	disks := []string{"sda", "nvme0n1", "mmcblk0"}
+
+	testCases := []struct {
+		name     string
+		expected bool
+	}{
+		{name: "sda1", expected: true},
+		{name: "sda12", expected: true},
+		{name: "sdaa", expected: false},
+		{name: "sdaa1", expected: false},
+		{name: "nvme0n1p1", expected: true},
+		{name: "nvme0n10", expected: false},
+		{name: "nvme0n1p", expected: false},
+		{name: "mmcblk0p2", expected: true},
+		{name: "mmcblk01", expected: false},
+		{name: "sdb1", expected: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if actual := isPartition(tc.name, disks); actual != tc.expected {
+				t.Errorf("Expected %v, actual %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_parseDiskStats(t *testing.T) {
+	// The device mapper and the partitions use the sectors of the disks: only the disks are counted.
+	raw := `   8       0 sda 0 0 10 0 0 0 20 0 0 0 0 0 0 0 0 0 0
+   8       1 sda1 0 0 10 0 0 0 20 0 0 0 0 0 0 0 0 0 0
+  65     160 sdaa 0 0 100 0 0 0 200 0 0 0 0 0 0 0 0 0 0
+ 259       0 nvme0n1 0 0 1000 0 0 0 2000 0 0 0 0 0 0 0 0 0 0
+ 259       1 nvme0n1p1 0 0 1000 0 0 0 2000 0 0 0 0 0 0 0 0 0 0
+ 259       2 nvme0n10 0 0 10000 0 0 0 20000 0 0 0 0 0 0 0 0 0 0
+ 253       0 dm-0 0 0 1000 0 0 0 2000 0 0 0 0 0 0 0 0 0 0
+ 253      10 dm-10 0 0 10 0 0 0 20 0 0 0 0 0 0 0 0 0 0
+   7       0 loop0 0 0 10 0 0 0 20 0 0 0 0 0 0 0 0 0 0
+`
+
+	read, write := parseDiskStats(raw)
+	if expected := uint64(11110 * 512); read != expected {
+		t.Errorf("Expected %v, actual %v", expected, read)
+	}
+	if expected := uint64(22220 * 512); write != expected {
+		t.Errorf("Expected %v, actual %v", expected, write)
+	}
+}
@@ -789,3 +789,79 @@ func Test_HostBar(t *testing.T) {
 		})
 	}
 }
+
+func Test_HostDiskRate(t *testing.T) {
+	testCases := []struct {
+		name     string
+		expected string
+		runner   runnerFunc
+		wantErr  bool
+	}{
+		{
+			name:     "happy case",
+			expected: "41%",
+			runner: func(cmd string) (string, error) {
+				return "Filesystem     1024-blocks     Used Available Capacity Mounted on\n/dev/sda1        479597248 185263676 269899188      41% /\n", nil
+			},
+		},
+		{
+			name: "Only header",
+			runner: func(cmd string) (string, error) {
+				return "Filesystem     1024-blocks     Used Available Capacity Mounted on\n", nil
+			},
+			wantErr: true,
+		},
+		{
+			name:    "Runner return error",
+			runner:  func(cmd string) (string, error) { return "", errors.New("Error!") },
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := HostDiskRate(tc.runner, "/")
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Error '%v' even if wantErr is %t", err, tc.wantErr)
+				return
+			}
+
+			if tc.wantErr == false && actual != tc.expected {
+				t.Errorf("Expected %v, actual %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_HostFleet(t *testing.T) {
+	outputs := map[string]string{
+		"/bin/cat /proc/stat":    string(ReadFixtureFile("./testdata/fixtures/host_cpu", t)),
+		"/bin/cat /proc/meminfo": string(ReadFixtureFile("./testdata/fixtures/host_memory", t)),
+		"/bin/cat /proc/loadavg": "0.52 0.58 0.59 1/467 12345",
+		"/bin/cat /proc/uptime":  "266400.21 59425.48",
+		"/bin/df -P /":           "Filesystem 1024-blocks Used Available Capacity Mounted on\n/dev/sda1 479597248 185263676 269899188 41% /",
+	}
+
+	runner := func(host string) (func(cmd string) (string, error), error) {
+		switch host {
+		case "unreachable":
+			return nil, errors.New("connection refused")
+		case "broken":
+			return func(cmd string) (string, error) { return "", errors.New("command not found") }, nil
+		}
+
+		return func(cmd string) (string, error) { return outputs[cmd], nil }, nil
+	}
+
+	expected := [][]string{
+		{"host", "cpu", "memory", "load", "disk", "uptime"},
+		{"web1", "11.97 %", "82.29 %", "0.52 0.58 0.59", "41%", "3d"},
+		{"unreachable", "connection refused", "", "", "", ""},
+		{"broken", "command not found", "", "", "", ""},
+	}
+
+	actual := HostFleet([]string{"web1", "unreachable", "broken"}, runner)
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Expected %v, actual %v", expected, actual)
+	}
+}
@@ -0,0 +1,131 @@
+package platform
+
+// metrics exposes the numeric values of the widgets in the Prometheus text format.
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Sample of a metric with its labels.
+type Sample struct {
+	Labels map[string]string
+	Value  float64
+}
+
+var numberRegexp = regexp.MustCompile(`-?[0-9]+(\.[0-9]+)?`)
+
+// Samples of the numeric values of a widget.
+// A box has a value only if its text has exactly one number (for example "online (200)" or "42").
+// The boxes displaying an error don't have any value, even if their message has a number (like "status 500").
+// Every dimension of a bar chart is a sample, and every series of a stacked bar chart too.
+// The sparklines and the line charts display a history: only their newest value is a sample.
+// Tables don't have any numeric value.
+func (wi SnapshotWidget) Samples() []Sample {
+	samples := []Sample{}
+	switch wi.Type {
+	case SnapshotBox:
+		if strings.TrimSpace(wi.Title) == ErrorTitle {
+			break
+		}
+		n := numberRegexp.FindAllString(wi.Text, -1)
+		if len(n) != 1 {
+			break
+		}
+		if v, err := strconv.ParseFloat(n[0], 64); err == nil {
+			samples = append(samples, Sample{Labels: map[string]string{}, Value: v})
+		}
+	case SnapshotGauge:
+		samples = append(samples, Sample{Labels: map[string]string{}, Value: *wi.Value})
+	case SnapshotBar:
+		for k, d := range wi.Dimensions {
+			if k < len(wi.Values) {
+				samples = append(samples, Sample{
+					Labels: map[string]string{"dimension": d},
+					Value:  float64(wi.Values[k]),
+				})
+			}
+		}
+	case SnapshotStackedBar:
+		for s, values := range wi.Series {
+			for k, d := range wi.Dimensions {
+				if k < len(values) {
+					samples = append(samples, Sample{
+						Labels: map[string]string{"dimension": d, "series": strconv.Itoa(s + 1)},
+						Value:  float64(values[k]),
+					})
+				}
+			}
+		}
+	case SnapshotSparkline:
+		for k, values := range wi.Series {
+			if len(values) == 0 {
+				continue
+			}
+			labels := map[string]string{}
+			if k < len(wi.Dimensions) {
+				labels["series"] = strings.TrimSpace(wi.Dimensions[k])
+			}
+			samples = append(samples, Sample{Labels: labels, Value: float64(values[len(values)-1])})
+		}
+	case SnapshotLine:
+		if len(wi.Points) > 0 {
+			samples = append(samples, Sample{Labels: map[string]string{}, Value: wi.Points[len(wi.Points)-1]})
+		}
+	}
+
+	title := strings.TrimSpace(wi.Title)
+	for _, s := range samples {
+		s.Labels["title"] = title
+	}
+
+	return samples
+}
+
+// WriteMetrics writes the samples of the gauge name in the Prometheus text format, sorted by labels.
+func WriteMetrics(w io.Writer, name string, help string, samples []Sample) error {
+	lines := make([]string, 0, len(samples))
+	for _, s := range samples {
+		lines = append(lines, name+formatLabels(s.Labels)+" "+strconv.FormatFloat(s.Value, 'g', -1, 64))
+	}
+	sort.Strings(lines)
+
+	_, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n", name, help, name)
+	if err != nil {
+		return err
+	}
+
+	for _, l := range lines {
+		if _, err := fmt.Fprintln(w, l); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func formatLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	l := make([]string, 0, len(keys))
+	for _, k := range keys {
+		l = append(l, fmt.Sprintf(`%s="%s"`, k, labelEscaper.Replace(labels[k])))
+	}
+
+	return "{" + strings.Join(l, ",") + "}"
+}
+
+// labelEscaper escapes the label values as required by the Prometheus text format.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
@@ -0,0 +1,88 @@
+package platform
+
+import (
+	"bytes"
+	"testing"
+)
+
+func Test_SnapshotWidgetSamples(t *testing.T) {
+	value := 87.5
+	testCases := []struct {
+		name     string
+		expected string
+		widget   SnapshotWidget
+	}{
+		{
+			name:     "box with a number",
+			expected: "devdash{title=\"Availability\"} 200\n",
+			widget:   SnapshotWidget{Type: SnapshotBox, Title: " Availability ", Text: "online (200)"},
+		},
+		{
+			name:     "box with more than one number",
+			expected: "",
+			widget:   SnapshotWidget{Type: SnapshotBox, Title: " Uptime ", Text: "35m 49s"},
+		},
+		{
+			name:     "gauge",
+			expected: "devdash{title=\"Coverage\"} 87.5\n",
+			widget:   SnapshotWidget{Type: SnapshotGauge, Title: "Coverage", Value: &value},
+		},
+		{
+			name: "bar",
+			expected: "devdash{dimension=\"Mon\",title=\"Commits\"} 3\n" +
+				"devdash{dimension=\"Tue\",title=\"Commits\"} 5\n",
+			widget: SnapshotWidget{Type: SnapshotBar, Title: "Commits", Dimensions: []string{"Mon", "Tue"}, Values: []int{3, 5}},
+		},
+		{
+			name: "stacked bar",
+			expected: "devdash{dimension=\"Mon\",series=\"1\",title=\"Issues\"} 1\n" +
+				"devdash{dimension=\"Mon\",series=\"2\",title=\"Issues\"} 2\n",
+			widget: SnapshotWidget{Type: SnapshotStackedBar, Title: "Issues", Dimensions: []string{"Mon"}, Series: [][]int{{1}, {2}}},
+		},
+		{
+			name:     "table",
+			expected: "",
+			widget:   SnapshotWidget{Type: SnapshotTable, Title: "Branches", Rows: [][]string{{"master", "42"}}},
+		},
+		{
+			name:     "error box",
+			expected: "",
+			widget:   SnapshotWidget{Type: SnapshotBox, Title: " ERROR ", Text: "status 500"},
+		},
+		{
+			name: "sparklines",
+			expected: "devdash{series=\"rx\",title=\"Network\"} 7\n" +
+				"devdash{series=\"tx\",title=\"Network\"} 2\n",
+			widget: SnapshotWidget{Type: SnapshotSparkline, Title: "Network", Dimensions: []string{"rx", "tx"}, Series: [][]int{{3, 7}, {1, 2}}},
+		},
+		{
+			name:     "line",
+			expected: "devdash{title=\"CPU\"} 42.5\n",
+			widget:   SnapshotWidget{Type: SnapshotLine, Title: "CPU", Dimensions: []string{"10:00:00", "10:00:05"}, Points: []float64{12, 42.5}},
+		},
+		{
+			name:     "empty line",
+			expected: "",
+			widget:   SnapshotWidget{Type: SnapshotLine, Title: "CPU"},
+		},
+		{
+			name:     "escaped label",
+			expected: "devdash{title=\"\\\"Stars\\\" \\\\ all\\nrepositories\"} 42\n",
+			widget:   SnapshotWidget{Type: SnapshotBox, Title: "\"Stars\" \\ all\nrepositories", Text: "42"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var b bytes.Buffer
+			if err := WriteMetrics(&b, "devdash", "help", tc.widget.Samples()); err != nil {
+				t.Fatal(err)
+			}
+
+			expected := "# HELP devdash help\n# TYPE devdash gauge\n" + tc.expected
+			if b.String() != expected {
+				t.Errorf("Expected %v, actual %v", expected, b.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package platform
+
+import (
+	"fmt"
+
+	goping "github.com/go-ping/ping"
+)
+
+// Ping the host once and return the statistics of the ping.
+func Ping(host string) (string, error) {
+	return recordCommand(host, "ping", func() (string, error) {
+		pinger, err := goping.NewPinger(host)
+		if err != nil {
+			return "", err
+		}
+		pinger.Count = 1
+		pinger.Run()                 // blocks until finished
+		stats := pinger.Statistics() // get send/receive/rtt stats
+
+		return fmt.Sprintf("Sent: %d / Received: %d / Time: %d", stats.PacketsSent, stats.PacketsRecv, stats.AvgRtt), nil
+	})
+}
@@ -0,0 +1,164 @@
+package platform
+
+// plugin run an external executable to get the data of a widget.
+// The widget (name and options) is sent as JSON to the standard input of the executable.
+// The executable writes the data to display as JSON on its standard output.
+// Example of output: {"type": "bar", "title": "Deploys", "dimensions": ["mon", "tue"], "values": [3, 5]}
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	PluginBox        = "box"
+	PluginGauge      = "gauge"
+	PluginBar        = "bar"
+	PluginStackedBar = "bar_stacked"
+	PluginTable      = "table"
+
+	pluginDefaultTimeout = 30 * time.Second
+	pluginMaxSeries      = 8
+)
+
+// Plugin executes the executables of the plugins.
+type Plugin struct {
+	dir     string
+	timeout time.Duration
+}
+
+// PluginRequest sent to the standard input of the executable.
+type PluginRequest struct {
+	Name    string            `json:"name"`
+	Options map[string]string `json:"options"`
+}
+
+// PluginResponse read from the standard output of the executable.
+type PluginResponse struct {
+	Type       string         `json:"type"`
+	Title      string         `json:"title"`
+	Text       string         `json:"text"`
+	Value      float64        `json:"value"`
+	Dimensions []string       `json:"dimensions"`
+	Values     []int          `json:"values"`
+	Series     []PluginSeries `json:"series"`
+	Rows       [][]string     `json:"rows"`
+	Error      string         `json:"error"`
+}
+
+// PluginSeries is one dataset of a stacked bar.
+type PluginSeries struct {
+	Name   string `json:"name"`
+	Color  string `json:"color"`
+	Values []int  `json:"values"`
+}
+
+// NewPlugin with the directory where to find the executables and the timeout of each execution.
+func NewPlugin(dir string, timeout time.Duration) *Plugin {
+	if timeout <= 0 {
+		timeout = pluginDefaultTimeout
+	}
+
+	return &Plugin{
+		dir:     dir,
+		timeout: timeout,
+	}
+}
+
+// Run the executable with its arguments and send it the request.
+func (p *Plugin) Run(executable string, args []string, req PluginRequest) (*PluginResponse, error) {
+	in, err := json.Marshal(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't encode the request for the plugin %s", executable)
+	}
+
+	command := strings.Join(append([]string{executable}, args...), " ") + "\n" + string(in)
+	out, err := recordCommand("plugin", command, func() (string, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), p.timeout)
+		defer cancel()
+
+		cmd := exec.CommandContext(ctx, p.lookPath(executable), args...)
+		cmd.Stdin = bytes.NewReader(in)
+
+		var stdout, stderr bytes.Buffer
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+
+		err := cmd.Run()
+		if ctx.Err() == context.DeadlineExceeded {
+			return "", errors.Errorf("plugin %s didn't answer after %s", executable, p.timeout)
+		}
+		if err != nil {
+			if msg := strings.TrimSpace(stderr.String()); msg != "" {
+				return "", errors.Wrapf(err, "plugin %s failed: %s", executable, msg)
+			}
+			return "", errors.Wrapf(err, "plugin %s failed", executable)
+		}
+
+		return stdout.String(), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return decodePluginResponse([]byte(out))
+}
+
+// lookPath of the executable. Look first in the plugin directory, then use the executable as it is.
+func (p *Plugin) lookPath(executable string) string {
+	if p.dir == "" || filepath.IsAbs(executable) || strings.ContainsRune(executable, filepath.Separator) {
+		return executable
+	}
+
+	path := filepath.Join(p.dir, executable)
+	if _, err := os.Stat(path); err == nil {
+		return path
+	}
+
+	return executable
+}
+
+func decodePluginResponse(data []byte) (*PluginResponse, error) {
+	resp := &PluginResponse{}
+	if err := json.Unmarshal(data, resp); err != nil {
+		return nil, errors.Wrapf(err, "can't decode the plugin output %q", string(data))
+	}
+
+	if resp.Error != "" {
+		return nil, errors.New(resp.Error)
+	}
+
+	switch resp.Type {
+	case PluginBox, PluginGauge, "":
+	case PluginTable:
+		// termui can't draw rows longer than the header.
+		if len(resp.Rows) == 0 {
+			return nil, errors.New("plugin table needs at least one row, the header")
+		}
+		for k, r := range resp.Rows {
+			if len(r) != len(resp.Rows[0]) {
+				return nil, errors.Errorf("plugin table needs rows as long as the header (%d columns), row %d has %d columns", len(resp.Rows[0]), k, len(r))
+			}
+		}
+	case PluginBar:
+		if len(resp.Dimensions) != len(resp.Values) {
+			return nil, errors.Errorf("plugin bar needs as many dimensions as values, having %d dimensions and %d values", len(resp.Dimensions), len(resp.Values))
+		}
+	case PluginStackedBar:
+		if len(resp.Series) > pluginMaxSeries {
+			return nil, errors.Errorf("plugin stacked bar can't have more than %d series, having %d", pluginMaxSeries, len(resp.Series))
+		}
+	default:
+		return nil, errors.Errorf("unknown plugin output type %s", resp.Type)
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,144 @@
+package platform
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_decodePluginResponse(t *testing.T) {
+	testCases := []struct {
+		name        string
+		expected    *PluginResponse
+		fixtureFile string
+		data        string
+		wantErr     bool
+	}{
+		{
+			name: "bar",
+			expected: &PluginResponse{
+				Type:       PluginBar,
+				Title:      " Deploys ",
+				Dimensions: []string{"mon", "tue", "wed"},
+				Values:     []int{3, 5, 1},
+			},
+			fixtureFile: "./testdata/fixtures/plugin_bar.json",
+		},
+		{
+			name: "stacked bar",
+			expected: &PluginResponse{
+				Type:       PluginStackedBar,
+				Dimensions: []string{"mon", "tue"},
+				Series: []PluginSeries{
+					{Name: "success", Color: "green", Values: []int{3, 5}},
+					{Name: "failure", Color: "red", Values: []int{1, 0}},
+				},
+			},
+			fixtureFile: "./testdata/fixtures/plugin_stacked_bar.json",
+		},
+		{
+			name:    "error returned by the plugin",
+			data:    `{"error": "can't reach the API"}`,
+			wantErr: true,
+		},
+		{
+			name:    "unknown type",
+			data:    `{"type": "pie"}`,
+			wantErr: true,
+		},
+		{
+			name:    "bar with missing dimensions",
+			data:    `{"type": "bar", "dimensions": ["mon"], "values": [1, 2]}`,
+			wantErr: true,
+		},
+		{
+			name: "table",
+			expected: &PluginResponse{
+				Type: PluginTable,
+				Rows: [][]string{{"service", "status"}, {"api", "up"}},
+			},
+			data: `{"type": "table", "rows": [["service", "status"], ["api", "up"]]}`,
+		},
+		{
+			name:    "table without rows",
+			data:    `{"type": "table", "rows": []}`,
+			wantErr: true,
+		},
+		{
+			name:    "table with a row longer than the header",
+			data:    `{"type": "table", "rows": [["service", "status"], ["api", "up", "eu"]]}`,
+			wantErr: true,
+		},
+		{
+			name:    "table with a row shorter than the header",
+			data:    `{"type": "table", "rows": [["service", "status"], ["api"]]}`,
+			wantErr: true,
+		},
+		{
+			name:    "not JSON",
+			data:    `42 deploys`,
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			data := []byte(tc.data)
+			if tc.fixtureFile != "" {
+				data = ReadFixtureFile(tc.fixtureFile, t)
+			}
+
+			actual, err := decodePluginResponse(data)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Error '%v' even if wantErr is %t", err, tc.wantErr)
+				return
+			}
+
+			if tc.wantErr == false && !reflect.DeepEqual(tc.expected, actual) {
+				t.Errorf("Expected %v, actual %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_PluginRun(t *testing.T) {
+	testCases := []struct {
+		name       string
+		expected   *PluginResponse
+		executable string
+		wantErr    bool
+	}{
+		{
+			name: "executable found in the plugin directory",
+			expected: &PluginResponse{
+				Type:  PluginBox,
+				Title: "echo",
+				Text:  `{"name":"plugin.box","options":{"title":"Echo"}}`,
+			},
+			executable: "echo",
+		},
+		{
+			name:       "executable not found",
+			executable: "./testdata/plugins/unknown",
+			wantErr:    true,
+		},
+	}
+
+	p := NewPlugin("./testdata/plugins", time.Second)
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := p.Run(tc.executable, nil, PluginRequest{
+				Name:    "plugin.box",
+				Options: map[string]string{"title": "Echo"},
+			})
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Error '%v' even if wantErr is %t", err, tc.wantErr)
+				return
+			}
+
+			if tc.wantErr == false && !reflect.DeepEqual(tc.expected, actual) {
+				t.Errorf("Expected %v, actual %v", tc.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,320 @@
+package platform
+
+// prometheus runs PromQL queries against the HTTP API of a Prometheus server.
+// See https://prometheus.io/docs/prometheus/latest/querying/api/
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	promVector = "vector"
+	promMatrix = "matrix"
+	promScalar = "scalar"
+)
+
+// Prometheus client.
+type Prometheus struct {
+	address string
+	token   string
+	client  *http.Client
+}
+
+type promResponse struct {
+	Status    string `json:"status"`
+	ErrorType string `json:"errorType"`
+	Error     string `json:"error"`
+	Data      struct {
+		ResultType string          `json:"resultType"`
+		Result     json.RawMessage `json:"result"`
+	} `json:"data"`
+}
+
+// PromSample of an instant vector.
+type PromSample struct {
+	Metric map[string]string `json:"metric"`
+	Value  promValue         `json:"value"`
+}
+
+// PromSeries of a range vector.
+type PromSeries struct {
+	Metric map[string]string `json:"metric"`
+	Values []promValue       `json:"values"`
+}
+
+// promValue is a pair [timestamp, "value"].
+type promValue struct {
+	Time  time.Time
+	Value float64
+}
+
+func (v *promValue) UnmarshalJSON(data []byte) error {
+	var pair []interface{}
+	if err := json.Unmarshal(data, &pair); err != nil {
+		return err
+	}
+
+	if len(pair) != 2 {
+		return errors.Errorf("unexpected value %s, needs to be [timestamp, value]", string(data))
+	}
+
+	ts, ok := pair[0].(float64)
+	if !ok {
+		return errors.Errorf("unexpected timestamp %v", pair[0])
+	}
+
+	s, ok := pair[1].(string)
+	if !ok {
+		return errors.Errorf("unexpected value %v", pair[1])
+	}
+
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return err
+	}
+
+	sec, dec := math.Modf(ts)
+	v.Time = time.Unix(int64(sec), int64(dec*1e9))
+	v.Value = f
+
+	return nil
+}
+
+// NewPrometheus client for the server at address. The token is optional.
+func NewPrometheus(address string, token string) *Prometheus {
+	return &Prometheus{
+		address: strings.TrimSuffix(address, "/"),
+		token:   token,
+		client:  cachedHTTPClient("prometheus "+token, &http.Client{}),
+	}
+}
+
+// Query the current instant vector of a PromQL expression.
+// A scalar result is returned as a vector with one sample without labels.
+func (p *Prometheus) Query(query string) ([]PromSample, error) {
+	params := url.Values{}
+	params.Set("query", query)
+
+	resp, err := p.get("query", params)
+	if err != nil {
+		return nil, err
+	}
+
+	switch resp.Data.ResultType {
+	case promVector:
+		samples := []PromSample{}
+		if err := json.Unmarshal(resp.Data.Result, &samples); err != nil {
+			return nil, errors.Wrapf(err, "can't read the result of the query %s", query)
+		}
+		return samples, nil
+	case promScalar:
+		v := promValue{}
+		if err := json.Unmarshal(resp.Data.Result, &v); err != nil {
+			return nil, errors.Wrapf(err, "can't read the result of the query %s", query)
+		}
+		return []PromSample{{Metric: map[string]string{}, Value: v}}, nil
+	default:
+		return nil, errors.Errorf("the query %s returns a %s, needs to return a vector or a scalar", query, resp.Data.ResultType)
+	}
+}
+
+// QueryRange returns the range vector of a PromQL expression, with one value per step.
+func (p *Prometheus) QueryRange(query string, start, end time.Time, step time.Duration) ([]PromSeries, error) {
+	params := url.Values{}
+	params.Set("query", query)
+	params.Set("start", formatPromTime(start))
+	params.Set("end", formatPromTime(end))
+	params.Set("step", strconv.FormatFloat(step.Seconds(), 'f', -1, 64))
+
+	resp, err := p.get("query_range", params)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.Data.ResultType != promMatrix {
+		return nil, errors.Errorf("the query %s returns a %s, needs to return a matrix", query, resp.Data.ResultType)
+	}
+
+	series := []PromSeries{}
+	if err := json.Unmarshal(resp.Data.Result, &series); err != nil {
+		return nil, errors.Wrapf(err, "can't read the result of the query %s", query)
+	}
+
+	return series, nil
+}
+
+func (p *Prometheus) get(endpoint string, params url.Values) (*promResponse, error) {
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/api/v1/%s?%s", p.address, endpoint, params.Encode()), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.token != "" {
+		req.Header.Set("Authorization", "Bearer "+p.token)
+	}
+
+	res, err := p.client.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "error while fetching prometheus API")
+	}
+	defer res.Body.Close()
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "error while reading prometheus API response")
+	}
+
+	resp := &promResponse{}
+	if err := json.Unmarshal(body, resp); err != nil {
+		return nil, errors.Wrapf(err, "unexpected prometheus API response with status %d", res.StatusCode)
+	}
+
+	if resp.Status != "success" {
+		return nil, errors.Errorf("prometheus API error %s: %s", resp.ErrorType, resp.Error)
+	}
+
+	return resp, nil
+}
+
+// Value of the first sample returned by the query.
+func (p *Prometheus) Value(query string) (float64, error) {
+	samples, err := p.Query(query)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(samples) == 0 {
+		return 0, errors.Errorf("the query %s doesn't return any value", query)
+	}
+
+	return samples[0].Value.Value, nil
+}
+
+// Bar returns the values of the query for each time period between start and end.
+// The values of every series are added together.
+func (p *Prometheus) Bar(query string, start, end time.Time, timePeriod string) ([]string, []int, error) {
+	step, layout, err := promStep(timePeriod)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	series, err := p.QueryRange(query, start, end, step)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dim, val := formatPromBar(series, layout)
+
+	return dim, val, nil
+}
+
+// Table of the samples returned by the query: the labels given and the value, sorted by value.
+// Every label is displayed if no label is given.
+func (p *Prometheus) Table(query string, labels []string, limit int64) ([][]string, error) {
+	samples, err := p.Query(query)
+	if err != nil {
+		return nil, err
+	}
+
+	return formatPromTable(samples, labels, limit), nil
+}
+
+func formatPromBar(series []PromSeries, layout string) ([]string, []int) {
+	sums := map[int64]float64{}
+	for _, s := range series {
+		for _, v := range s.Values {
+			sums[v.Time.Unix()] += v.Value
+		}
+	}
+
+	times := make([]int64, 0, len(sums))
+	for t := range sums {
+		times = append(times, t)
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+
+	dim := make([]string, 0, len(times))
+	val := make([]int, 0, len(times))
+	for _, t := range times {
+		dim = append(dim, time.Unix(t, 0).Format(layout))
+		val = append(val, int(math.Round(sums[t])))
+	}
+
+	return dim, val
+}
+
+func formatPromTable(samples []PromSample, labels []string, limit int64) [][]string {
+	if len(labels) == 0 {
+		set := map[string]bool{}
+		for _, s := range samples {
+			for l := range s.Metric {
+				set[l] = true
+			}
+		}
+		for l := range set {
+			labels = append(labels, l)
+		}
+		sort.Strings(labels)
+	}
+
+	sort.SliceStable(samples, func(i, j int) bool {
+		return samples[i].Value.Value > samples[j].Value.Value
+	})
+
+	table := [][]string{append(append([]string{}, labels...), "Value")}
+	for k, s := range samples {
+		if int64(k) >= limit {
+			break
+		}
+
+		row := make([]string, 0, len(labels)+1)
+		for _, l := range labels {
+			row = append(row, s.Metric[l])
+		}
+		row = append(row, strconv.FormatFloat(s.Value.Value, 'f', -1, 64))
+		table = append(table, row)
+	}
+
+	return table
+}
+
+// promStep returns the step of a range query and the layout of its dimensions for a time period.
+// The time period can be a duration too, like "6h".
+func promStep(timePeriod string) (time.Duration, string, error) {
+	switch timePeriod {
+	case "hour":
+		return time.Hour, "15:04", nil
+	case "day":
+		return 24 * time.Hour, "01-02", nil
+	case "week":
+		return 7 * 24 * time.Hour, "01-02", nil
+	case "month":
+		return 30 * 24 * time.Hour, "Jan", nil
+	}
+
+	step, err := time.ParseDuration(timePeriod)
+	if err != nil || step <= 0 {
+		return 0, "", errors.Errorf("time period %s must be hour, day, week, month or a duration (like 6h)", timePeriod)
+	}
+
+	if step < 24*time.Hour {
+		return step, "15:04", nil
+	}
+
+	return step, "01-02", nil
+}
+
+func formatPromTime(t time.Time) string {
+	return strconv.FormatFloat(float64(t.UnixNano())/1e9, 'f', 3, 64)
+}
@@ -0,0 +1,200 @@
+package platform
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// promServer serves the fixture file for every query.
+func promServer(fixtureFile string, t *testing.T) *httptest.Server {
+	fixtures := ReadFixtureFile(fixtureFile, t)
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/query" && r.URL.Path != "/api/v1/query_range" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		if r.URL.Query().Get("query") == "" {
+			t.Errorf("Missing query in %s", r.URL.String())
+		}
+		if r.Header.Get("Authorization") != "Bearer token" {
+			t.Errorf("Unexpected authorization header %s", r.Header.Get("Authorization"))
+		}
+
+		w.Write(fixtures)
+	}))
+}
+
+func Test_PrometheusValue(t *testing.T) {
+	testCases := []struct {
+		name        string
+		expected    float64
+		fixtureFile string
+		wantErr     bool
+	}{
+		{
+			name:        "vector",
+			expected:    1,
+			fixtureFile: "./testdata/fixtures/prom_vector.json",
+		},
+		{
+			name:        "scalar",
+			expected:    42.5,
+			fixtureFile: "./testdata/fixtures/prom_scalar.json",
+		},
+		{
+			name:        "matrix",
+			fixtureFile: "./testdata/fixtures/prom_matrix.json",
+			wantErr:     true,
+		},
+		{
+			name:        "error",
+			fixtureFile: "./testdata/fixtures/prom_error.json",
+			wantErr:     true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := promServer(tc.fixtureFile, t)
+			defer server.Close()
+
+			actual, err := NewPrometheus(server.URL, "token").Value("up")
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Error '%v' even if wantErr is %t", err, tc.wantErr)
+				return
+			}
+
+			if tc.wantErr == false && actual != tc.expected {
+				t.Errorf("Expected %v, actual %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_PrometheusBar(t *testing.T) {
+	testCases := []struct {
+		name        string
+		expectedDim []string
+		expectedVal []int
+		timePeriod  string
+		fixtureFile string
+		wantErr     bool
+	}{
+		{
+			name: "values of series added together",
+			expectedDim: []string{
+				time.Unix(1577836800, 0).Format("01-02"),
+				time.Unix(1577923200, 0).Format("01-02"),
+				time.Unix(1578009600, 0).Format("01-02"),
+			},
+			expectedVal: []int{11, 22, 3},
+			timePeriod:  "day",
+			fixtureFile: "./testdata/fixtures/prom_matrix.json",
+		},
+		{
+			name: "duration as time period",
+			expectedDim: []string{
+				time.Unix(1577836800, 0).Format("15:04"),
+				time.Unix(1577923200, 0).Format("15:04"),
+				time.Unix(1578009600, 0).Format("15:04"),
+			},
+			expectedVal: []int{11, 22, 3},
+			timePeriod:  "6h",
+			fixtureFile: "./testdata/fixtures/prom_matrix.json",
+		},
+		{
+			name:        "wrong time period",
+			timePeriod:  "fortnight",
+			fixtureFile: "./testdata/fixtures/prom_matrix.json",
+			wantErr:     true,
+		},
+		{
+			name:        "vector",
+			timePeriod:  "day",
+			fixtureFile: "./testdata/fixtures/prom_vector.json",
+			wantErr:     true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := promServer(tc.fixtureFile, t)
+			defer server.Close()
+
+			end := time.Unix(1578009600, 0)
+			dim, val, err := NewPrometheus(server.URL, "token").Bar("up", end.AddDate(0, 0, -2), end, tc.timePeriod)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Error '%v' even if wantErr is %t", err, tc.wantErr)
+				return
+			}
+
+			if tc.wantErr == false && !reflect.DeepEqual(tc.expectedDim, dim) {
+				t.Errorf("Expected %v, actual %v", tc.expectedDim, dim)
+			}
+
+			if tc.wantErr == false && !reflect.DeepEqual(tc.expectedVal, val) {
+				t.Errorf("Expected %v, actual %v", tc.expectedVal, val)
+			}
+		})
+	}
+}
+
+func Test_PrometheusTable(t *testing.T) {
+	testCases := []struct {
+		name        string
+		expected    [][]string
+		labels      []string
+		limit       int64
+		fixtureFile string
+		wantErr     bool
+	}{
+		{
+			name: "labels given",
+			expected: [][]string{
+				{"job", "Value"},
+				{"devdash", "2.5"},
+				{"prometheus", "1"},
+			},
+			labels:      []string{"job"},
+			limit:       2,
+			fixtureFile: "./testdata/fixtures/prom_vector.json",
+		},
+		{
+			name: "every label",
+			expected: [][]string{
+				{"__name__", "instance", "job", "Value"},
+				{"up", "localhost:8080", "devdash", "2.5"},
+				{"up", "localhost:9090", "prometheus", "1"},
+				{"up", "localhost:9100", "node", "0"},
+			},
+			limit:       5,
+			fixtureFile: "./testdata/fixtures/prom_vector.json",
+		},
+		{
+			name:        "error",
+			limit:       5,
+			fixtureFile: "./testdata/fixtures/prom_error.json",
+			wantErr:     true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := promServer(tc.fixtureFile, t)
+			defer server.Close()
+
+			actual, err := NewPrometheus(server.URL, "token").Table("up", tc.labels, tc.limit)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Error '%v' even if wantErr is %t", err, tc.wantErr)
+				return
+			}
+
+			if tc.wantErr == false && !reflect.DeepEqual(tc.expected, actual) {
+				t.Errorf("Expected %v, actual %v", tc.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package platform
+
+// ratelimit tracks the rate limit of an API from the headers X-RateLimit-* of its responses.
+// An API can have a rate limit for each of its resources (like core, search and graphql for Github):
+// each resource is tracked on its own, given by the header X-RateLimit-Resource.
+// When the limit of a resource is exceeded, no request to this resource is sent till the reset:
+// the cache serves the last responses known meanwhile.
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	headerRateLimit     = "X-RateLimit-Limit"
+	headerRateRemaining = "X-RateLimit-Remaining"
+	headerRateReset     = "X-RateLimit-Reset"
+	headerRateResource  = "X-RateLimit-Resource"
+	headerRetryAfter    = "Retry-After"
+)
+
+// RateLimits of the resources of an API, shared by every client using the same credentials.
+type RateLimits struct {
+	mu        sync.Mutex
+	resources map[string]*RateLimit
+	// resource requested, known before sending the request.
+	resource func(req *http.Request) string
+}
+
+// NewRateLimits with a function giving the resource of each request.
+func NewRateLimits(resource func(req *http.Request) string) *RateLimits {
+	return &RateLimits{
+		resources: map[string]*RateLimit{},
+		resource:  resource,
+	}
+}
+
+// Get the rate limit of the resource.
+func (r *RateLimits) Get(resource string) *RateLimit {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.resources[resource]; !ok {
+		r.resources[resource] = &RateLimit{}
+	}
+
+	return r.resources[resource]
+}
+
+// RateLimit of a resource of an API.
+type RateLimit struct {
+	mu        sync.Mutex
+	limit     int
+	remaining int
+	reset     time.Time
+	// retry is the end of the backoff asked by the API, even if requests remain.
+	retry time.Time
+}
+
+// RateLimitError is returned instead of sending a request when the rate limit is exceeded.
+type RateLimitError struct {
+	Reset time.Time
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("rate limit exceeded, no request till %s", e.Reset.Format("15:04:05"))
+}
+
+// State of the rate limit. The limit is 0 if no response has been received yet.
+func (r *RateLimit) State() (limit int, remaining int, reset time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.limit, r.remaining, r.reset
+}
+
+// exceeded returns the time when requests can be sent again, if the limit is exceeded.
+func (r *RateLimit) exceeded(now time.Time) (time.Time, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if now.Before(r.retry) {
+		return r.retry, true
+	}
+
+	if r.limit > 0 && r.remaining == 0 && now.Before(r.reset) {
+		return r.reset, true
+	}
+
+	return time.Time{}, false
+}
+
+// update the rate limit from the headers of a response.
+// It returns true if the response is a refusal because of the rate limit.
+func (r *RateLimit) update(resp *http.Response, now time.Time) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	h := resp.Header
+	if v, err := strconv.Atoi(h.Get(headerRateLimit)); err == nil {
+		r.limit = v
+	}
+	if v, err := strconv.Atoi(h.Get(headerRateRemaining)); err == nil {
+		r.remaining = v
+	}
+	if v, err := strconv.ParseInt(h.Get(headerRateReset), 10, 64); err == nil {
+		r.reset = time.Unix(v, 0)
+	}
+
+	refused := resp.StatusCode == http.StatusTooManyRequests ||
+		(resp.StatusCode == http.StatusForbidden && (h.Get(headerRateRemaining) == "0" || h.Get(headerRetryAfter) != ""))
+	if !refused {
+		return false
+	}
+
+	// The secondary rate limits ask to wait, whatever the remaining requests.
+	if v, err := strconv.Atoi(h.Get(headerRetryAfter)); err == nil {
+		r.retry = now.Add(time.Duration(v) * time.Second)
+	} else if r.reset.After(now) {
+		r.retry = r.reset
+	} else {
+		r.retry = now.Add(time.Minute)
+	}
+
+	return true
+}
+
+// rateLimitTransport doesn't send the requests to a resource while its rate limit is exceeded.
+type rateLimitTransport struct {
+	rateLimits *RateLimits
+	base       http.RoundTripper
+	// exempt requests are always sent, like the ones fetching the rate limit itself.
+	exempt func(req *http.Request) bool
+}
+
+// RoundTrip sends the request if the rate limit of its resource allows it, and updates the rate limit with the response.
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	exempt := t.exempt != nil && t.exempt(req)
+	rl := t.rateLimits.Get(t.rateLimits.resource(req))
+	if reset, ok := rl.exceeded(time.Now()); ok && !exempt {
+		return nil, &RateLimitError{Reset: reset}
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// The API knows better which resource has been used.
+	if r := resp.Header.Get(headerRateResource); r != "" {
+		rl = t.rateLimits.Get(r)
+	}
+
+	if rl.update(resp, time.Now()) && !exempt {
+		resp.Body.Close()
+		reset, _ := rl.exceeded(time.Now())
+		return nil, &RateLimitError{Reset: reset}
+	}
+
+	return resp, nil
+}
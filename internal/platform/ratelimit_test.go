@@ -0,0 +1,308 @@
+package platform
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func Test_RateLimitUpdate(t *testing.T) {
+	now := time.Date(2020, 01, 01, 10, 00, 00, 00, time.UTC)
+	reset := now.Add(time.Hour)
+
+	testCases := []struct {
+		name             string
+		status           int
+		header           http.Header
+		expectedRefused  bool
+		expectedExceeded bool
+		expectedUntil    time.Time
+	}{
+		{
+			name:   "requests remaining",
+			status: http.StatusOK,
+			header: rateHeader(http.Header{
+				headerRateLimit:     {"5000"},
+				headerRateRemaining: {"10"},
+				headerRateReset:     {strconv.FormatInt(reset.Unix(), 10)},
+			}),
+		},
+		{
+			name:   "last request",
+			status: http.StatusOK,
+			header: rateHeader(http.Header{
+				headerRateLimit:     {"5000"},
+				headerRateRemaining: {"0"},
+				headerRateReset:     {strconv.FormatInt(reset.Unix(), 10)},
+			}),
+			expectedExceeded: true,
+			expectedUntil:    reset,
+		},
+		{
+			name:   "refused till the reset",
+			status: http.StatusForbidden,
+			header: rateHeader(http.Header{
+				headerRateLimit:     {"5000"},
+				headerRateRemaining: {"0"},
+				headerRateReset:     {strconv.FormatInt(reset.Unix(), 10)},
+			}),
+			expectedRefused:  true,
+			expectedExceeded: true,
+			expectedUntil:    reset,
+		},
+		{
+			name:   "secondary rate limit",
+			status: http.StatusForbidden,
+			header: rateHeader(http.Header{
+				headerRateLimit:     {"5000"},
+				headerRateRemaining: {"10"},
+				headerRetryAfter:    {"60"},
+			}),
+			expectedRefused:  true,
+			expectedExceeded: true,
+			expectedUntil:    now.Add(time.Minute),
+		},
+		{
+			name:   "forbidden without rate limit",
+			status: http.StatusForbidden,
+			header: http.Header{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rl := &RateLimit{}
+			refused := rl.update(&http.Response{StatusCode: tc.status, Header: tc.header}, now)
+			if tc.expectedRefused != refused {
+				t.Errorf("Expected %v, actual %v", tc.expectedRefused, refused)
+			}
+
+			until, exceeded := rl.exceeded(now)
+			if tc.expectedExceeded != exceeded {
+				t.Errorf("Expected %v, actual %v", tc.expectedExceeded, exceeded)
+			}
+
+			if !tc.expectedUntil.Equal(until) {
+				t.Errorf("Expected %v, actual %v", tc.expectedUntil, until)
+			}
+		})
+	}
+}
+
+// rateHeader with canonical keys.
+func rateHeader(values http.Header) http.Header {
+	h := http.Header{}
+	for k, v := range values {
+		h.Set(k, v[0])
+	}
+
+	return h
+}
+
+func Test_RateLimitStaleCache(t *testing.T) {
+	var hits int32
+	remaining := []string{"1", "0"}
+	reset := strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h := atomic.AddInt32(&hits, 1)
+		w.Header().Set(headerRateLimit, "2")
+		w.Header().Set(headerRateRemaining, remaining[h-1])
+		w.Header().Set(headerRateReset, reset)
+		fmt.Fprint(w, r.URL.Path)
+	}))
+	defer server.Close()
+
+	rls := NewRateLimits(githubResource)
+	rl := rls.Get(githubResourceCore)
+	// The stale responses are kept even without TTL.
+	client := NewCache(0, "").Client("github token", &http.Client{
+		Transport: &rateLimitTransport{rateLimits: rls, base: http.DefaultTransport},
+	})
+
+	testCases := []struct {
+		name     string
+		path     string
+		expected string
+		wantErr  bool
+	}{
+		{name: "first request", path: "/repos/devdash", expected: "/repos/devdash"},
+		{name: "last request", path: "/repos/termui", expected: "/repos/termui"},
+		{name: "stale response", path: "/repos/devdash", expected: "/repos/devdash"},
+		{name: "nothing to serve", path: "/repos/tview", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, err := client.Get(server.URL + tc.path)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Error '%v' even if wantErr is %t", err, tc.wantErr)
+			}
+
+			if tc.wantErr {
+				return
+			}
+			defer resp.Body.Close()
+
+			body, err := ioutil.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tc.expected != string(body) {
+				t.Errorf("Expected %v, actual %v", tc.expected, string(body))
+			}
+		})
+	}
+
+	if hits != 2 {
+		t.Errorf("Expected %v, actual %v", 2, hits)
+	}
+
+	if l, r, _ := rl.State(); l != 2 || r != 0 {
+		t.Errorf("Expected %v/%v, actual %v/%v", 0, 2, r, l)
+	}
+}
+
+func Test_RateLimitStaleCacheRefused(t *testing.T) {
+	var hits int32
+	reset := strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(headerRateLimit, "5000")
+		w.Header().Set(headerRateReset, reset)
+		if atomic.AddInt32(&hits, 1) == 1 {
+			w.Header().Set(headerRateRemaining, "10")
+			fmt.Fprint(w, "devdash")
+			return
+		}
+
+		// The rate limit is exceeded by another client using the same token.
+		w.Header().Set(headerRateRemaining, "0")
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	client := NewCache(0, "").Client("github token", &http.Client{
+		Transport: &rateLimitTransport{rateLimits: NewRateLimits(githubResource), base: http.DefaultTransport},
+	})
+
+	for _, expected := range []string{"devdash", "devdash"} {
+		resp, err := client.Get(server.URL + "/repos/devdash")
+		if err != nil {
+			t.Fatalf("Error '%v' even if wantErr is %t", err, false)
+		}
+
+		body, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if expected != string(body) {
+			t.Errorf("Expected %v, actual %v", expected, string(body))
+		}
+	}
+
+	if hits != 2 {
+		t.Errorf("Expected %v, actual %v", 2, hits)
+	}
+}
+
+func Test_RateLimitResources(t *testing.T) {
+	var hits int32
+	reset := strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		resource, remaining := "core", "4999"
+		if strings.HasPrefix(r.URL.Path, "/search/") {
+			resource, remaining = "search", "0"
+		}
+		w.Header().Set(headerRateResource, resource)
+		w.Header().Set(headerRateLimit, "5000")
+		w.Header().Set(headerRateRemaining, remaining)
+		w.Header().Set(headerRateReset, reset)
+		fmt.Fprint(w, r.URL.Path)
+	}))
+	defer server.Close()
+
+	rls := NewRateLimits(githubResource)
+	client := NewCache(time.Hour, "").Client("github token", &http.Client{
+		Transport: &rateLimitTransport{rateLimits: rls, base: http.DefaultTransport, exempt: githubRateLimitRequest},
+	})
+
+	testCases := []struct {
+		name    string
+		path    string
+		noCache bool
+		wantErr bool
+	}{
+		{name: "search exhausted", path: "/search/issues"},
+		{name: "search refused", path: "/search/issues?page=2", wantErr: true},
+		{name: "core still available", path: "/repos/devdash"},
+		{name: "rate limit not cached", path: "/rate_limit", noCache: true},
+		{name: "rate limit fetched again", path: "/rate_limit", noCache: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, server.URL+tc.path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tc.noCache {
+				req = req.WithContext(withoutCache(req.Context()))
+			}
+
+			resp, err := client.Do(req)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Error '%v' even if wantErr is %t", err, tc.wantErr)
+			}
+			if err == nil {
+				resp.Body.Close()
+			}
+		})
+	}
+
+	if hits != 4 {
+		t.Errorf("Expected %v, actual %v", 4, hits)
+	}
+
+	if l, r, _ := rls.Get(githubResourceCore).State(); l != 5000 || r != 4999 {
+		t.Errorf("Expected %v/%v, actual %v/%v", 4999, 5000, r, l)
+	}
+}
+
+func Test_githubQuotaTransport(t *testing.T) {
+	reset := strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10)
+	original := http.Header{}
+	original.Set(headerRateRemaining, "0")
+	original.Set(headerRateReset, reset)
+
+	tr := &githubQuotaTransport{base: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Header: original, Body: http.NoBody}, nil
+	})}
+
+	resp, err := tr.RoundTrip(httptest.NewRequest(http.MethodGet, "https://api.github.com/graphql", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if actual := resp.Header.Get(headerRateReset); actual != "" {
+		t.Errorf("Expected %v, actual %v", "", actual)
+	}
+
+	if actual := original.Get(headerRateReset); actual != reset {
+		t.Errorf("Expected %v, actual %v", reset, actual)
+	}
+}
+
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
@@ -0,0 +1,269 @@
+package platform
+
+// recorder saves every response of the platforms (HTTP responses and outputs of commands) in a directory.
+// These recordings can be replayed afterwards: the dashboard is then displayed without network, credentials or remote hosts.
+// The dates in the requests are often computed from the current time ("7_days_ago" for example):
+// they are replaced by their number of days from today in the keys of the recordings, to replay them another day.
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	recordMode = iota
+	replayMode
+)
+
+// Recorder records or replays the responses of the platforms.
+type Recorder struct {
+	dir  string
+	mode int
+	mu   sync.Mutex
+}
+
+// HTTPRecording is an HTTP response saved on disk.
+type HTTPRecording struct {
+	Method     string      `json:"method"`
+	URL        string      `json:"url"`
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header"`
+	Body       string      `json:"body"`
+}
+
+// CommandRecording is the output of a command saved on disk.
+type CommandRecording struct {
+	Target  string `json:"target"`
+	Command string `json:"command"`
+	Output  string `json:"output"`
+	Error   string `json:"error,omitempty"`
+}
+
+var (
+	// recordedDate matches the dates and the RFC 3339 times, even escaped in a URL.
+	recordedDate = regexp.MustCompile(`\d{4}-\d{2}-\d{2}(T\d{2}(:|%3A)\d{2}(:|%3A)\d{2}(\.\d+)?(Z|([+-]|%2B)\d{2}(:|%3A)\d{2}))?`)
+	// recordedUnixTime matches the Unix times of the Prometheus queries.
+	recordedUnixTime = regexp.MustCompile(`\b(start|end|time)=(\d{9,}(\.\d+)?)`)
+	unescapeTime     = strings.NewReplacer("%3A", ":", "%2B", "+")
+)
+
+// The recorder used by every platform. Nothing is recorded if nil.
+var recorder *Recorder
+
+// Record every response of the platforms in dir.
+func Record(dir string) {
+	recorder = &Recorder{dir: dir, mode: recordMode}
+}
+
+// Replay the responses recorded in dir instead of fetching them.
+func Replay(dir string) {
+	recorder = &Recorder{dir: dir, mode: replayMode}
+}
+
+// StopRecorder stops recording or replaying the responses.
+func StopRecorder() {
+	recorder = nil
+}
+
+func replaying() bool {
+	return recorder != nil && recorder.mode == replayMode
+}
+
+// recordedHTTPClient wraps the transport of the client with the recorder, if any.
+func recordedHTTPClient(client *http.Client) *http.Client {
+	if recorder == nil {
+		return client
+	}
+
+	base := client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	rc := *client
+	rc.Transport = &recordTransport{recorder: recorder, base: base}
+
+	return &rc
+}
+
+// RecordedHTTPClient returns a client recording or replaying its responses, without cache.
+func RecordedHTTPClient() *http.Client {
+	return recordedHTTPClient(&http.Client{})
+}
+
+// recordCommand records or replays the output of a command run on target.
+func recordCommand(target, command string, run func() (string, error)) (string, error) {
+	if recorder == nil {
+		return run()
+	}
+
+	return recorder.command(target, command, run)
+}
+
+func (r *Recorder) command(target, command string, run func() (string, error)) (string, error) {
+	path := r.path("cmd", target+"\n"+command)
+
+	if r.mode == replayMode {
+		rec := CommandRecording{}
+		if err := r.read(path, &rec); err != nil {
+			return "", errors.Wrapf(err, "no recording for the command %s on %s", command, target)
+		}
+		if rec.Error != "" {
+			return rec.Output, errors.New(rec.Error)
+		}
+		return rec.Output, nil
+	}
+
+	out, err := run()
+	rec := CommandRecording{
+		Target:  target,
+		Command: command,
+		Output:  out,
+	}
+	if err != nil {
+		rec.Error = err.Error()
+	}
+
+	if werr := r.write(path, rec); werr != nil {
+		return "", werr
+	}
+
+	return out, err
+}
+
+type recordTransport struct {
+	recorder *Recorder
+	base     http.RoundTripper
+}
+
+// RoundTrip records the response of the request, or replays it.
+func (t *recordTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	// Credentials are not part of the key: recordings can be replayed without them.
+	path := t.recorder.path("http", req.Method+"\n"+req.URL.String()+"\n"+string(body))
+
+	if t.recorder.mode == replayMode {
+		rec := HTTPRecording{}
+		if err := t.recorder.read(path, &rec); err != nil {
+			return nil, errors.Wrapf(err, "no recording for %s %s", req.Method, req.URL.String())
+		}
+
+		e := cacheEntry{StatusCode: rec.StatusCode, Header: rec.Header, Body: []byte(rec.Body)}
+		return e.response(req), nil
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	rec := HTTPRecording{
+		Method:     req.Method,
+		URL:        req.URL.String(),
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       string(data),
+	}
+	if err := t.recorder.write(path, rec); err != nil {
+		return nil, err
+	}
+
+	e := cacheEntry{StatusCode: rec.StatusCode, Header: rec.Header, Body: data}
+	return e.response(req), nil
+}
+
+// path of the recording: the key is hashed to get a valid filename.
+func (r *Recorder) path(kind, key string) string {
+	h := sha256.Sum256([]byte(recordKey(key, time.Now())))
+	return filepath.Join(r.dir, kind, hex.EncodeToString(h[:])+".json")
+}
+
+func (r *Recorder) read(path string, v interface{}) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, v)
+}
+
+func (r *Recorder) write(path string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return errors.Wrapf(err, "can't encode recording %s", path)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return errors.Wrapf(err, "can't create recording directory %s", filepath.Dir(path))
+	}
+
+	return ioutil.WriteFile(path, data, 0600)
+}
+
+// recordKey replaces the dates and times of the key by their number of days from now.
+// Two times of the same day give the same key.
+func recordKey(key string, now time.Time) string {
+	key = recordedDate.ReplaceAllStringFunc(key, func(d string) string {
+		t, err := time.ParseInLocation("2006-01-02", d, time.Local)
+		if len(d) > len("2006-01-02") {
+			t, err = time.Parse(time.RFC3339, unescapeTime.Replace(d))
+		}
+		if err != nil {
+			return d
+		}
+
+		return daysFrom(t, now)
+	})
+
+	return recordedUnixTime.ReplaceAllStringFunc(key, func(p string) string {
+		m := recordedUnixTime.FindStringSubmatch(p)
+		sec, err := strconv.ParseFloat(m[2], 64)
+		if err != nil {
+			return p
+		}
+
+		return m[1] + "=" + daysFrom(time.Unix(int64(sec), 0), now)
+	})
+}
+
+// daysFrom returns the number of days between now and t, like "{today-7}".
+func daysFrom(t time.Time, now time.Time) string {
+	t, now = t.In(time.Local), now.In(time.Local)
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	return fmt.Sprintf("{today%+d}", int(day.Sub(today).Hours()/24))
+}
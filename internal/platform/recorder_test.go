@@ -0,0 +1,247 @@
+package platform
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func Test_RecorderHTTP(t *testing.T) {
+	testCases := []struct {
+		name     string
+		expected string
+		record   string
+		replay   string
+		wantErr  bool
+	}{
+		{
+			name:     "replay recorded response",
+			expected: "/repos/devdash",
+			record:   "/repos/devdash",
+			replay:   "/repos/devdash",
+		},
+		{
+			name:    "no recording",
+			record:  "/repos/devdash",
+			replay:  "/repos/termui",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "devdash_recordings")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, r.URL.Path)
+			}))
+
+			client := &http.Client{Transport: &recordTransport{
+				recorder: &Recorder{dir: dir, mode: recordMode},
+				base:     http.DefaultTransport,
+			}}
+			resp, err := client.Get(server.URL + tc.record)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+
+			// The replay can't use the network.
+			server.Close()
+
+			client = &http.Client{Transport: &recordTransport{
+				recorder: &Recorder{dir: dir, mode: replayMode},
+				base:     http.DefaultTransport,
+			}}
+			resp, err = client.Get(server.URL + tc.replay)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Error '%v' even if wantErr is %t", err, tc.wantErr)
+				return
+			}
+
+			if tc.wantErr == false {
+				body, _ := ioutil.ReadAll(resp.Body)
+				resp.Body.Close()
+				if string(body) != tc.expected {
+					t.Errorf("Expected %v, actual %v", tc.expected, string(body))
+				}
+			}
+		})
+	}
+}
+
+func Test_RecorderCachedResponses(t *testing.T) {
+	dir, err := ioutil.TempDir("", "devdash_recordings")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, r.URL.Path)
+	}))
+
+	defer func(c *Cache, r *Recorder) {
+		httpCache = c
+		recorder = r
+	}(httpCache, recorder)
+
+	// The response is in the persisted cache before the recording.
+	UseCache(NewCache(time.Minute, filepath.Join(dir, "cache")))
+	get := func() (string, error) {
+		resp, err := cachedHTTPClient("github token", &http.Client{}).Get(server.URL + "/repos/devdash")
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		body, err := ioutil.ReadAll(resp.Body)
+		return string(body), err
+	}
+	if _, err := get(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The response served from the disk is recorded.
+	server.Close()
+	UseCache(NewCache(time.Minute, filepath.Join(dir, "cache")))
+	Record(filepath.Join(dir, "recordings"))
+	if _, err := get(); err != nil {
+		t.Fatal(err)
+	}
+
+	UseCache(NewCache(0, ""))
+	Replay(filepath.Join(dir, "recordings"))
+	actual, err := get()
+	if err != nil {
+		t.Errorf("Error '%v' even if wantErr is %t", err, false)
+	}
+
+	if expected := "/repos/devdash"; actual != expected {
+		t.Errorf("Expected %v, actual %v", expected, actual)
+	}
+}
+
+func Test_RecorderCommand(t *testing.T) {
+	testCases := []struct {
+		name     string
+		expected string
+		output   string
+		err      error
+		target   string
+		command  string
+		wantErr  bool
+	}{
+		{
+			name:     "replay recorded output",
+			expected: "0.01 0.02 0.03 1/120 4242",
+			output:   "0.01 0.02 0.03 1/120 4242",
+			target:   "user@example.com",
+			command:  "/bin/cat /proc/loadavg",
+		},
+		{
+			name:    "replay recorded error",
+			err:     errors.New("can't run command"),
+			target:  "user@example.com",
+			command: "/bin/cat /proc/loadavg",
+			wantErr: true,
+		},
+		{
+			name:    "no recording",
+			output:  "0.01 0.02 0.03 1/120 4242",
+			target:  "user@example.com",
+			command: "/bin/cat /proc/uptime",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "devdash_recordings")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+
+			r := &Recorder{dir: dir, mode: recordMode}
+			r.command(tc.target, "/bin/cat /proc/loadavg", func() (string, error) {
+				return tc.output, tc.err
+			})
+
+			r = &Recorder{dir: dir, mode: replayMode}
+			actual, err := r.command(tc.target, tc.command, func() (string, error) {
+				t.Errorf("The command %s shouldn't run while replaying", tc.command)
+				return "", nil
+			})
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Error '%v' even if wantErr is %t", err, tc.wantErr)
+				return
+			}
+
+			if actual != tc.expected {
+				t.Errorf("Expected %v, actual %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_recordKey(t *testing.T) {
+	recorded := time.Date(2020, 6, 8, 15, 4, 5, 0, time.Local)
+	replayed := time.Date(2020, 7, 1, 9, 30, 0, 0, time.Local)
+
+	testCases := []struct {
+		name     string
+		expected string
+		recorded string
+		replayed string
+	}{
+		{
+			name:     "dates in a body",
+			expected: `{"startDate":"{today-7}","endDate":"{today+0}"}`,
+			recorded: `{"startDate":"2020-06-01","endDate":"2020-06-08"}`,
+			replayed: `{"startDate":"2020-06-24","endDate":"2020-07-01"}`,
+		},
+		{
+			name:     "escaped times in a URL",
+			expected: "/commits?since={today-1}&until={today+0}",
+			recorded: "/commits?since=" + url.QueryEscape(recorded.AddDate(0, 0, -1).Format(time.RFC3339)) +
+				"&until=" + url.QueryEscape(recorded.Format(time.RFC3339)),
+			replayed: "/commits?since=" + url.QueryEscape(replayed.AddDate(0, 0, -1).Format(time.RFC3339)) +
+				"&until=" + url.QueryEscape(replayed.Format(time.RFC3339)),
+		},
+		{
+			name:     "git command",
+			expected: "git log --since={today-30} 00:00:00 --until={today+0} 23:59:59",
+			recorded: "git log --since=2020-05-09 00:00:00 --until=2020-06-08 23:59:59",
+			replayed: "git log --since=2020-06-01 00:00:00 --until=2020-07-01 23:59:59",
+		},
+		{
+			name:     "prometheus times",
+			expected: "query=up&start={today-7}&end={today+0}&step=86400",
+			recorded: "query=up&start=" + formatPromTime(recorded.AddDate(0, 0, -7)) + "&end=" + formatPromTime(recorded) + "&step=86400",
+			replayed: "query=up&start=" + formatPromTime(replayed.AddDate(0, 0, -7)) + "&end=" + formatPromTime(replayed) + "&step=86400",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if actual := recordKey(tc.recorded, recorded); actual != tc.expected {
+				t.Errorf("Expected %v, actual %v", tc.expected, actual)
+			}
+			if actual := recordKey(tc.replayed, replayed); actual != tc.expected {
+				t.Errorf("Expected %v, actual %v", tc.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,491 @@
+package platform
+
+// snapshot is a TUI without terminal: it keeps the data of every widget drawn.
+// The snapshot can then be written as text, JSON or HTML, for reports or to compare dashboards over time.
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	SnapshotText = "text"
+	SnapshotJSON = "json"
+	SnapshotHTML = "html"
+)
+
+// ErrorTitle of the boxes displaying an error.
+const ErrorTitle = "ERROR"
+
+// Types of widgets in a snapshot.
+const (
+	SnapshotTitle      = "title"
+	SnapshotBox        = "box"
+	SnapshotGauge      = "gauge"
+	SnapshotBar        = "bar"
+	SnapshotStackedBar = "bar_stacked"
+	SnapshotTable      = "table"
+	SnapshotSparkline  = "sparkline"
+	SnapshotLine       = "line"
+)
+
+// Snapshot of the widgets of a dashboard.
+type Snapshot struct {
+	sync.Mutex
+	Rows []*SnapshotRow `json:"rows"`
+
+	// widgets of the next column.
+	widgets []*SnapshotWidget
+	// columns of the next row.
+	cols []*SnapshotCol
+	// every widget added to the grid, in order.
+	cells []*SnapshotWidget
+	// when not nil, the next widget drawn replace this one.
+	target *SnapshotWidget
+	// only one cell is updated at a time, for the target to stay the same while drawing.
+	update sync.Mutex
+}
+
+// SnapshotRow of the grid.
+type SnapshotRow struct {
+	Cols []*SnapshotCol `json:"cols"`
+}
+
+// SnapshotCol of the grid, with its size (from 1 to 12).
+type SnapshotCol struct {
+	Size    int               `json:"size"`
+	Widgets []*SnapshotWidget `json:"widgets"`
+}
+
+// SnapshotWidget with its data. Only the fields of its type are set.
+type SnapshotWidget struct {
+	Type       string     `json:"type"`
+	Title      string     `json:"title,omitempty"`
+	Text       string     `json:"text,omitempty"`
+	Value      *float64   `json:"value,omitempty"`
+	Dimensions []string   `json:"dimensions,omitempty"`
+	Values     []int      `json:"values,omitempty"`
+	Series     [][]int    `json:"series,omitempty"`
+	Points     []float64  `json:"points,omitempty"`
+	Rows       [][]string `json:"rows,omitempty"`
+}
+
+// NewSnapshot returns an empty snapshot.
+func NewSnapshot() *Snapshot {
+	return &Snapshot{}
+}
+
+func (s *Snapshot) add(w *SnapshotWidget) {
+	if s.target != nil {
+		*s.target = *w
+		return
+	}
+
+	s.cells = append(s.cells, w)
+	s.widgets = append(s.widgets, w)
+}
+
+// AddCol to the snapshot grid.
+func (s *Snapshot) AddCol(size int) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.cols = append(s.cols, &SnapshotCol{Size: size, Widgets: s.widgets})
+	s.widgets = nil
+}
+
+// AddRow to the snapshot grid.
+func (s *Snapshot) AddRow() {
+	s.Lock()
+	defer s.Unlock()
+
+	s.Rows = append(s.Rows, &SnapshotRow{Cols: s.cols})
+	s.cols = nil
+}
+
+// Title of a project, in its own row.
+func (s *Snapshot) Title(
+	title string,
+	textColor uint16,
+	borderColor uint16,
+	bold bool,
+	height int,
+	size int,
+) {
+	s.Lock()
+	defer s.Unlock()
+
+	w := &SnapshotWidget{Type: SnapshotTitle, Text: title}
+	s.Rows = append(s.Rows, &SnapshotRow{Cols: []*SnapshotCol{{Size: size, Widgets: []*SnapshotWidget{w}}}})
+}
+
+// TextBox widget type.
+func (s *Snapshot) TextBox(
+	data string,
+	textColor uint16,
+	borderColor uint16,
+	title string,
+	titleColor uint16,
+	height int,
+	multiline bool,
+	bold bool,
+) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.add(&SnapshotWidget{Type: SnapshotBox, Title: title, Text: data})
+}
+
+// Gauge widget type.
+func (s *Snapshot) Gauge(
+	data float64,
+	textColor uint16,
+	barColor uint16,
+	borderColor uint16,
+	title string,
+	titleColor uint16,
+	height int,
+) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.add(&SnapshotWidget{Type: SnapshotGauge, Title: title, Value: &data})
+}
+
+// BarChart widget type.
+func (s *Snapshot) BarChart(
+	data []int,
+	dimensions []string,
+	title string,
+	tc uint16,
+	bd uint16,
+	fg uint16,
+	nc uint16,
+	enc uint16,
+	height int,
+	gap int,
+	barWidth int,
+	barColor uint16,
+) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.add(&SnapshotWidget{Type: SnapshotBar, Title: title, Dimensions: dimensions, Values: data})
+}
+
+// StackedBarChart widget type. Only the series with values are kept.
+func (s *Snapshot) StackedBarChart(
+	data [8][]int,
+	dimensions []string,
+	title string,
+	tc uint16,
+	colors []uint16,
+	bd uint16,
+	fg uint16,
+	nc uint16,
+	height int,
+	gap int,
+	barWidth int,
+) {
+	s.Lock()
+	defer s.Unlock()
+
+	series := [][]int{}
+	for _, d := range data {
+		if len(d) > 0 {
+			series = append(series, d)
+		}
+	}
+
+	s.add(&SnapshotWidget{Type: SnapshotStackedBar, Title: title, Dimensions: dimensions, Series: series})
+}
+
+// Sparklines widget type. The labels are the dimensions of the series.
+func (s *Snapshot) Sparklines(
+	data [][]int,
+	labels []string,
+	title string,
+	tc uint16,
+	bd uint16,
+	colors []uint16,
+	height int,
+) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.add(&SnapshotWidget{Type: SnapshotSparkline, Title: title, Dimensions: labels, Series: data})
+}
+
+// LineChart widget type.
+func (s *Snapshot) LineChart(
+	data []float64,
+	dimensions []string,
+	title string,
+	tc uint16,
+	bd uint16,
+	fg uint16,
+	lineColor uint16,
+	height int,
+) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.add(&SnapshotWidget{Type: SnapshotLine, Title: title, Dimensions: dimensions, Points: data})
+}
+
+// Table widget type.
+func (s *Snapshot) Table(
+	data [][]string,
+	title string,
+	tc uint16,
+	bd uint16,
+	fg uint16,
+) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.add(&SnapshotWidget{Type: SnapshotTable, Title: title, Rows: data})
+}
+
+// CountCells added to the grid.
+func (s *Snapshot) CountCells() int {
+	s.Lock()
+	defer s.Unlock()
+
+	return len(s.cells)
+}
+
+// UpdateCell replace the widget of the cell id with the widget drawn by draw.
+func (s *Snapshot) UpdateCell(id int, draw func()) {
+	s.update.Lock()
+	defer s.update.Unlock()
+
+	s.Lock()
+	if id < 0 || id >= len(s.cells) {
+		s.Unlock()
+		return
+	}
+	s.target = s.cells[id]
+	s.Unlock()
+
+	draw()
+
+	s.Lock()
+	s.target = nil
+	s.Unlock()
+}
+
+// Cell returns a copy of the widget of the cell id.
+func (s *Snapshot) Cell(id int) (SnapshotWidget, bool) {
+	s.Lock()
+	defer s.Unlock()
+
+	if id < 0 || id >= len(s.cells) {
+		return SnapshotWidget{}, false
+	}
+
+	return *s.cells[id], true
+}
+
+// KQuit does nothing: there is no keyboard.
+func (*Snapshot) KQuit(key string) {}
+
+// KHotReload does nothing: there is no keyboard.
+func (*Snapshot) KHotReload(key string, c chan<- time.Time) {}
+
+// KEdit does nothing: there is no keyboard.
+func (*Snapshot) KEdit(key string, editDashboard func()) {}
+
+// Loop does nothing: a snapshot doesn't receive any event.
+func (*Snapshot) Loop() {}
+
+// Render does nothing: the snapshot is written with Write.
+func (*Snapshot) Render() {}
+
+// Align does nothing: a snapshot has no dimension.
+func (*Snapshot) Align() {}
+
+// Close does nothing.
+func (*Snapshot) Close() {}
+
+// Clean remove every widget of the snapshot.
+func (s *Snapshot) Clean() {
+	s.Lock()
+	defer s.Unlock()
+
+	s.Rows = nil
+	s.widgets = nil
+	s.cols = nil
+	s.cells = nil
+}
+
+// HotReload remove every widget of the snapshot.
+func (s *Snapshot) HotReload() {
+	s.Clean()
+}
+
+// Write the snapshot in the format given (text, json or html).
+func (s *Snapshot) Write(w io.Writer, format string) error {
+	s.Lock()
+	defer s.Unlock()
+
+	switch format {
+	case SnapshotText:
+		return s.writeText(w)
+	case SnapshotJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(s)
+	case SnapshotHTML:
+		return snapshotTemplate.Execute(w, s)
+	default:
+		return errors.Errorf("unknown snapshot format %s (possible formats: text, json or html)", format)
+	}
+}
+
+func (s *Snapshot) writeText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, r := range s.Rows {
+		for _, c := range r.Cols {
+			for _, wi := range c.Widgets {
+				writeTextWidget(tw, wi)
+			}
+		}
+	}
+
+	return tw.Flush()
+}
+
+func writeTextWidget(w io.Writer, wi *SnapshotWidget) {
+	if wi.Type == SnapshotTitle {
+		fmt.Fprintf(w, "# %s\n\n", strings.TrimSpace(wi.Text))
+		return
+	}
+
+	fmt.Fprintf(w, "## %s\n", strings.TrimSpace(wi.Title))
+	switch wi.Type {
+	case SnapshotBox:
+		fmt.Fprintln(w, strings.TrimSpace(wi.Text))
+	case SnapshotGauge:
+		fmt.Fprintf(w, "%.2f\n", *wi.Value)
+	case SnapshotBar, SnapshotStackedBar, SnapshotLine:
+		for _, l := range wi.Lines() {
+			fmt.Fprintln(w, strings.Join(l, "\t"))
+		}
+	case SnapshotSparkline:
+		for _, l := range wi.SparklineLines() {
+			fmt.Fprintln(w, strings.Join(l, "\t"))
+		}
+	case SnapshotTable:
+		for _, r := range wi.Rows {
+			fmt.Fprintln(w, strings.Join(r, "\t"))
+		}
+	}
+	fmt.Fprintln(w)
+}
+
+// Lines of a bar or line chart: each dimension followed by its values.
+func (wi *SnapshotWidget) Lines() [][]string {
+	lines := [][]string{}
+	for k, d := range wi.Dimensions {
+		l := []string{d}
+		if k < len(wi.Values) {
+			l = append(l, strconv.Itoa(wi.Values[k]))
+		}
+		if k < len(wi.Points) {
+			l = append(l, strconv.FormatFloat(wi.Points[k], 'f', 2, 64))
+		}
+		for _, v := range wi.Series {
+			if k < len(v) {
+				l = append(l, strconv.Itoa(v[k]))
+			}
+		}
+		lines = append(lines, l)
+	}
+
+	return lines
+}
+
+// SparklineLines of sparklines: each label followed by the values of its serie.
+func (wi *SnapshotWidget) SparklineLines() [][]string {
+	lines := [][]string{}
+	for k, v := range wi.Series {
+		l := []string{""}
+		if k < len(wi.Dimensions) {
+			l[0] = wi.Dimensions[k]
+		}
+		for _, p := range v {
+			l = append(l, strconv.Itoa(p))
+		}
+		lines = append(lines, l)
+	}
+
+	return lines
+}
+
+var snapshotTemplate = template.Must(template.New("snapshot").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>DevDash</title>
+<style>
+body { font-family: sans-serif; }
+.row { display: flex; }
+.widget { border: 1px solid #ccc; margin: 4px; padding: 4px; }
+table { border-collapse: collapse; }
+td, th { padding: 2px 8px; text-align: left; }
+</style>
+</head>
+<body>
+{{- range .Rows }}
+<div class="row">
+{{- range .Cols }}
+<div class="col" style="flex: {{ .Size }};">
+{{- range .Widgets }}
+{{- if eq .Type "title" }}
+<h1>{{ .Text }}</h1>
+{{- else }}
+<div class="widget {{ .Type }}">
+<h2>{{ .Title }}</h2>
+{{- if eq .Type "box" }}
+<pre>{{ .Text }}</pre>
+{{- else if eq .Type "gauge" }}
+<progress max="100" value="{{ .Value }}">{{ .Value }}</progress>
+{{- else if or (eq .Type "bar") (eq .Type "bar_stacked") (eq .Type "line") }}
+<table>
+{{- range .Lines }}
+<tr>{{ range $k, $v := . }}{{ if eq $k 0 }}<th>{{ $v }}</th>{{ else }}<td>{{ $v }}</td>{{ end }}{{ end }}</tr>
+{{- end }}
+</table>
+{{- else if eq .Type "sparkline" }}
+<table>
+{{- range .SparklineLines }}
+<tr>{{ range $k, $v := . }}{{ if eq $k 0 }}<th>{{ $v }}</th>{{ else }}<td>{{ $v }}</td>{{ end }}{{ end }}</tr>
+{{- end }}
+</table>
+{{- else if eq .Type "table" }}
+<table>
+{{- range .Rows }}
+<tr>{{ range . }}<td>{{ . }}</td>{{ end }}</tr>
+{{- end }}
+</table>
+{{- end }}
+</div>
+{{- end }}
+{{- end }}
+</div>
+{{- end }}
+</div>
+{{- end }}
+</body>
+</html>
+`))
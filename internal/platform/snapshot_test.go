@@ -0,0 +1,114 @@
+package platform
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func Test_SnapshotWrite(t *testing.T) {
+	testCases := []struct {
+		name     string
+		expected []string
+		format   string
+		wantErr  bool
+	}{
+		{
+			name:   "text",
+			format: SnapshotText,
+			expected: []string{
+				"# DevDash\n",
+				"## Stars\n42\n",
+				"## Coverage\n87.50\n",
+				"## Commits\nMon  3\nTue  5\n",
+				"## Issues\nMon  1  2\nTue  3  4\n",
+				"## Branches\nBranch  Author\nmaster  Matthieu\n",
+				"## Net I/O\nRX  1  4\nTX  2  3\n",
+				"## CPU usage\n10:00:00  12.50\n10:00:05  20.00\n",
+			},
+		},
+		{
+			name:   "json",
+			format: SnapshotJSON,
+			expected: []string{
+				`"type": "title"`,
+				`"text": "42"`,
+				`"value": 87.5`,
+				`"values": [`,
+				`"series": [`,
+				`"rows": [`,
+				`"type": "sparkline"`,
+				`"points": [`,
+			},
+		},
+		{
+			name:   "html",
+			format: SnapshotHTML,
+			expected: []string{
+				"<h1>DevDash</h1>",
+				"<pre>42</pre>",
+				`<progress max="100" value="87.5">`,
+				"<tr><th>Mon</th><td>3</td></tr>",
+				"<tr><th>Mon</th><td>1</td><td>2</td></tr>",
+				"<tr><td>master</td><td>Matthieu</td></tr>",
+				"<tr><th>RX</th><td>1</td><td>4</td></tr>",
+				"<tr><th>10:00:00</th><td>12.50</td></tr>",
+			},
+		},
+		{
+			name:    "unknown format",
+			format:  "xml",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := NewSnapshot()
+			s.Title("DevDash", 0, 0, true, 3, 12)
+			s.TextBox("42", 0, 0, "Stars", 0, 3, false, false)
+			s.Gauge(87.5, 0, 0, 0, "Coverage", 0, 3)
+			s.AddCol(6)
+			s.BarChart([]int{3, 5}, []string{"Mon", "Tue"}, "Commits", 0, 0, 0, 0, 0, 10, 0, 6, 0)
+			s.StackedBarChart([8][]int{{1, 3}, {2, 4}}, []string{"Mon", "Tue"}, "Issues", 0, nil, 0, 0, 0, 10, 0, 6)
+			s.Table([][]string{{"Branch", "Author"}, {"master", "Matthieu"}}, "Branches", 0, 0, 0)
+			s.Sparklines([][]int{{1, 4}, {2, 3}}, []string{"RX", "TX"}, "Net I/O", 0, 0, nil, 8)
+			s.LineChart([]float64{12.5, 20}, []string{"10:00:00", "10:00:05"}, "CPU usage", 0, 0, 0, 0, 10)
+			s.AddCol(6)
+			s.AddRow()
+
+			var b bytes.Buffer
+			err := s.Write(&b, tc.format)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Error '%v' even if wantErr is %t", err, tc.wantErr)
+				return
+			}
+
+			for _, e := range tc.expected {
+				if !strings.Contains(b.String(), e) {
+					t.Errorf("Expected %v, actual %v", e, b.String())
+				}
+			}
+		})
+	}
+}
+
+func Test_SnapshotUpdateCell(t *testing.T) {
+	s := NewSnapshot()
+	s.TextBox("42", 0, 0, "Stars", 0, 3, false, false)
+	s.TextBox("3", 0, 0, "Watchers", 0, 3, false, false)
+	s.AddCol(12)
+	s.AddRow()
+
+	s.UpdateCell(0, func() {
+		s.TextBox("43", 0, 0, "Stars", 0, 3, false, false)
+	})
+
+	if s.CountCells() != 2 {
+		t.Errorf("Expected %v, actual %v", 2, s.CountCells())
+	}
+
+	if actual := s.Rows[0].Cols[0].Widgets[0].Text; actual != "43" {
+		t.Errorf("Expected %v, actual %v", "43", actual)
+	}
+}
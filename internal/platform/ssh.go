@@ -1,33 +1,405 @@
 package platform
 
 import (
+	"io/ioutil"
 	"net"
 	"os"
+	"os/user"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	"golang.org/x/crypto/ssh"
 	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
 )
 
-func sshAgentAuth(username, addr string) (*ssh.Client, error) {
-	s := os.Getenv(sshAgentEnv)
-	if s == "" {
-		return nil, errors.Errorf("%s environment varible empty", sshAgentEnv)
+const (
+	sshDefaultPort   = 22
+	sshDefaultConfig = "~/.ssh/config"
+	// sshTimeout to connect to a host and to do the handshake, for each hop.
+	sshTimeout = 10 * time.Second
+)
+
+var (
+	// sshDefaultKeyFiles are used when no key file is configured. They are ignored if they don't exist.
+	sshDefaultKeyFiles = []string{"~/.ssh/id_ed25519", "~/.ssh/id_ecdsa", "~/.ssh/id_rsa"}
+	// sshDefaultKnownHosts are used when no known_hosts file is configured.
+	sshDefaultKnownHosts = []string{"~/.ssh/known_hosts", "/etc/ssh/ssh_known_hosts"}
+)
+
+// SSHOptions to connect to a remote host.
+// The options not set are read from the ssh config file, then the defaults of OpenSSH are used.
+type SSHOptions struct {
+	Port int
+	// KeyFiles are the private keys used to authenticate, in addition to the keys of the ssh-agent.
+	KeyFiles []string
+	// Passphrase of the encrypted private keys.
+	Passphrase string
+	// KnownHosts files to verify the key of the host.
+	KnownHosts []string
+	// ProxyJump is a list of jump hosts separated with commas, like "user@bastion:22,gateway".
+	ProxyJump string
+	// ConfigFile is the ssh config file, ~/.ssh/config by default.
+	ConfigFile string
+	// InsecureIgnoreHostKey accepts any host key. It shouldn't be used outside of a trusted network.
+	InsecureIgnoreHostKey bool
+}
+
+// sshConnection to a host, through the jump hosts if any.
+type sshConnection struct {
+	*ssh.Client
+	jumps []*ssh.Client
+}
+
+// Close the connection to the host and to the jump hosts.
+func (c *sshConnection) Close() error {
+	err := c.Client.Close()
+	for i := len(c.jumps) - 1; i >= 0; i-- {
+		c.jumps[i].Close()
+	}
+
+	return err
+}
+
+// sshTarget is a host to connect to, with its resolved options.
+type sshTarget struct {
+	addr   string
+	config *ssh.ClientConfig
+}
+
+// sshConnect to the host given, through the jump hosts if any.
+// The host can be an alias of the ssh config file.
+func sshConnect(username, host string, opts SSHOptions) (*sshConnection, error) {
+	configFile := opts.ConfigFile
+	if configFile == "" {
+		configFile = sshDefaultConfig
+	}
+
+	config, err := readSSHConfig(configFile)
+	if err != nil {
+		return nil, err
+	}
+
+	// The agent is only used during the handshakes.
+	signers, closeAgent, err := sshSigners(config, host, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer closeAgent()
+
+	targets := []sshTarget{}
+	proxyJump := opts.ProxyJump
+	if proxyJump == "" {
+		proxyJump = config.get(host, sshConfigProxyJump)
+	}
+
+	if proxyJump != "" && proxyJump != "none" {
+		for _, v := range strings.Split(proxyJump, ",") {
+			jumpUser, jumpHost, jumpPort := splitSSHDestination(strings.TrimSpace(v))
+			t, err := sshNewTarget(config, jumpUser, jumpHost, jumpPort, signers, opts)
+			if err != nil {
+				return nil, err
+			}
+			targets = append(targets, t)
+		}
+	}
+
+	t, err := sshNewTarget(config, username, host, opts.Port, signers, opts)
+	if err != nil {
+		return nil, err
 	}
+	targets = append(targets, t)
 
-	agentConn, err := net.Dial("unix", s)
+	return sshDial(targets)
+}
+
+// sshDial the first target, then each target through the previous one.
+func sshDial(targets []sshTarget) (*sshConnection, error) {
+	var conn *sshConnection
+	for _, t := range targets {
+		if conn == nil {
+			c, err := (&net.Dialer{Timeout: t.config.Timeout}).Dial("tcp", t.addr)
+			if err != nil {
+				return nil, errors.Wrapf(err, "can't connect to %s", t.addr)
+			}
+
+			client, err := sshHandshake(c, t)
+			if err != nil {
+				c.Close()
+				return nil, err
+			}
+			conn = &sshConnection{Client: client}
+			continue
+		}
+
+		c, err := conn.Dial("tcp", t.addr)
+		if err != nil {
+			conn.Close()
+			return nil, errors.Wrapf(err, "can't reach %s from the jump host %s", t.addr, conn.RemoteAddr())
+		}
+
+		client, err := sshHandshake(c, t)
+		if err != nil {
+			c.Close()
+			conn.Close()
+			return nil, err
+		}
+
+		conn = &sshConnection{
+			Client: client,
+			jumps:  append(conn.jumps, conn.Client),
+		}
+	}
+
+	return conn, nil
+}
+
+// sshHandshake with the target on the connection given.
+// The connection is closed if the handshake doesn't end before the timeout of the target: a host can accept connections without answering.
+func sshHandshake(c net.Conn, t sshTarget) (*ssh.Client, error) {
+	timer := time.AfterFunc(t.config.Timeout, func() { c.Close() })
+	sc, chans, reqs, err := ssh.NewClientConn(c, t.addr, t.config)
+	if !timer.Stop() {
+		if err == nil {
+			sc.Close()
+		}
+		return nil, errors.Errorf("can't connect to %s: no answer after %s", t.addr, t.config.Timeout)
+	}
 	if err != nil {
-		return nil, errors.Wrapf(err, "Can't connect via ssh-agent")
+		return nil, errors.Wrapf(err, "can't connect to %s", t.addr)
+	}
+
+	return ssh.NewClient(sc, chans, reqs), nil
+}
+
+// sshNewTarget resolves the address, the user and the host key verification of a host.
+func sshNewTarget(
+	config sshConfig,
+	username string,
+	host string,
+	port int,
+	signers []ssh.Signer,
+	opts SSHOptions,
+) (sshTarget, error) {
+	// The port can be part of the address.
+	if h, p, err := net.SplitHostPort(host); err == nil {
+		host = h
+		port, _ = strconv.Atoi(p)
+	}
+
+	if port == 0 {
+		port, _ = strconv.Atoi(config.get(host, sshConfigPort))
+	}
+	if port == 0 {
+		port = sshDefaultPort
+	}
+
+	if username == "" {
+		username = config.get(host, sshConfigUser)
+	}
+	if username == "" {
+		if u, err := user.Current(); err == nil {
+			username = u.Username
+		}
+	}
+
+	hostname := host
+	if h := config.get(host, sshConfigHostName); h != "" {
+		hostname = strings.Replace(h, "%h", host, -1)
+	}
+	addr := net.JoinHostPort(hostname, strconv.Itoa(port))
+
+	c := &ssh.ClientConfig{
+		User:    username,
+		Auth:    []ssh.AuthMethod{ssh.PublicKeys(signers...)},
+		Timeout: sshTimeout,
+	}
+
+	if opts.InsecureIgnoreHostKey {
+		c.HostKeyCallback = ssh.InsecureIgnoreHostKey()
+		return sshTarget{addr: addr, config: c}, nil
+	}
+
+	files := opts.KnownHosts
+	if len(files) == 0 {
+		files = config.getAll(host, sshConfigUserKnownHostsFile)
+	}
+	callback, known, err := sshKnownHosts(files)
+	if err != nil {
+		return sshTarget{}, err
+	}
+
+	c.HostKeyCallback = callback
+	// The host might have other keys than the ones known: the known ones are preferred.
+	c.HostKeyAlgorithms = sshHostKeyAlgorithms(known, addr)
+
+	return sshTarget{addr: addr, config: c}, nil
+}
+
+// sshSigners from the key files and the ssh-agent. They are used for the jump hosts too.
+// The keys configured must be readable, the default ones are ignored if they don't exist or are encrypted.
+func sshSigners(config sshConfig, host string, opts SSHOptions) ([]ssh.Signer, func(), error) {
+	files := opts.KeyFiles
+	explicit := true
+	if len(files) == 0 {
+		files = config.getAll(host, sshConfigIdentityFile)
+	}
+	if len(files) == 0 {
+		files = sshDefaultKeyFiles
+		explicit = false
+	}
+
+	signers := []ssh.Signer{}
+	for _, f := range files {
+		s, err := sshReadKey(expandHome(f), opts.Passphrase)
+		if err != nil && explicit {
+			return nil, nil, err
+		}
+		if err == nil {
+			signers = append(signers, s)
+		}
+	}
+
+	closeAgent := func() {}
+	if sock := os.Getenv(sshAgentEnv); sock != "" {
+		agentConn, err := net.Dial("unix", sock)
+		if err != nil && len(signers) == 0 {
+			return nil, nil, errors.Wrapf(err, "can't connect via ssh-agent")
+		}
+
+		if err == nil {
+			closeAgent = func() { agentConn.Close() }
+			if as, err := agent.NewClient(agentConn).Signers(); err == nil {
+				signers = append(signers, as...)
+			}
+		}
+	}
+
+	if len(signers) == 0 {
+		closeAgent()
+		return nil, nil, errors.Errorf(
+			"no SSH key found: configure key_files or add your keys to the ssh-agent (%s)",
+			sshAgentEnv,
+		)
+	}
+
+	return signers, closeAgent, nil
+}
+
+// sshReadKey from a private key file, decrypted with the passphrase if needed.
+func sshReadKey(file, passphrase string) (ssh.Signer, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't read SSH key %s", file)
+	}
+
+	s, err := ssh.ParsePrivateKey(data)
+	if _, ok := err.(*ssh.PassphraseMissingError); ok {
+		if passphrase == "" {
+			return nil, errors.Errorf("the SSH key %s is encrypted: you need to specify its passphrase", file)
+		}
+		s, err = ssh.ParsePrivateKeyWithPassphrase(data, []byte(passphrase))
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't parse SSH key %s", file)
+	}
+
+	return s, nil
+}
+
+// sshKnownHosts verifies the host keys with the known_hosts files.
+// The callback of knownhosts is returned too, as known: its errors give the keys known for a host.
+func sshKnownHosts(files []string) (callback ssh.HostKeyCallback, known ssh.HostKeyCallback, err error) {
+	if len(files) == 0 {
+		files = sshDefaultKnownHosts
+	}
+
+	existing := []string{}
+	for _, f := range files {
+		f = expandHome(f)
+		if _, err := os.Stat(f); err == nil {
+			existing = append(existing, f)
+		}
+	}
+
+	if len(existing) == 0 {
+		return nil, nil, errors.Errorf("can't find any known_hosts file in %s", strings.Join(files, ", "))
+	}
+
+	known, err = knownhosts.New(existing...)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "can't read known_hosts files %s", strings.Join(existing, ", "))
+	}
+
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		err := known(hostname, remote, key)
+		if ke, ok := err.(*knownhosts.KeyError); ok {
+			if len(ke.Want) == 0 {
+				return errors.Errorf(
+					"the host %s is unknown: add its key to %s, with ssh-keyscan for example",
+					hostname,
+					strings.Join(existing, ", "),
+				)
+			}
+
+			return errors.Errorf(
+				"the key of the host %s doesn't match the one in %s:%d, it might be a man-in-the-middle attack",
+				hostname,
+				ke.Want[0].Filename,
+				ke.Want[0].Line,
+			)
+		}
+
+		return err
+	}, known, nil
+}
+
+// sshHostKeyAlgorithms of the keys known for the address, with the callback of knownhosts.
+// Nil is returned if no key is known: the default algorithms are used.
+func sshHostKeyAlgorithms(known ssh.HostKeyCallback, addr string) []string {
+	// No key is equal to an empty key: the keys known are returned in the error.
+	err := known(addr, &net.TCPAddr{}, sshEmptyKey{})
+	ke, ok := err.(*knownhosts.KeyError)
+	if !ok {
+		return nil
+	}
+
+	algos := []string{}
+	for _, v := range ke.Want {
+		if v.Key.Type() == ssh.KeyAlgoRSA {
+			algos = append(algos, ssh.KeyAlgoRSASHA512, ssh.KeyAlgoRSASHA256)
+		}
+		algos = append(algos, v.Key.Type())
+	}
+
+	if len(algos) == 0 {
+		return nil
+	}
+
+	return algos
+}
+
+// sshEmptyKey is a public key matching no host.
+type sshEmptyKey struct{}
+
+func (sshEmptyKey) Type() string                        { return "" }
+func (sshEmptyKey) Marshal() []byte                     { return nil }
+func (sshEmptyKey) Verify([]byte, *ssh.Signature) error { return errors.New("empty key") }
+
+// splitSSHDestination like "user@host:port" in its user, its host and its port.
+func splitSSHDestination(dest string) (string, string, int) {
+	username := ""
+	if i := strings.LastIndex(dest, "@"); i >= 0 {
+		username, dest = dest[:i], dest[i+1:]
 	}
 
-	auth := ssh.PublicKeysCallback(agent.NewClient(agentConn).Signers)
-	config := &ssh.ClientConfig{
-		User: username,
-		Auth: []ssh.AuthMethod{auth},
-		HostKeyCallback: func(string, net.Addr, ssh.PublicKey) error {
-			return nil
-		},
+	port := 0
+	if h, p, err := net.SplitHostPort(dest); err == nil {
+		dest = h
+		port, _ = strconv.Atoi(p)
 	}
 
-	return ssh.Dial("tcp", addr, config)
+	return username, dest, port
 }
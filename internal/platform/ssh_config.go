@@ -0,0 +1,189 @@
+package platform
+
+// ssh_config reads the options of the hosts from an OpenSSH client config file, like ~/.ssh/config.
+// Only the keywords needed to connect are used: HostName, User, Port, IdentityFile, UserKnownHostsFile and ProxyJump.
+// The Match blocks and the Include keyword are not supported: they are ignored.
+// See https://man.openbsd.org/ssh_config
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	sshConfigHost               = "host"
+	sshConfigMatch              = "match"
+	sshConfigHostName           = "hostname"
+	sshConfigUser               = "user"
+	sshConfigPort               = "port"
+	sshConfigIdentityFile       = "identityfile"
+	sshConfigUserKnownHostsFile = "userknownhostsfile"
+	sshConfigProxyJump          = "proxyjump"
+)
+
+// sshConfig is the list of the blocks of the config file, in order.
+type sshConfig []sshConfigBlock
+
+type sshConfigBlock struct {
+	patterns []string
+	// options are the arguments of each keyword, in lower case.
+	options map[string][][]string
+}
+
+// readSSHConfig from the file. An empty config is returned if the file doesn't exist.
+func readSSHConfig(file string) (sshConfig, error) {
+	f, err := os.Open(expandHome(file))
+	if os.IsNotExist(err) {
+		return sshConfig{}, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't open ssh config file %s", file)
+	}
+	defer f.Close()
+
+	c, err := parseSSHConfig(f)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't read ssh config file %s", file)
+	}
+
+	return c, nil
+}
+
+func parseSSHConfig(r io.Reader) (sshConfig, error) {
+	// The options before the first Host apply to every host.
+	c := sshConfig{{patterns: []string{"*"}, options: map[string][][]string{}}}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		keyword, args := splitSSHConfigLine(line)
+		switch keyword {
+		case sshConfigHost:
+			c = append(c, sshConfigBlock{patterns: args, options: map[string][][]string{}})
+		case sshConfigMatch:
+			// No host matches the block: its options are ignored.
+			c = append(c, sshConfigBlock{options: map[string][][]string{}})
+		default:
+			last := c[len(c)-1]
+			last.options[keyword] = append(last.options[keyword], args)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// splitSSHConfigLine into its keyword, in lower case, and its arguments.
+// The keyword can be separated from its arguments with "=", and the arguments can be quoted.
+func splitSSHConfigLine(line string) (string, []string) {
+	i := strings.IndexAny(line, " \t=")
+	if i < 0 {
+		return strings.ToLower(line), nil
+	}
+
+	keyword := strings.ToLower(line[:i])
+	rest := strings.TrimLeft(line[i:], " \t")
+	rest = strings.TrimLeft(strings.TrimPrefix(rest, "="), " \t")
+
+	args := []string{}
+	for rest != "" {
+		var arg string
+		if rest[0] == '"' {
+			end := strings.IndexByte(rest[1:], '"')
+			if end < 0 {
+				arg, rest = rest[1:], ""
+			} else {
+				arg, rest = rest[1:end+1], rest[end+2:]
+			}
+		} else {
+			end := strings.IndexAny(rest, " \t")
+			if end < 0 {
+				end = len(rest)
+			}
+			arg, rest = rest[:end], rest[end:]
+		}
+
+		args = append(args, arg)
+		rest = strings.TrimLeft(rest, " \t")
+	}
+
+	return keyword, args
+}
+
+// get the first argument of the keyword for the host: like OpenSSH, the first value found is used.
+func (c sshConfig) get(host, keyword string) string {
+	for _, b := range c {
+		if !b.matches(host) {
+			continue
+		}
+
+		for _, args := range b.options[keyword] {
+			if len(args) > 0 {
+				return args[0]
+			}
+		}
+	}
+
+	return ""
+}
+
+// getAll the arguments of the keyword for the host, like the IdentityFile which can be repeated.
+func (c sshConfig) getAll(host, keyword string) []string {
+	values := []string{}
+	for _, b := range c {
+		if !b.matches(host) {
+			continue
+		}
+
+		for _, args := range b.options[keyword] {
+			values = append(values, args...)
+		}
+	}
+
+	return values
+}
+
+// matches returns true if one of the patterns matches the host, and no negated pattern ("!host") matches it.
+func (b sshConfigBlock) matches(host string) bool {
+	found := false
+	for _, p := range b.patterns {
+		negated := strings.HasPrefix(p, "!")
+		if ok, _ := path.Match(strings.TrimPrefix(p, "!"), host); !ok {
+			continue
+		}
+
+		if negated {
+			return false
+		}
+		found = true
+	}
+
+	return found
+}
+
+// expandHome replaces the "~" at the beginning of the path by the home directory.
+func expandHome(p string) string {
+	if p != "~" && !strings.HasPrefix(p, "~/") {
+		return p
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return p
+	}
+
+	return filepath.Join(home, p[1:])
+}
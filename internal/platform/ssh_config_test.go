@@ -0,0 +1,75 @@
+package platform
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func Test_sshConfig(t *testing.T) {
+	config, err := parseSSHConfig(bytes.NewReader(ReadFixtureFile("./testdata/fixtures/ssh_config", t)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name     string
+		host     string
+		keyword  string
+		expected string
+	}{
+		{name: "host name with token", host: "web", keyword: sshConfigHostName, expected: "%h.internal.example.com"},
+		{name: "port", host: "web", keyword: sshConfigPort, expected: "2222"},
+		{name: "first value found", host: "web.example.com", keyword: sshConfigUser, expected: "deploy"},
+		{name: "wildcard", host: "api.example.com", keyword: sshConfigUser, expected: "admin"},
+		{name: "negated pattern", host: "private.example.com", keyword: sshConfigUser, expected: "matthieu"},
+		{name: "match block ignored", host: "db", keyword: sshConfigUser, expected: "matthieu"},
+		{name: "proxy jump", host: "db", keyword: sshConfigProxyJump, expected: "bastion"},
+		{name: "default port", host: "db", keyword: sshConfigPort, expected: "22"},
+		{name: "no value", host: "api.example.com", keyword: sshConfigHostName, expected: ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := config.get(tc.host, tc.keyword)
+			if tc.expected != actual {
+				t.Errorf("Expected %v, actual %v", tc.expected, actual)
+			}
+		})
+	}
+
+	testCasesAll := []struct {
+		name     string
+		host     string
+		keyword  string
+		expected []string
+	}{
+		{
+			name:     "identity files added together",
+			host:     "api.example.com",
+			keyword:  sshConfigIdentityFile,
+			expected: []string{"~/.ssh/id_devdash", "~/.ssh/id_example"},
+		},
+		{
+			name:     "quoted arguments",
+			host:     "db",
+			keyword:  sshConfigUserKnownHostsFile,
+			expected: []string{"~/.ssh/known_hosts_db", "/etc/ssh/known hosts"},
+		},
+		{
+			name:     "no value",
+			host:     "web",
+			keyword:  sshConfigUserKnownHostsFile,
+			expected: []string{},
+		},
+	}
+
+	for _, tc := range testCasesAll {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := config.getAll(tc.host, tc.keyword)
+			if !reflect.DeepEqual(tc.expected, actual) {
+				t.Errorf("Expected %v, actual %v", tc.expected, actual)
+			}
+		})
+	}
+}
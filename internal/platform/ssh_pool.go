@@ -0,0 +1,277 @@
+package platform
+
+// ssh_pool keeps one SSH connection per host, shared by every Host connecting to it with the same options.
+// The commands are run in sessions multiplexed on the connection.
+// A connection is checked with keepalives: when it dies, it's reconnected with a backoff when the next command runs.
+
+import (
+	"io"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	sshKeepAliveInterval = 15 * time.Second
+	sshKeepAliveTimeout  = 10 * time.Second
+	sshMinBackoff        = time.Second
+	sshMaxBackoff        = time.Minute
+	// sshMaxSessions running at the same time on a connection. OpenSSH refuses more than 10 by default.
+	sshMaxSessions = 8
+
+	sshKeepAliveRequest = "keepalive@openssh.com"
+)
+
+var sshPool = &sshConnPool{conns: map[string]*sshPooledConn{}}
+
+type sshConnPool struct {
+	mu    sync.Mutex
+	conns map[string]*sshPooledConn
+}
+
+// get the connection of the key, created with dial if needed. It needs to be released when not used anymore.
+func (p *sshConnPool) get(key string, dial func() (*sshConnection, error)) *sshPooledConn {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	c, ok := p.conns[key]
+	if !ok {
+		c = newSSHPooledConn(dial)
+		c.key = key
+		p.conns[key] = c
+	}
+	c.refs++
+
+	return c
+}
+
+// release the connection: it's closed when nobody uses it anymore.
+// The connection is closed outside of the lock of the pool, to not block the other hosts.
+func (p *sshConnPool) release(c *sshPooledConn) {
+	p.mu.Lock()
+	c.refs--
+	unused := c.refs <= 0
+	if unused && p.conns[c.key] == c {
+		delete(p.conns, c.key)
+	}
+	p.mu.Unlock()
+
+	if unused {
+		c.close()
+	}
+}
+
+// sshPooledConn is a connection reconnected when needed.
+type sshPooledConn struct {
+	key      string
+	refs     int
+	dial     func() (*sshConnection, error)
+	sessions chan bool
+	// keepAlive requests are sent at this interval, and need an answer before the timeout.
+	keepAliveInterval time.Duration
+	keepAliveTimeout  time.Duration
+	// now is the clock of the backoff.
+	now func() time.Time
+
+	mu     sync.Mutex
+	conn   *sshConnection
+	closed bool
+	// dialing is closed when the current dial ends, if any.
+	dialing chan bool
+	// err of the last dial, returned till the next retry.
+	err     error
+	retry   time.Time
+	backoff time.Duration
+}
+
+func newSSHPooledConn(dial func() (*sshConnection, error)) *sshPooledConn {
+	return &sshPooledConn{
+		dial:              dial,
+		sessions:          make(chan bool, sshMaxSessions),
+		keepAliveInterval: sshKeepAliveInterval,
+		keepAliveTimeout:  sshKeepAliveTimeout,
+		now:               time.Now,
+	}
+}
+
+// client connected, or reconnected if the connection died.
+// The connection is not retried before the end of the backoff.
+// Only one dial runs at a time, without lock: the callers at the same time wait for its result.
+func (c *sshPooledConn) client() (*sshConnection, error) {
+	c.mu.Lock()
+	for c.dialing != nil {
+		dialing := c.dialing
+		c.mu.Unlock()
+		<-dialing
+		c.mu.Lock()
+	}
+
+	if c.conn != nil {
+		conn := c.conn
+		c.mu.Unlock()
+		return conn, nil
+	}
+
+	if c.closed {
+		c.mu.Unlock()
+		return nil, errors.New("the SSH connection is closed")
+	}
+
+	if c.now().Before(c.retry) {
+		err := errors.Wrapf(c.err, "next connection attempt at %s", c.retry.Format("15:04:05"))
+		c.mu.Unlock()
+		return nil, err
+	}
+
+	dialing := make(chan bool)
+	c.dialing = dialing
+	c.mu.Unlock()
+
+	conn, err := c.dial()
+
+	c.mu.Lock()
+	c.dialing = nil
+	close(dialing)
+
+	if err != nil {
+		c.backoff *= 2
+		if c.backoff < sshMinBackoff {
+			c.backoff = sshMinBackoff
+		}
+		if c.backoff > sshMaxBackoff {
+			c.backoff = sshMaxBackoff
+		}
+		c.err = err
+		// The backoff begins when the dial fails: it can take till its timeout.
+		c.retry = c.now().Add(c.backoff)
+		c.mu.Unlock()
+		return nil, err
+	}
+
+	// Closed while dialing.
+	if c.closed {
+		c.mu.Unlock()
+		conn.Close()
+		return nil, errors.New("the SSH connection is closed")
+	}
+
+	c.conn = conn
+	c.backoff = 0
+	c.retry = time.Time{}
+	c.mu.Unlock()
+	go c.keepAlive(conn)
+
+	return conn, nil
+}
+
+// run the command in a new session, writing its output in stdout.
+func (c *sshPooledConn) run(command string, stdout io.Writer) error {
+	c.sessions <- true
+	defer func() { <-c.sessions }()
+
+	session, err := c.session()
+	if err != nil {
+		return errors.Wrapf(err, "can't create session with SSH client for command %s", command)
+	}
+	defer session.Close()
+
+	session.Stdout = stdout
+	if err := session.Run(command); err != nil {
+		return errors.Wrapf(err, "can't run command %s on remote server", command)
+	}
+
+	return nil
+}
+
+func (c *sshPooledConn) session() (*ssh.Session, error) {
+	conn, err := c.client()
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := conn.NewSession()
+	// The server refusing the session doesn't mean the connection is dead.
+	if _, ok := err.(*ssh.OpenChannelError); err == nil || ok {
+		return s, err
+	}
+
+	// The connection died since the last keepalive: one reconnection is tried.
+	c.broken(conn)
+	conn, err = c.client()
+	if err != nil {
+		return nil, err
+	}
+
+	return conn.NewSession()
+}
+
+// keepAlive sends requests to the host till the connection is closed.
+// The connection is closed if the host doesn't answer: the next command reconnects.
+func (c *sshPooledConn) keepAlive(conn *sshConnection) {
+	closed := make(chan bool)
+	go func() {
+		conn.Wait()
+		close(closed)
+	}()
+
+	ticker := time.NewTicker(c.keepAliveInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-closed:
+			c.broken(conn)
+			return
+		case <-ticker.C:
+			if err := sshKeepAlive(conn, c.keepAliveTimeout); err != nil {
+				c.broken(conn)
+				return
+			}
+		}
+	}
+}
+
+// sshKeepAlive checks that the host answers. Any answer is fine, even a refusal.
+func sshKeepAlive(conn *sshConnection, timeout time.Duration) error {
+	errc := make(chan error, 1)
+	go func() {
+		_, _, err := conn.SendRequest(sshKeepAliveRequest, true, nil)
+		errc <- err
+	}()
+
+	select {
+	case err := <-errc:
+		return err
+	case <-time.After(timeout):
+		return errors.Errorf("no answer to keepalive after %s", timeout)
+	}
+}
+
+// broken closes the connection if it's still the current one.
+func (c *sshPooledConn) broken(conn *sshConnection) {
+	c.mu.Lock()
+	current := c.conn == conn
+	if current {
+		c.conn = nil
+	}
+	c.mu.Unlock()
+
+	if current {
+		conn.Close()
+	}
+}
+
+// close the connection. A dial running is not waited for: its connection is closed when it ends.
+func (c *sshPooledConn) close() {
+	c.mu.Lock()
+	conn := c.conn
+	c.conn = nil
+	c.closed = true
+	c.mu.Unlock()
+
+	if conn != nil {
+		conn.Close()
+	}
+}
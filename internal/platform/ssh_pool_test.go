@@ -0,0 +1,231 @@
+package platform
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+func Test_SSHPooledConnReconnect(t *testing.T) {
+	dir, err := ioutil.TempDir("", "devdash_ssh")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	keyFile := "./testdata/fixtures/ssh_key_encrypted"
+	clientKey, err := sshReadKey(keyFile, sshTestPassphrase)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hostKey := sshTestSigner(t)
+	server := sshTestServer(t, hostKey, clientKey.PublicKey())
+	defer server.Close()
+
+	knownHosts := filepath.Join(dir, "known_hosts")
+	sshTestFile(t, knownHosts, knownhosts.Line([]string{server.Addr().String()}, hostKey.PublicKey()))
+
+	var dials int32
+	conn := newSSHPooledConn(func() (*sshConnection, error) {
+		atomic.AddInt32(&dials, 1)
+		return sshConnect("", server.Addr().String(), SSHOptions{
+			KeyFiles:   []string{keyFile},
+			Passphrase: sshTestPassphrase,
+			KnownHosts: []string{knownHosts},
+			ConfigFile: filepath.Join(dir, "no_config"),
+		})
+	})
+	conn.keepAliveInterval = 10 * time.Millisecond
+	defer conn.close()
+
+	h := &Host{ssh: conn}
+	for _, cmd := range []string{"uptime", "cat /proc/loadavg"} {
+		if _, err := h.run(cmd); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if dials != 1 {
+		t.Errorf("Expected %v, actual %v", 1, dials)
+	}
+
+	// The dead connection is detected without running any command.
+	server.drop()
+	for i := 0; i < 100; i++ {
+		conn.mu.Lock()
+		dead := conn.conn == nil
+		conn.mu.Unlock()
+		if dead {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	actual, err := h.run("uptime")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if actual != "uptime" {
+		t.Errorf("Expected %v, actual %v", "uptime", actual)
+	}
+
+	if dials != 2 {
+		t.Errorf("Expected %v, actual %v", 2, dials)
+	}
+}
+
+func Test_SSHPooledConnBackoff(t *testing.T) {
+	var dials int32
+	now := time.Date(2020, 6, 1, 10, 0, 0, 0, time.UTC)
+	conn := newSSHPooledConn(func() (*sshConnection, error) {
+		atomic.AddInt32(&dials, 1)
+		// The dial fails after its timeout.
+		now = now.Add(sshTimeout)
+		return nil, errors.New("connection refused")
+	})
+	conn.now = func() time.Time { return now }
+
+	if _, err := conn.client(); err == nil {
+		t.Fatal("Expected an error when the dial fails")
+	}
+
+	// No new attempt before the end of the backoff, even after a slow dial.
+	_, err := conn.client()
+	if err == nil || !strings.Contains(err.Error(), "next connection attempt") {
+		t.Errorf("Unexpected error %v", err)
+	}
+
+	if dials != 1 || conn.backoff != sshMinBackoff {
+		t.Errorf("Expected %v dial with backoff %v, actual %v with backoff %v", 1, sshMinBackoff, dials, conn.backoff)
+	}
+
+	now = conn.retry
+	conn.client()
+	if dials != 2 || conn.backoff != 2*sshMinBackoff {
+		t.Errorf("Expected %v dials with backoff %v, actual %v with backoff %v", 2, 2*sshMinBackoff, dials, conn.backoff)
+	}
+
+	conn.backoff = sshMaxBackoff
+	now = conn.retry
+	conn.client()
+	if conn.backoff != sshMaxBackoff {
+		t.Errorf("Expected %v, actual %v", sshMaxBackoff, conn.backoff)
+	}
+}
+
+func Test_SSHConnPool(t *testing.T) {
+	pool := &sshConnPool{conns: map[string]*sshPooledConn{}}
+	dial := func() (*sshConnection, error) { return nil, errors.New("not used") }
+
+	c1 := pool.get("matthieu@web1", dial)
+	c2 := pool.get("matthieu@web1", dial)
+	c3 := pool.get("matthieu@web2", dial)
+	if c1 != c2 || c1 == c3 {
+		t.Fatal("Expected the same connection for the same key only")
+	}
+
+	pool.release(c1)
+	if _, ok := pool.conns["matthieu@web1"]; !ok || c1.closed {
+		t.Error("Expected the connection to be kept while it's used")
+	}
+
+	pool.release(c2)
+	if _, ok := pool.conns["matthieu@web1"]; ok || !c1.closed {
+		t.Error("Expected the connection to be closed when not used anymore")
+	}
+
+	if _, err := c1.client(); err == nil {
+		t.Error("Expected an error for a closed connection")
+	}
+}
+
+func Test_SSHPooledConnDialing(t *testing.T) {
+	var dials int32
+	started := make(chan bool)
+	unblock := make(chan bool)
+	conn := newSSHPooledConn(func() (*sshConnection, error) {
+		if atomic.AddInt32(&dials, 1) == 1 {
+			close(started)
+		}
+		<-unblock
+		return nil, errors.New("connection refused")
+	})
+
+	errs := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			_, err := conn.client()
+			errs <- err
+		}()
+	}
+	<-started
+
+	// The connection can be closed while dialing.
+	closed := make(chan bool)
+	go func() {
+		conn.close()
+		close(closed)
+	}()
+
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the connection to be closed without waiting for the dial")
+	}
+
+	close(unblock)
+	for i := 0; i < 2; i++ {
+		if err := <-errs; err == nil {
+			t.Error("Expected an error when the dial fails")
+		}
+	}
+
+	if actual := atomic.LoadInt32(&dials); actual != 1 {
+		t.Errorf("Expected %v, actual %v", 1, actual)
+	}
+}
+
+func Test_NewHostDown(t *testing.T) {
+	// Nothing listens on the address.
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+
+	h, err := NewHost("matthieu", addr, SSHOptions{
+		KeyFiles:              []string{"./testdata/fixtures/ssh_key_encrypted"},
+		Passphrase:            sshTestPassphrase,
+		InsecureIgnoreHostKey: true,
+		ConfigFile:            "./testdata/fixtures/no_config",
+	})
+	if err != nil {
+		t.Fatalf("Error '%v' even if wantErr is %t", err, false)
+	}
+	defer h.Close()
+
+	// The clock doesn't move: the backoff can't end, however long the dial is.
+	now := time.Now()
+	h.ssh.now = func() time.Time { return now }
+
+	if _, err := h.Runner("uptime"); err == nil {
+		t.Error("Expected an error when the host is down")
+	}
+
+	// The backoff is kept between the commands.
+	_, err = h.Runner("uptime")
+	if err == nil || !strings.Contains(err.Error(), "next connection attempt") {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
@@ -0,0 +1,431 @@
+package platform
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"fmt"
+	"golang.org/x/crypto/ed25519"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+const sshTestPassphrase = "devdash"
+
+func Test_SSHConnect(t *testing.T) {
+	dir, err := ioutil.TempDir("", "devdash_ssh")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	keyFile := "./testdata/fixtures/ssh_key_encrypted"
+	clientKey, err := sshReadKey(keyFile, sshTestPassphrase)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hostKey := sshTestSigner(t)
+	target := sshTestServer(t, hostKey, clientKey.PublicKey())
+	defer target.Close()
+	jump := sshTestServer(t, hostKey, clientKey.PublicKey())
+	defer jump.Close()
+
+	knownHosts := filepath.Join(dir, "known_hosts")
+	sshTestFile(t, knownHosts, knownhosts.Line(
+		[]string{target.Addr().String(), jump.Addr().String()},
+		hostKey.PublicKey(),
+	))
+
+	otherHosts := filepath.Join(dir, "known_hosts_other")
+	sshTestFile(t, otherHosts, knownhosts.Line([]string{"example.com"}, hostKey.PublicKey()))
+
+	changedHosts := filepath.Join(dir, "known_hosts_changed")
+	sshTestFile(t, changedHosts, knownhosts.Line([]string{target.Addr().String()}, sshTestSigner(t).PublicKey()))
+
+	_, port, _ := net.SplitHostPort(target.Addr().String())
+	config := filepath.Join(dir, "config")
+	sshTestFile(t, config, fmt.Sprintf(
+		"Host devdash\n  HostName 127.0.0.1\n  Port %s\n  User matthieu\n  IdentityFile %s\n  UserKnownHostsFile %s\n",
+		port,
+		keyFile,
+		knownHosts,
+	))
+
+	noConfig := filepath.Join(dir, "no_config")
+
+	testCases := []struct {
+		name    string
+		host    string
+		opts    SSHOptions
+		wantErr bool
+	}{
+		{
+			name: "key with passphrase",
+			host: target.Addr().String(),
+			opts: SSHOptions{KeyFiles: []string{keyFile}, Passphrase: sshTestPassphrase, KnownHosts: []string{knownHosts}, ConfigFile: noConfig},
+		},
+		{
+			name: "port",
+			host: "127.0.0.1",
+			opts: SSHOptions{Port: sshTestPort(target), KeyFiles: []string{keyFile}, Passphrase: sshTestPassphrase, KnownHosts: []string{knownHosts}, ConfigFile: noConfig},
+		},
+		{
+			name:    "missing passphrase",
+			host:    target.Addr().String(),
+			opts:    SSHOptions{KeyFiles: []string{keyFile}, KnownHosts: []string{knownHosts}, ConfigFile: noConfig},
+			wantErr: true,
+		},
+		{
+			name:    "wrong passphrase",
+			host:    target.Addr().String(),
+			opts:    SSHOptions{KeyFiles: []string{keyFile}, Passphrase: "wrong", KnownHosts: []string{knownHosts}, ConfigFile: noConfig},
+			wantErr: true,
+		},
+		{
+			name:    "unknown host",
+			host:    target.Addr().String(),
+			opts:    SSHOptions{KeyFiles: []string{keyFile}, Passphrase: sshTestPassphrase, KnownHosts: []string{otherHosts}, ConfigFile: noConfig},
+			wantErr: true,
+		},
+		{
+			name:    "host key changed",
+			host:    target.Addr().String(),
+			opts:    SSHOptions{KeyFiles: []string{keyFile}, Passphrase: sshTestPassphrase, KnownHosts: []string{changedHosts}, ConfigFile: noConfig},
+			wantErr: true,
+		},
+		{
+			name: "host key ignored",
+			host: target.Addr().String(),
+			opts: SSHOptions{KeyFiles: []string{keyFile}, Passphrase: sshTestPassphrase, InsecureIgnoreHostKey: true, ConfigFile: noConfig},
+		},
+		{
+			name: "jump host",
+			host: target.Addr().String(),
+			opts: SSHOptions{
+				KeyFiles:   []string{keyFile},
+				Passphrase: sshTestPassphrase,
+				KnownHosts: []string{knownHosts},
+				ProxyJump:  "matthieu@" + jump.Addr().String(),
+				ConfigFile: noConfig,
+			},
+		},
+		{
+			name: "ssh config",
+			host: "devdash",
+			opts: SSHOptions{Passphrase: sshTestPassphrase, ConfigFile: config},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			conn := newSSHPooledConn(func() (*sshConnection, error) {
+				return sshConnect("", tc.host, tc.opts)
+			})
+			defer conn.close()
+
+			h := &Host{ssh: conn}
+			actual, err := h.run("uptime")
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Error '%v' even if wantErr is %t", err, tc.wantErr)
+			}
+
+			if tc.wantErr == false && actual != "uptime" {
+				t.Errorf("Expected %v, actual %v", "uptime", actual)
+			}
+		})
+	}
+}
+
+func Test_SSHHostKeyAlgorithms(t *testing.T) {
+	dir, err := ioutil.TempDir("", "devdash_ssh")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	keyFile := "./testdata/fixtures/ssh_key_encrypted"
+	clientKey, err := sshReadKey(keyFile, sshTestPassphrase)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaSigner, err := ssh.NewSignerFromKey(rsaKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecdsaSigner, err := ssh.NewSignerFromKey(ecdsaKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the RSA key of the server is known.
+	server := sshTestServerKeys(t, []ssh.Signer{sshTestSigner(t), ecdsaSigner, rsaSigner}, clientKey.PublicKey())
+	defer server.Close()
+
+	knownHosts := filepath.Join(dir, "known_hosts")
+	sshTestFile(t, knownHosts, knownhosts.Line([]string{server.Addr().String()}, rsaSigner.PublicKey()))
+
+	_, known, err := sshKnownHosts([]string{knownHosts})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{ssh.KeyAlgoRSASHA512, ssh.KeyAlgoRSASHA256, ssh.KeyAlgoRSA}
+	if actual := sshHostKeyAlgorithms(known, server.Addr().String()); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %v, actual %v", expected, actual)
+	}
+
+	if actual := sshHostKeyAlgorithms(known, "example.com:22"); actual != nil {
+		t.Errorf("Expected %v, actual %v", nil, actual)
+	}
+
+	conn, err := sshConnect("", server.Addr().String(), SSHOptions{
+		KeyFiles:   []string{keyFile},
+		Passphrase: sshTestPassphrase,
+		KnownHosts: []string{knownHosts},
+		ConfigFile: filepath.Join(dir, "no_config"),
+	})
+	if err != nil {
+		t.Fatalf("Error '%v' even if wantErr is %t", err, false)
+	}
+	conn.Close()
+}
+
+func Test_SSHDialTimeout(t *testing.T) {
+	// The host accepts the connections, but never answers.
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+			defer c.Close()
+		}
+	}()
+
+	start := time.Now()
+	_, err = sshDial([]sshTarget{{
+		addr: l.Addr().String(),
+		config: &ssh.ClientConfig{
+			HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+			Timeout:         100 * time.Millisecond,
+		},
+	}})
+	if err == nil {
+		t.Errorf("Error '%v' even if wantErr is %t", err, true)
+	}
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected %v, actual %v", "less than a second", elapsed)
+	}
+}
+
+func Test_splitSSHDestination(t *testing.T) {
+	testCases := []struct {
+		name         string
+		dest         string
+		expectedUser string
+		expectedHost string
+		expectedPort int
+	}{
+		{name: "host", dest: "bastion", expectedHost: "bastion"},
+		{name: "user and host", dest: "deploy@bastion", expectedUser: "deploy", expectedHost: "bastion"},
+		{name: "user, host and port", dest: "deploy@bastion:2222", expectedUser: "deploy", expectedHost: "bastion", expectedPort: 2222},
+		{name: "ipv6", dest: "[::1]:2222", expectedHost: "::1", expectedPort: 2222},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			u, h, p := splitSSHDestination(tc.dest)
+			if tc.expectedUser != u || tc.expectedHost != h || tc.expectedPort != p {
+				t.Errorf("Expected %v %v %v, actual %v %v %v", tc.expectedUser, tc.expectedHost, tc.expectedPort, u, h, p)
+			}
+		})
+	}
+}
+
+func sshTestSigner(t *testing.T) ssh.Signer {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return s
+}
+
+func sshTestFile(t *testing.T, file, content string) {
+	if err := ioutil.WriteFile(file, []byte(content+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// sshTestListener can drop the connections of its clients, like a host rebooting.
+type sshTestListener struct {
+	net.Listener
+	mu    sync.Mutex
+	conns []net.Conn
+}
+
+func (l *sshTestListener) drop() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, c := range l.conns {
+		c.Close()
+	}
+	l.conns = nil
+}
+
+func sshTestPort(l net.Listener) int {
+	return l.Addr().(*net.TCPAddr).Port
+}
+
+// sshTestServer accepts the client key only. It echoes the commands executed, and forwards the TCP connections.
+func sshTestServer(t *testing.T, hostKey ssh.Signer, clientKey ssh.PublicKey) *sshTestListener {
+	return sshTestServerKeys(t, []ssh.Signer{hostKey}, clientKey)
+}
+
+// sshTestServerKeys is a sshTestServer with several host keys, from the preferred one.
+func sshTestServerKeys(t *testing.T, hostKeys []ssh.Signer, clientKey ssh.PublicKey) *sshTestListener {
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(c ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if !bytes.Equal(key.Marshal(), clientKey.Marshal()) {
+				return nil, fmt.Errorf("unknown key for %s", c.User())
+			}
+			return nil, nil
+		},
+	}
+	for _, k := range hostKeys {
+		config.AddHostKey(k)
+	}
+
+	nl, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := &sshTestListener{Listener: nl}
+
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+
+			l.mu.Lock()
+			l.conns = append(l.conns, c)
+			l.mu.Unlock()
+
+			go func() {
+				_, chans, reqs, err := ssh.NewServerConn(c, config)
+				if err != nil {
+					c.Close()
+					return
+				}
+				go ssh.DiscardRequests(reqs)
+
+				for nc := range chans {
+					switch nc.ChannelType() {
+					case "session":
+						go sshTestSession(nc)
+					case "direct-tcpip":
+						go sshTestForward(nc)
+					default:
+						nc.Reject(ssh.UnknownChannelType, nc.ChannelType())
+					}
+				}
+			}()
+		}
+	}()
+
+	return l
+}
+
+func sshTestSession(nc ssh.NewChannel) {
+	ch, reqs, err := nc.Accept()
+	if err != nil {
+		return
+	}
+	defer ch.Close()
+
+	for req := range reqs {
+		if req.Type != "exec" {
+			req.Reply(false, nil)
+			continue
+		}
+
+		cmd := struct{ Command string }{}
+		ssh.Unmarshal(req.Payload, &cmd)
+		req.Reply(true, nil)
+		io.Copy(ch, strings.NewReader(cmd.Command))
+		ch.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{0}))
+		return
+	}
+}
+
+func sshTestForward(nc ssh.NewChannel) {
+	dest := struct {
+		Host     string
+		Port     uint32
+		OrigHost string
+		OrigPort uint32
+	}{}
+	if err := ssh.Unmarshal(nc.ExtraData(), &dest); err != nil {
+		nc.Reject(ssh.ConnectionFailed, err.Error())
+		return
+	}
+
+	c, err := net.Dial("tcp", net.JoinHostPort(dest.Host, fmt.Sprint(dest.Port)))
+	if err != nil {
+		nc.Reject(ssh.ConnectionFailed, err.Error())
+		return
+	}
+
+	ch, reqs, err := nc.Accept()
+	if err != nil {
+		c.Close()
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+
+	go func() {
+		io.Copy(ch, c)
+		ch.CloseWrite()
+	}()
+	io.Copy(c, ch)
+	c.Close()
+}
@@ -2,16 +2,28 @@ package platform
 
 import (
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/Phantas0s/termui"
 )
 
 type termUI struct {
+	sync.Mutex
 	body    *termui.Grid
 	widgets []termui.GridBufferer
 	col     []*termui.Row
 	row     []*termui.Row
+
+	// every widget added to the grid, in order.
+	cells []*cell
+	// when not nil, the next widget drawn replace the widget of this cell.
+	target *cell
+}
+
+// cell of the grid holding a widget, which can be replaced without rebuilding the whole grid.
+type cell struct {
+	termui.GridBufferer
 }
 
 // NewTermUI returns a new Terminal Interface object with a given output mode.
@@ -41,15 +53,62 @@ func (t *termUI) AddCol(size int) {
 
 // AddRow to the termui grid system.
 func (t *termUI) AddRow() {
+	t.Lock()
+	defer t.Unlock()
+
 	t.body.AddRows(termui.NewRow(t.col...))
-	t.Align()
+	t.align()
 }
 
 func (t *termUI) Align() {
+	t.Lock()
+	defer t.Unlock()
+
+	t.align()
+}
+
+func (t *termUI) align() {
 	t.body.Width = termui.TermWidth()
 	t.body.Align()
 }
 
+// add a widget to the next column, or replace the widget of the targeted cell.
+func (t *termUI) add(w termui.GridBufferer) {
+	if t.target != nil {
+		t.target.GridBufferer = w
+		return
+	}
+
+	c := &cell{w}
+	t.cells = append(t.cells, c)
+	t.widgets = append(t.widgets, c)
+}
+
+// CountCells added to the grid.
+func (t *termUI) CountCells() int {
+	t.Lock()
+	defer t.Unlock()
+
+	return len(t.cells)
+}
+
+// UpdateCell replace the widget of the cell id with the widget drawn by draw, and render the grid.
+func (t *termUI) UpdateCell(id int, draw func()) {
+	t.Lock()
+	defer t.Unlock()
+
+	if id < 0 || id >= len(t.cells) {
+		return
+	}
+
+	t.target = t.cells[id]
+	draw()
+	t.target = nil
+
+	t.align()
+	termui.Render(t.body)
+}
+
 // TextBox widget type.
 func (t *termUI) TextBox(
 	data string,
@@ -77,7 +136,7 @@ func (t *termUI) TextBox(
 	textBox.Height = height
 	textBox.Multiline = multiline
 
-	t.widgets = append(t.widgets, textBox)
+	t.add(textBox)
 }
 
 func (t *termUI) Gauge(
@@ -99,7 +158,7 @@ func (t *termUI) Gauge(
 	gauge.Percent = data
 	gauge.Height = height
 
-	t.widgets = append(t.widgets, gauge)
+	t.add(gauge)
 }
 
 // Title is a special TextBox widget type.
@@ -153,7 +212,7 @@ func (t *termUI) BarChart(
 	bc.EmptyNumColor = termui.Attribute(enc)
 	bc.Buffer()
 
-	t.widgets = append(t.widgets, bc)
+	t.add(bc)
 }
 
 // StackedBarChar widget type.
@@ -187,7 +246,141 @@ func (t *termUI) StackedBarChart(
 	}
 	bc.NumColor = [8]termui.Attribute{termui.Attribute(nc), termui.Attribute(nc)}
 
-	t.widgets = append(t.widgets, bc)
+	t.add(bc)
+}
+
+// Sparklines widget type, one sparkline for each dataset.
+// The height of the widget is shared between the sparklines.
+func (t *termUI) Sparklines(
+	data [][]int,
+	labels []string,
+	title string,
+	tc uint16,
+	bd uint16,
+	colors []uint16,
+	height int,
+) {
+	sp := termui.NewSparklines()
+	sp.BorderLabel = title
+	sp.BorderLabelFg = termui.Attribute(tc)
+	sp.BorderFg = termui.Attribute(bd)
+	sp.Height = height
+
+	// The border takes two lines, and the label of each sparkline one.
+	lineHeight := 1
+	if len(data) > 0 && (height-2)/len(data)-1 > 1 {
+		lineHeight = (height-2)/len(data) - 1
+	}
+
+	for k, d := range data {
+		s := termui.NewSparkline()
+		s.Data = d
+		s.Height = lineHeight
+		if k < len(labels) {
+			s.Title = labels[k]
+		}
+		if k < len(colors) && colors[k] != 0 {
+			s.LineColor = termui.Attribute(colors[k])
+		}
+		sp.Add(s)
+	}
+
+	t.add(sp)
+}
+
+// LineChart widget type.
+func (t *termUI) LineChart(
+	data []float64,
+	dimensions []string,
+	title string,
+	tc uint16,
+	bd uint16,
+	fg uint16,
+	lineColor uint16,
+	height int,
+) {
+	lc := &lineChart{LineChart: termui.NewLineChart(), data: data, labels: dimensions}
+	lc.BorderLabel = title
+	lc.BorderLabelFg = termui.Attribute(tc)
+	lc.BorderFg = termui.Attribute(bd)
+	lc.Height = height
+	if fg != 0 {
+		lc.AxesColor = termui.Attribute(fg)
+	}
+	if lineColor != 0 {
+		lc.LineColor = termui.Attribute(lineColor)
+	}
+
+	// termui can't draw a chart without data.
+	if len(lc.data) == 0 {
+		lc.data = []float64{0}
+		lc.labels = []string{""}
+	}
+
+	t.add(lc)
+}
+
+// lineChart draws the newest points fitting in its width.
+// termui draws the points from the oldest: the newest ones would be cut.
+type lineChart struct {
+	*termui.LineChart
+	data   []float64
+	labels []string
+}
+
+// Buffer with the newest points, once the width is known.
+func (lc *lineChart) Buffer() termui.Buffer {
+	lc.Data, lc.DataLabels = newestPoints(lc.data, lc.labels, lc.InnerWidth())
+
+	return lc.LineChart.Buffer()
+}
+
+// newestPoints of a line chart fitting in the width given, with their labels.
+// termui draws two points per column, beside the Y axis and its labels.
+func newestPoints(data []float64, labels []string, width int) ([]float64, []string) {
+	n := 2 * (width - 1 - lineChartLabelWidth(data))
+	if n <= 0 || len(data) <= n {
+		return data, labels
+	}
+
+	start := len(data) - n
+	if len(labels) == len(data) {
+		labels = labels[start:]
+	}
+
+	return data[start:], labels
+}
+
+// lineChartLabelWidth is the width of the widest label of the Y axis, formatted like termui.
+// The range of the axis is extended by 20% of the span of the data at most, on each side.
+func lineChartLabelWidth(data []float64) int {
+	if len(data) == 0 {
+		return 0
+	}
+
+	min, max := data[0], data[0]
+	for _, v := range data {
+		if v < min {
+			min = v
+		}
+		if v > max {
+			max = v
+		}
+	}
+
+	width := 0
+	span := max - min
+	for _, v := range []float64{min - 0.2*span, max + 0.2*span} {
+		s := fmt.Sprintf("%.2f", v)
+		if len(s)-3 > 3 && v >= 0 {
+			s = fmt.Sprintf("%.2e", v)
+		}
+		if len(s) > width {
+			width = len(s)
+		}
+	}
+
+	return width
 }
 
 // Table widget type.
@@ -206,7 +399,7 @@ func (t *termUI) Table(
 	ta.BorderFg = termui.Attribute(bd)
 	ta.SetSize()
 
-	t.widgets = append(t.widgets, ta)
+	t.add(ta)
 }
 
 // KQuit set a key to quit the application.
@@ -242,6 +435,9 @@ func (t *termUI) Loop() {
 
 // Render termui and delete the instance of the widgets rendered.
 func (t *termUI) Render() {
+	t.Lock()
+	defer t.Unlock()
+
 	termui.Render(t.body)
 
 	// delete every widget for the rows / cols rendered.
@@ -260,14 +456,18 @@ func (t *termUI) Clean() {
 	t.body.Y = 0
 	t.body.BgColor = termui.ThemeAttr("bg")
 	t.body.Width = termui.TermWidth()
+	t.cells = []*cell{}
 }
 
 // Close termui.
-func (termUI) Close() {
+func (*termUI) Close() {
 	termui.Close()
 }
 
 func (t *termUI) HotReload() {
+	t.Lock()
+	defer t.Unlock()
+
 	t.Clean()
 	termui.Clear()
 }
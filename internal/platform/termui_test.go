@@ -0,0 +1,64 @@
+package platform
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_newestPoints(t *testing.T) {
+	testCases := []struct {
+		name           string
+		data           []float64
+		labels         []string
+		width          int
+		expected       []float64
+		expectedLabels []string
+	}{
+		{
+			name:           "every point fits",
+			data:           []float64{1, 2, 3},
+			labels:         []string{"a", "b", "c"},
+			width:          20,
+			expected:       []float64{1, 2, 3},
+			expectedLabels: []string{"a", "b", "c"},
+		},
+		{
+			// The Y labels are 4 characters wide ("0.60", "3.40"): 2 points fit in 8 columns.
+			name:           "newest points",
+			data:           []float64{1, 2, 3},
+			labels:         []string{"a", "b", "c"},
+			width:          6,
+			expected:       []float64{2, 3},
+			expectedLabels: []string{"b", "c"},
+		},
+		{
+			name:           "labels not matching the data",
+			data:           []float64{1, 2, 3},
+			labels:         []string{"a"},
+			width:          6,
+			expected:       []float64{2, 3},
+			expectedLabels: []string{"a"},
+		},
+		{
+			name:           "width unknown",
+			data:           []float64{1, 2, 3},
+			labels:         []string{"a", "b", "c"},
+			width:          0,
+			expected:       []float64{1, 2, 3},
+			expectedLabels: []string{"a", "b", "c"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, labels := newestPoints(tc.data, tc.labels, tc.width)
+
+			if !reflect.DeepEqual(actual, tc.expected) {
+				t.Errorf("Expected %v, actual %v", tc.expected, actual)
+			}
+			if !reflect.DeepEqual(labels, tc.expectedLabels) {
+				t.Errorf("Expected %v, actual %v", tc.expectedLabels, labels)
+			}
+		})
+	}
+}
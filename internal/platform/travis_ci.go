@@ -18,8 +18,11 @@ func NewTravisCI(token string) *TravisCI {
 		token = ""
 	}
 
+	client := travis.NewClient(travis.ApiOrgUrl, token)
+	client.HTTPClient = cachedHTTPClient("travis "+token, client.HTTPClient)
+
 	return &TravisCI{
-		client: travis.NewClient(travis.ApiOrgUrl, token),
+		client: client,
 	}
 }
 
@@ -0,0 +1,146 @@
+package internal
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Phantas0s/devdash/internal/platform"
+	"github.com/adrg/xdg"
+	"github.com/pkg/errors"
+)
+
+const (
+	pluginBox        = "plugin.box"
+	pluginGauge      = "plugin.gauge"
+	pluginBar        = "plugin.bar"
+	pluginStackedBar = "plugin.bar_stacked"
+	pluginTable      = "plugin.table"
+
+	optionArguments = "arguments"
+)
+
+type pluginWidget struct {
+	tui    *Tui
+	client *platform.Plugin
+}
+
+// NewPluginWidget to run the executables in dir, or in the PATH.
+func NewPluginWidget(dir string, timeout time.Duration) *pluginWidget {
+	return &pluginWidget{
+		client: platform.NewPlugin(dir, timeout),
+	}
+}
+
+type pluginServiceConfig struct {
+	Path    string `mapstructure:"path"`
+	Timeout int64  `mapstructure:"timeout"`
+}
+
+func init() {
+	RegisterService(ServiceFactory{
+		ID:        "plugin",
+		Name:      "Plugin",
+		ConfigKey: "plugin",
+		Always:    true,
+		Widgets:   []string{pluginBox, pluginGauge, pluginBar, pluginStackedBar, pluginTable},
+		Config:    func() interface{} { return &pluginServiceConfig{} },
+		New: func(config interface{}) (Service, error) {
+			c := config.(*pluginServiceConfig)
+			dir := c.Path
+			if dir == "" {
+				dir = filepath.Join(xdg.ConfigHome, "devdash", "plugins")
+			}
+			return NewPluginWidget(dir, time.Duration(c.Timeout)*time.Second), nil
+		},
+	})
+}
+
+// CreateWidgets for the plugin service.
+func (p *pluginWidget) CreateWidgets(widget Widget, tui *Tui) (f func() error, err error) {
+	p.tui = tui
+
+	switch widget.Name {
+	case pluginBox, pluginGauge, pluginBar, pluginStackedBar, pluginTable:
+		f, err = p.run(widget)
+	default:
+		return nil, errors.Errorf("can't find the widget %s for service plugin", widget.Name)
+	}
+
+	return
+}
+
+func (p *pluginWidget) run(widget Widget) (f func() error, err error) {
+	executable, ok := widget.Options[optionCommand]
+	if !ok || executable == "" {
+		return nil, errors.Errorf("the widget %s needs the option %s", widget.Name, optionCommand)
+	}
+
+	var args []string
+	if _, ok := widget.Options[optionArguments]; ok {
+		args = strings.Fields(widget.Options[optionArguments])
+	}
+
+	resp, err := p.client.Run(executable, args, platform.PluginRequest{
+		Name:    widget.Name,
+		Options: widget.Options,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The type of the widget is the suffix of its name, like "box" for "plugin.box".
+	wt := strings.TrimPrefix(widget.Name, "plugin.")
+	if resp.Type != "" && resp.Type != wt {
+		return nil, errors.Errorf("the plugin %s returned a %s, but the widget %s needs a %s", executable, resp.Type, widget.Name, wt)
+	}
+
+	title := " " + executable + " "
+	if resp.Title != "" {
+		title = resp.Title
+	}
+	if _, ok := widget.Options[optionTitle]; ok {
+		title = widget.Options[optionTitle]
+	}
+
+	switch wt {
+	case platform.PluginBox:
+		f = func() error {
+			return p.tui.AddTextBox(resp.Text, title, widget.Options)
+		}
+	case platform.PluginGauge:
+		f = func() error {
+			return p.tui.AddGauge(resp.Value, title, widget.Options)
+		}
+	case platform.PluginBar:
+		f = func() error {
+			return p.tui.AddBarChart(resp.Values, resp.Dimensions, title, widget.Options)
+		}
+	case platform.PluginStackedBar:
+		data, colors := pluginStackedBarData(resp.Series)
+		f = func() error {
+			return p.tui.AddStackedBarChart(data, resp.Dimensions, title, colors, widget.Options)
+		}
+	case platform.PluginTable:
+		f = func() error {
+			return p.tui.AddTable(resp.Rows, title, widget.Options)
+		}
+	}
+
+	return
+}
+
+func pluginStackedBarData(series []platform.PluginSeries) (data [8][]int, colors []uint16) {
+	defaultColors := []uint16{blue, green, yellow, red, magenta, cyan, white, black}
+	for k, v := range series {
+		data[k] = v.Values
+
+		color := defaultColors[k]
+		if c, ok := colorLookUp[v.Color]; ok {
+			color = c
+		}
+		colors = append(colors, color)
+	}
+
+	return
+}
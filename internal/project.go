@@ -7,13 +7,12 @@ package internal
 // TODO I feel the absence of generics here...  To refactor somehow (using reflection?).
 
 import (
+	"strconv"
+	"time"
+
 	"github.com/pkg/errors"
 )
 
-type service interface {
-	CreateWidgets(widget Widget, tui *Tui) (f func() error, err error)
-}
-
 type project struct {
 	name        string
 	nameOptions map[string]string
@@ -21,16 +20,10 @@ type project struct {
 	sizes       [][]string
 	themes      map[string]map[string]string
 	tui         *Tui
+	services    map[string]Service
 
-	gaWidget         service
-	monitorWidget    service
-	gscWidget        service
-	githubWidget     service
-	travisCIWidget   service
-	feedlyWidget     service
-	gitWidget        service
-	remoteHostWidget service
-	localhostWidget  service
+	// ID of the widgets in the TUI grid, once rendered.
+	cells [][][]int
 }
 
 // NewProject for the dashboard.
@@ -49,44 +42,13 @@ func NewProject(
 		sizes:       sizes,
 		themes:      themes,
 		tui:         tui,
+		services:    map[string]Service{},
 	}
 }
 
-// Service builder
-func (p *project) WithGa(ga *gaWidget) {
-	p.gaWidget = ga
-}
-
-func (p *project) WithMonitor(mon *monitorWidget) {
-	p.monitorWidget = mon
-}
-
-func (p *project) WithGoogleSearchConsole(gsc *gscWidget) {
-	p.gscWidget = gsc
-}
-
-func (p *project) WithGithub(github *githubWidget) {
-	p.githubWidget = github
-}
-
-func (p *project) WithTravisCI(travisCI *travisCIWidget) {
-	p.travisCIWidget = travisCI
-}
-
-func (p *project) WithFeedly(feedly *feedlyWidget) {
-	p.feedlyWidget = feedly
-}
-
-func (p *project) WithGit(git *gitWidget) {
-	p.gitWidget = git
-}
-
-func (p *project) WithRemoteHost(remoteHost *HostWidget) {
-	p.remoteHostWidget = remoteHost
-}
-
-func (p *project) WithLocalhost(localhost *HostWidget) {
-	p.localhostWidget = localhost
+// WithService add a service created from the registry to the project.
+func (p *project) WithService(serviceID string, s Service) {
+	p.services[serviceID] = s
 }
 
 func (p *project) addDefaultTheme(w Widget) Widget {
@@ -117,46 +79,28 @@ func (p *project) addDefaultTheme(w Widget) Widget {
 	return w
 }
 
-func (p *project) mapServiceID(serviceID string) (service, error) {
-	services := map[string]service{
-		"display": NewDisplayWidget(),
-		"ga":      p.gaWidget,
-		"mon":     p.monitorWidget,
-		"gsc":     p.gscWidget,
-		"github":  p.githubWidget,
-		"travis":  p.travisCIWidget,
-		"feedly":  p.feedlyWidget,
-		"git":     p.gitWidget,
-		"rh":      p.remoteHostWidget,
-		"lh":      p.localhostWidget,
+// mapServiceID to the service of the project.
+// The service is nil if it's registered but not configured for the project.
+func (p *project) mapServiceID(w Widget) (Service, error) {
+	f, err := findService(w.serviceID())
+	if err != nil {
+		return nil, err
 	}
 
-	if _, ok := services[serviceID]; ok {
-		return services[serviceID], nil
+	if !f.hasWidget(w.Name) {
+		return nil, errors.Errorf("can't find the widget %s for service %s", w.Name, f.Name)
 	}
 
-	return nil, errors.Errorf("Impossible to find the service with ID %s", serviceID)
+	return p.services[f.ID], nil
 }
 
 func mapServiceName(serviceID string) (string, error) {
-	services := map[string]string{
-		"display": "Display",
-		"ga":      "Google Analytics",
-		"mon":     "Monitor",
-		"gsc":     "Google Search Console",
-		"github":  "Github",
-		"travis":  "Travis",
-		"feedly":  "Feedly",
-		"git":     "Git",
-		"rh":      "Remote Host",
-		"lh":      "Localhost",
-	}
-
-	if _, ok := services[serviceID]; ok {
-		return services[serviceID], nil
+	f, err := findService(serviceID)
+	if err != nil {
+		return "", err
 	}
 
-	return "", errors.Errorf("Impossible to find the service with ID %s", serviceID)
+	return f.Name, nil
 }
 
 // Create all the widgets and populate them with data.
@@ -169,6 +113,36 @@ func (p *project) CreateWidgets() [][][]func() error {
 		DisplayError(p.tui, err)()
 	}
 
+	return p.renderers(func(Widget) bool { return true })
+}
+
+// Refresh the data of the rendered widgets and update them in place, without rebuilding the TUI grid.
+// The widgets having their own refresh interval are refreshed by the scheduler.
+func (p *project) Refresh() {
+	funcs := p.renderers(func(w Widget) bool {
+		_, ok := w.Options[optionRefresh]
+		return !ok
+	})
+
+	for r := range funcs {
+		for c := range funcs[r] {
+			for i, f := range funcs[r][c] {
+				if f == nil || r >= len(p.cells) || c >= len(p.cells[r]) || i >= len(p.cells[r][c]) {
+					continue
+				}
+
+				id := p.cells[r][c][i]
+				if err := p.tui.UpdateWidget(id, f); err != nil {
+					p.tui.UpdateWidget(id, DisplayError(p.tui, err))
+				}
+			}
+		}
+	}
+}
+
+// renderers of the widgets selected by the filter, fetched concurrently.
+// The renderer of a widget not selected is nil.
+func (p *project) renderers(filter func(w Widget) bool) [][][]func() error {
 	chs := make([][][]chan func() error, len(p.widgets))
 
 	for ir, row := range p.widgets {
@@ -176,10 +150,16 @@ func (p *project) CreateWidgets() [][][]func() error {
 			chs[ir] = append(chs[ir], []chan func() error{})
 			for _, w := range col {
 				w = p.addDefaultTheme(w)
-				ch := make(chan func() error)
+				ch := make(chan func() error, 1)
 				chs[ir][ic] = append(chs[ir][ic], ch)
 
-				service, err := p.mapServiceID(w.serviceID())
+				if !filter(w) {
+					ch <- nil
+					close(ch)
+					continue
+				}
+
+				service, err := p.mapServiceID(w)
 				if err != nil {
 					go func(c chan<- func() error) {
 						c <- DisplayError(p.tui, err)
@@ -218,7 +198,7 @@ func (p *project) CreateWidgets() [][][]func() error {
 
 // getRenderers to display the widgets.
 // One channel per widget to keep the order of widget in a slice.
-func getRenderers(s service, name string, w Widget, tui *Tui, c chan<- func() error) {
+func getRenderers(s Service, name string, w Widget, tui *Tui, c chan<- func() error) {
 	if s == nil {
 		c <- DisplayError(tui, errors.Errorf("can't use widget %s without service %s.", w.Name, name))
 	} else {
@@ -233,13 +213,17 @@ func getRenderers(s service, name string, w Widget, tui *Tui, c chan<- func() er
 }
 
 func (p *project) Render(funcs [][][]func() error) {
+	p.cells = make([][][]int, len(p.widgets))
 	for r, row := range p.widgets {
 		for c, col := range row {
+			p.cells[r] = append(p.cells[r], []int{})
 			for _, f := range funcs[r][c] {
+				id := p.tui.CountWidgets()
 				err := f()
 				if err != nil {
 					DisplayError(p.tui, err)()
 				}
+				p.cells[r][c] = append(p.cells[r][c], id)
 			}
 			if len(col) > 0 {
 				if err := p.tui.AddCol(p.sizes[r][c]); err != nil {
@@ -252,6 +236,74 @@ func (p *project) Render(funcs [][][]func() error) {
 	}
 }
 
+// RenderedWidgets indexed by the ID of their cell in the TUI grid.
+func (p *project) RenderedWidgets() map[int]Widget {
+	widgets := map[int]Widget{}
+	for r, row := range p.widgets {
+		for c, col := range row {
+			for i, w := range col {
+				if r < len(p.cells) && c < len(p.cells[r]) && i < len(p.cells[r][c]) {
+					widgets[p.cells[r][c][i]] = w
+				}
+			}
+		}
+	}
+
+	return widgets
+}
+
+// Schedule the refresh of every rendered widget with a refresh interval.
+func (p *project) Schedule(s *Scheduler) {
+	for r, row := range p.widgets {
+		for c, col := range row {
+			for i, w := range col {
+				if r >= len(p.cells) || c >= len(p.cells[r]) || i >= len(p.cells[r][c]) {
+					continue
+				}
+
+				// The refresh interval can come from a theme.
+				w = p.addDefaultTheme(w)
+				if _, ok := w.Options[optionRefresh]; !ok {
+					continue
+				}
+
+				id := p.cells[r][c][i]
+				refresh, err := strconv.ParseInt(w.Options[optionRefresh], 10, 0)
+				if err != nil || refresh <= 0 {
+					p.tui.UpdateWidget(id, DisplayError(p.tui, errors.Errorf("%s: %s must be a positive number of seconds", w.Name, optionRefresh)))
+					continue
+				}
+
+				s.Every(time.Duration(refresh)*time.Second, func() {
+					p.refreshWidget(w, id)
+				})
+			}
+		}
+	}
+}
+
+// refreshWidget fetch the data of a widget and replace it in the TUI grid.
+func (p *project) refreshWidget(w Widget, id int) {
+	f := DisplayError(p.tui, errors.Errorf("can't refresh widget %s", w.Name))
+
+	service, err := p.mapServiceID(w)
+	if err != nil {
+		f = DisplayError(p.tui, err)
+	} else if name, err := mapServiceName(w.serviceID()); err != nil {
+		f = DisplayError(p.tui, err)
+	} else {
+		ch := make(chan func() error, 1)
+		getRenderers(service, name, w, p.tui, ch)
+		if r, ok := <-ch; ok {
+			f = r
+		}
+	}
+
+	if err := p.tui.UpdateWidget(id, f); err != nil {
+		p.tui.UpdateWidget(id, DisplayError(p.tui, err))
+	}
+}
+
 func (p *project) addTitle(tui *Tui) error {
 	return tui.AddProjectTitle(p.name, p.nameOptions)
 }
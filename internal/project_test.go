@@ -1,8 +1,17 @@
 package internal
 
 import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
 	"reflect"
+	"strings"
 	"testing"
+
+	"github.com/Phantas0s/devdash/internal/platform"
 )
 
 func Test_addDefaultTheme(t *testing.T) {
@@ -138,3 +147,120 @@ func Test_addDefaultTheme(t *testing.T) {
 		})
 	}
 }
+
+func Test_CreateWidgetsReplay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "devdash_recordings")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer platform.StopRecorder()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/query_range":
+			fmt.Fprint(w, `{"status": "success", "data": {"resultType": "matrix", "result": [
+				{"metric": {"job": "node"}, "values": [[1577836800, "10"], [1577923200, "20"]]}
+			]}}`)
+		}
+	}))
+
+	// One widget per service: the widgets are created concurrently, and a service is not safe for concurrent use.
+	widgets := [][][]Widget{{
+		{
+			{Name: promBar, Options: map[string]string{optionQuery: "up"}},
+		},
+		{
+			{Name: boxAvailability, Options: map[string]string{optionAddress: server.URL}},
+		},
+		{
+			{Name: pluginBox, Options: map[string]string{optionCommand: "echo"}},
+		},
+	}}
+
+	render := func(plugins string) string {
+		snapshot := platform.NewSnapshot()
+		p := NewProject("devdash", nil, widgets, [][]string{{"S", "S", "S"}}, nil, NewTUI(snapshot))
+		options := map[string]map[string]interface{}{
+			"prom":   {"address": server.URL},
+			"mon":    {},
+			"plugin": {"path": plugins},
+		}
+		for id, o := range options {
+			f, err := findService(id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			s, err := f.Create(o)
+			if err != nil {
+				t.Fatal(err)
+			}
+			p.WithService(id, s)
+		}
+		p.Render(p.CreateWidgets())
+
+		var buf bytes.Buffer
+		if err := snapshot.Write(&buf, platform.SnapshotText); err != nil {
+			t.Fatal(err)
+		}
+
+		return buf.String()
+	}
+
+	platform.Record(dir)
+	expected := render("./platform/testdata/plugins")
+	if strings.Contains(expected, "ERROR") || !strings.Contains(expected, "01-02  20") || !strings.Contains(expected, pluginBox) {
+		t.Fatalf("Expected the data of the server, actual %v", expected)
+	}
+
+	// The replay can't use the network, nor run the plugins.
+	server.Close()
+
+	platform.Replay(dir)
+	if actual := render(dir); actual != expected {
+		t.Errorf("Expected %v, actual %v", expected, actual)
+	}
+}
+
+func Test_ScheduleThemeRefresh(t *testing.T) {
+	testCases := []struct {
+		name     string
+		expected bool
+		themes   map[string]map[string]string
+		widget   Widget
+	}{
+		{
+			name:     "refresh from the widget type theme",
+			expected: true,
+			themes:   map[string]map[string]string{"box": {optionRefresh: "60"}},
+			widget:   Widget{Name: displayBox},
+		},
+		{
+			name:     "refresh from a named theme",
+			expected: true,
+			themes:   map[string]map[string]string{"slow": {optionRefresh: "60"}},
+			widget:   Widget{Name: displayBox, Theme: "slow"},
+		},
+		{
+			name:   "no refresh",
+			themes: map[string]map[string]string{"box": {optionTitle: "devdash"}},
+			widget: Widget{Name: displayBox},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := NewProject("devdash", nil, [][][]Widget{{{tc.widget}}}, [][]string{{"M"}}, tc.themes, NewTUI(platform.NewSnapshot()))
+			p.WithService("display", NewDisplayWidget())
+			p.Render(p.CreateWidgets())
+
+			s := NewScheduler()
+			p.Schedule(s)
+			defer s.Stop()
+
+			if actual := s.stop != nil; actual != tc.expected {
+				t.Errorf("Expected %v, actual %v", tc.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,208 @@
+package internal
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Phantas0s/devdash/internal/platform"
+	"github.com/pkg/errors"
+)
+
+const (
+	promBox   = "prom.box"
+	promGauge = "prom.gauge"
+	promBar   = "prom.bar"
+	promTable = "prom.table"
+
+	// PromQL expression of the widget
+	optionQuery = "query"
+	// labels displayed in a table
+	optionLabels = "labels"
+)
+
+type prometheusWidget struct {
+	tui    *Tui
+	client *platform.Prometheus
+}
+
+// NewPrometheusWidget with the address of the Prometheus server and an optional token.
+func NewPrometheusWidget(address string, token string) (*prometheusWidget, error) {
+	if address == "" {
+		return nil, errors.New("the address of the prometheus server is missing")
+	}
+
+	return &prometheusWidget{
+		client: platform.NewPrometheus(address, token),
+	}, nil
+}
+
+type prometheusServiceConfig struct {
+	Address string `mapstructure:"address"`
+	Token   string `mapstructure:"token"`
+}
+
+func init() {
+	RegisterService(ServiceFactory{
+		ID:        "prom",
+		Name:      "Prometheus",
+		ConfigKey: "prometheus",
+		Widgets:   []string{promBox, promGauge, promBar, promTable},
+		Config:    func() interface{} { return &prometheusServiceConfig{} },
+		New: func(config interface{}) (Service, error) {
+			c := config.(*prometheusServiceConfig)
+			return NewPrometheusWidget(c.Address, c.Token)
+		},
+	})
+}
+
+func (p *prometheusWidget) CreateWidgets(widget Widget, tui *Tui) (f func() error, err error) {
+	p.tui = tui
+
+	switch widget.Name {
+	case promBox:
+		f, err = p.box(widget)
+	case promGauge:
+		f, err = p.gauge(widget)
+	case promBar:
+		f, err = p.bar(widget)
+	case promTable:
+		f, err = p.table(widget)
+	default:
+		return nil, errors.Errorf("can't find the widget %s for service prometheus", widget.Name)
+	}
+
+	return
+}
+
+func (p *prometheusWidget) box(widget Widget) (f func() error, err error) {
+	query, err := extractQuery(widget)
+	if err != nil {
+		return nil, err
+	}
+
+	title := " " + query + " "
+	if _, ok := widget.Options[optionTitle]; ok {
+		title = widget.Options[optionTitle]
+	}
+
+	value, err := p.client.Value(query)
+	if err != nil {
+		return nil, err
+	}
+
+	text := strconv.FormatFloat(math.Round(value*100)/100, 'f', -1, 64)
+	if _, ok := widget.Options[optionUnit]; ok {
+		text += " " + widget.Options[optionUnit]
+	}
+
+	f = func() error {
+		return p.tui.AddTextBox(text, title, widget.Options)
+	}
+
+	return
+}
+
+func (p *prometheusWidget) gauge(widget Widget) (f func() error, err error) {
+	query, err := extractQuery(widget)
+	if err != nil {
+		return nil, err
+	}
+
+	title := " " + query + " "
+	if _, ok := widget.Options[optionTitle]; ok {
+		title = widget.Options[optionTitle]
+	}
+
+	value, err := p.client.Value(query)
+	if err != nil {
+		return nil, err
+	}
+
+	f = func() error {
+		return p.tui.AddGauge(value, title, widget.Options)
+	}
+
+	return
+}
+
+func (p *prometheusWidget) bar(widget Widget) (f func() error, err error) {
+	query, err := extractQuery(widget)
+	if err != nil {
+		return nil, err
+	}
+
+	startDate, endDate, err := ExtractTimeRange(time.Now(), widget.Options)
+	if err != nil {
+		return nil, err
+	}
+
+	timePeriod := "day"
+	if _, ok := widget.Options[optionTimePeriod]; ok {
+		timePeriod = strings.TrimSpace(widget.Options[optionTimePeriod])
+	}
+
+	title := " " + query + " per " + timePeriod + " "
+	if _, ok := widget.Options[optionTitle]; ok {
+		title = widget.Options[optionTitle]
+	}
+
+	dim, val, err := p.client.Bar(query, startDate, endDate, timePeriod)
+	if err != nil {
+		return nil, err
+	}
+
+	f = func() error {
+		return p.tui.AddBarChart(val, dim, title, widget.Options)
+	}
+
+	return
+}
+
+func (p *prometheusWidget) table(widget Widget) (f func() error, err error) {
+	query, err := extractQuery(widget)
+	if err != nil {
+		return nil, err
+	}
+
+	title := " " + query + " "
+	if _, ok := widget.Options[optionTitle]; ok {
+		title = widget.Options[optionTitle]
+	}
+
+	labels := []string{}
+	if _, ok := widget.Options[optionLabels]; ok {
+		if len(widget.Options[optionLabels]) > 0 {
+			labels = strings.Split(strings.Replace(widget.Options[optionLabels], " ", "", -1), ",")
+		}
+	}
+
+	var limit int64 = 5
+	if _, ok := widget.Options[optionRowLimit]; ok {
+		limit, err = strconv.ParseInt(widget.Options[optionRowLimit], 10, 0)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s must be a number", widget.Options[optionRowLimit])
+		}
+	}
+
+	table, err := p.client.Table(query, labels, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	f = func() error {
+		return p.tui.AddTable(table, title, widget.Options)
+	}
+
+	return
+}
+
+func extractQuery(widget Widget) (string, error) {
+	query := strings.TrimSpace(widget.Options[optionQuery])
+	if query == "" {
+		return "", errors.Errorf("the widget %s needs a %s option", widget.Name, optionQuery)
+	}
+
+	return query, nil
+}
@@ -0,0 +1,56 @@
+package internal
+
+import (
+	"sync"
+	"time"
+)
+
+// Scheduler runs jobs at their own interval, independently of the refresh of the whole dashboard.
+type Scheduler struct {
+	mu   sync.Mutex
+	wg   sync.WaitGroup
+	stop chan struct{}
+}
+
+func NewScheduler() *Scheduler {
+	return &Scheduler{}
+}
+
+// Every interval, run the job till the scheduler is stopped.
+func (s *Scheduler) Every(interval time.Duration, job func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stop == nil {
+		s.stop = make(chan struct{})
+	}
+	stop := s.stop
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				job()
+			}
+		}
+	}()
+}
+
+// Stop every job and wait for the running ones to finish.
+// New jobs can be scheduled afterwards.
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	if s.stop != nil {
+		close(s.stop)
+		s.stop = nil
+	}
+	s.mu.Unlock()
+
+	s.wg.Wait()
+}
@@ -0,0 +1,39 @@
+package internal
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func Test_SchedulerStop(t *testing.T) {
+	var count int32
+	s := NewScheduler()
+	s.Every(time.Millisecond, func() {
+		atomic.AddInt32(&count, 1)
+	})
+
+	time.Sleep(20 * time.Millisecond)
+	s.Stop()
+
+	stopped := atomic.LoadInt32(&count)
+	if stopped == 0 {
+		t.Errorf("Expected the job to run before the scheduler is stopped")
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	if actual := atomic.LoadInt32(&count); actual != stopped {
+		t.Errorf("Expected %v, actual %v", stopped, actual)
+	}
+
+	// The scheduler can be reused after being stopped.
+	s.Every(time.Millisecond, func() {
+		atomic.AddInt32(&count, 1)
+	})
+	time.Sleep(20 * time.Millisecond)
+	s.Stop()
+
+	if actual := atomic.LoadInt32(&count); actual == stopped {
+		t.Errorf("Expected the job to run again after being rescheduled")
+	}
+}
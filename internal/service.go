@@ -0,0 +1,128 @@
+package internal
+
+// Registry of the services available in DevDash.
+// Each service registers a factory (see the init function of each widget file) with its configuration, its constructor and its widgets.
+// The dashboard builder then iterates over the registry to create every service configured for a project.
+
+import (
+	"os"
+	"sort"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/pkg/errors"
+)
+
+// Service creates the widgets of the dashboard.
+type Service interface {
+	CreateWidgets(widget Widget, tui *Tui) (f func() error, err error)
+}
+
+// ServiceFactory describes a service and how to create it from the configuration.
+type ServiceFactory struct {
+	// ID of the service, used as prefix for its widgets (for example "github" for "github.box_stars").
+	ID string
+	// Name of the service displayed to the user.
+	Name string
+	// ConfigKey of the service in the "services" of a project.
+	ConfigKey string
+	// Env variables used when the options are not set in the configuration (option => env variable).
+	Env map[string]string
+	// Always create the service, even without configuration.
+	Always bool
+	// Widgets the service can create.
+	Widgets []string
+	// Config returns a pointer to the configuration of the service, filled with the options of the config file.
+	// Can be nil if the service doesn't have any configuration.
+	Config func() interface{}
+	// New creates the service with the configuration returned by Config.
+	New func(config interface{}) (Service, error)
+}
+
+var registry = map[string]ServiceFactory{}
+
+// RegisterService to make it available in the dashboards.
+// It's called in an init function, from this package or from another one adding its own service.
+func RegisterService(f ServiceFactory) {
+	if _, ok := registry[f.ID]; ok {
+		panic("service " + f.ID + " already registered")
+	}
+
+	registry[f.ID] = f
+}
+
+// RegisteredServices sorted by ID.
+func RegisteredServices() []ServiceFactory {
+	fs := make([]ServiceFactory, 0, len(registry))
+	for _, f := range registry {
+		fs = append(fs, f)
+	}
+
+	sort.Slice(fs, func(i, j int) bool {
+		return fs[i].ID < fs[j].ID
+	})
+
+	return fs
+}
+
+func findService(serviceID string) (ServiceFactory, error) {
+	if f, ok := registry[serviceID]; ok {
+		return f, nil
+	}
+
+	return ServiceFactory{}, errors.Errorf("Impossible to find the service with ID %s", serviceID)
+}
+
+// WithEnv add the env variables of the service to its configuration, if the options are not already set.
+func (f ServiceFactory) WithEnv(options map[string]interface{}) map[string]interface{} {
+	opts := map[string]interface{}{}
+	for k, v := range options {
+		opts[k] = v
+	}
+
+	for k, env := range f.Env {
+		if v, ok := opts[k]; ok && v != "" {
+			continue
+		}
+		if e := os.Getenv(env); e != "" {
+			opts[k] = e
+		}
+	}
+
+	return opts
+}
+
+// Configured returns true if the service needs to be created for the options given.
+func (f ServiceFactory) Configured(options map[string]interface{}) bool {
+	return f.Always || len(options) > 0
+}
+
+// Create the service from the options of the configuration file.
+func (f ServiceFactory) Create(options map[string]interface{}) (Service, error) {
+	var config interface{}
+	if f.Config != nil {
+		config = f.Config()
+		decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+			WeaklyTypedInput: true,
+			Result:           config,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if err := decoder.Decode(options); err != nil {
+			return nil, errors.Wrapf(err, "can't read the configuration of the service %s", f.Name)
+		}
+	}
+
+	return f.New(config)
+}
+
+func (f ServiceFactory) hasWidget(name string) bool {
+	for _, w := range f.Widgets {
+		if w == name {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,97 @@
+package internal
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func Test_serviceFactoryWithEnv(t *testing.T) {
+	testCases := []struct {
+		name     string
+		expected map[string]interface{}
+		options  map[string]interface{}
+		env      string
+	}{
+		{
+			name:     "option from env variable",
+			expected: map[string]interface{}{"token": "env_token", "owner": "me"},
+			options:  map[string]interface{}{"owner": "me"},
+			env:      "env_token",
+		},
+		{
+			name:     "option from config not overwritten",
+			expected: map[string]interface{}{"token": "config_token"},
+			options:  map[string]interface{}{"token": "config_token"},
+			env:      "env_token",
+		},
+		{
+			name:     "no config and no env variable",
+			expected: map[string]interface{}{},
+			options:  nil,
+			env:      "",
+		},
+	}
+
+	f := ServiceFactory{Env: map[string]string{"token": "DEVDASH_TEST_TOKEN"}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			os.Setenv("DEVDASH_TEST_TOKEN", tc.env)
+			defer os.Unsetenv("DEVDASH_TEST_TOKEN")
+
+			actual := f.WithEnv(tc.options)
+
+			if !reflect.DeepEqual(tc.expected, actual) {
+				t.Errorf("Expected %v, actual %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_serviceFactoryCreate(t *testing.T) {
+	testCases := []struct {
+		name     string
+		expected gaServiceConfig
+		options  map[string]interface{}
+		wantErr  bool
+	}{
+		{
+			name:     "happy case",
+			expected: gaServiceConfig{Keyfile: "key.json", ViewID: "1234"},
+			options:  map[string]interface{}{"keyfile": "key.json", "view_id": "1234"},
+		},
+		{
+			name:     "numeric value for a string option",
+			expected: gaServiceConfig{Keyfile: "key.json", ViewID: "1234"},
+			options:  map[string]interface{}{"keyfile": "key.json", "view_id": 1234},
+		},
+		{
+			name:    "wrong type",
+			options: map[string]interface{}{"keyfile": []string{"key.json"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var actual gaServiceConfig
+			f := ServiceFactory{
+				Config: func() interface{} { return &gaServiceConfig{} },
+				New: func(config interface{}) (Service, error) {
+					actual = *config.(*gaServiceConfig)
+					return NewDisplayWidget(), nil
+				},
+			}
+
+			_, err := f.Create(tc.options)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Error '%v' even if wantErr is %t", err, tc.wantErr)
+				return
+			}
+
+			if !tc.wantErr && !reflect.DeepEqual(tc.expected, actual) {
+				t.Errorf("Expected %v, actual %v", tc.expected, actual)
+			}
+		})
+	}
+}
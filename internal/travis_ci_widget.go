@@ -24,6 +24,23 @@ func NewTravisCIWidget(token string) *travisCIWidget {
 	}
 }
 
+type travisCIServiceConfig struct {
+	Token string `mapstructure:"token"`
+}
+
+func init() {
+	RegisterService(ServiceFactory{
+		ID:        "travis",
+		Name:      "Travis",
+		ConfigKey: "travis",
+		Widgets:   []string{travisCITableBuilds},
+		Config:    func() interface{} { return &travisCIServiceConfig{} },
+		New: func(config interface{}) (Service, error) {
+			return NewTravisCIWidget(config.(*travisCIServiceConfig).Token), nil
+		},
+	})
+}
+
 func (tc travisCIWidget) CreateWidgets(widget Widget, tui *Tui) (f func() error, err error) {
 	tc.tui = tui
 
@@ -154,6 +154,27 @@ type drawer interface {
 		titleColor uint16,
 		height int,
 	)
+
+	Sparklines(
+		data [][]int,
+		labels []string,
+		title string,
+		tc uint16,
+		bd uint16,
+		colors []uint16,
+		height int,
+	)
+
+	LineChart(
+		data []float64,
+		dimensions []string,
+		title string,
+		tc uint16,
+		bd uint16,
+		fg uint16,
+		lineColor uint16,
+		height int,
+	)
 	AddCol(size int)
 	AddRow()
 }
@@ -179,6 +200,11 @@ type aligner interface {
 	Align()
 }
 
+type updater interface {
+	CountCells() int
+	UpdateCell(id int, draw func())
+}
+
 type manager interface {
 	keyManager
 	renderer
@@ -186,6 +212,7 @@ type manager interface {
 	looper
 	reloader
 	aligner
+	updater
 }
 
 type coloredElements struct {
@@ -506,6 +533,67 @@ func (t *Tui) AddStackedBarChart(
 	return nil
 }
 
+// AddSparklines to the TUI, one line for each dataset with its label.
+// It represents the last values of the datasets, without axis.
+func (t *Tui) AddSparklines(
+	data [][]int,
+	labels []string,
+	title string,
+	colors []uint16,
+	options map[string]string,
+) (err error) {
+	var height int64 = 8
+	if _, ok := options[optionHeight]; ok {
+		height, err = strconv.ParseInt(options[optionHeight], 0, 0)
+		if err != nil {
+			return err
+		}
+	}
+
+	ce := createColoredElements(options)
+	t.instance.Sparklines(
+		data,
+		labels,
+		title,
+		ce.titleColor,
+		ce.borderColor,
+		colors,
+		int(height),
+	)
+
+	return nil
+}
+
+// AddLineChart to the TUI, a representation of the evolution of a dataset overtime, with its axes.
+func (t *Tui) AddLineChart(
+	data []float64,
+	dimensions []string,
+	title string,
+	options map[string]string,
+) (err error) {
+	var height int64 = 10
+	if _, ok := options[optionHeight]; ok {
+		height, err = strconv.ParseInt(options[optionHeight], 0, 0)
+		if err != nil {
+			return err
+		}
+	}
+
+	ce := createColoredElements(options)
+	t.instance.LineChart(
+		data,
+		dimensions,
+		title,
+		ce.titleColor,
+		ce.borderColor,
+		ce.textColor,
+		ce.barColor,
+		int(height),
+	)
+
+	return nil
+}
+
 // AddTable to the TUI, with a header and the dataset.
 func (t *Tui) AddTable(data [][]string, title string, options map[string]string) error {
 	ce := createColoredElements(options)
@@ -550,6 +638,21 @@ func (t *Tui) Clean() {
 	t.instance.Clean()
 }
 
+// CountWidgets added to the TUI grid.
+// The ID of the next widget added is the current count.
+func (t *Tui) CountWidgets() int {
+	return t.instance.CountCells()
+}
+
+// UpdateWidget replace the widget id in the TUI grid with the widget added by render, without rebuilding the whole grid.
+func (t *Tui) UpdateWidget(id int, render func() error) (err error) {
+	t.instance.UpdateCell(id, func() {
+		err = render()
+	})
+
+	return
+}
+
 // Hot reload the whole TUI
 func (t *Tui) HotReload() {
 	t.instance.HotReload()
@@ -19,6 +19,9 @@ const (
 	optionTimePeriod = "time_period"
 	optionGlobal     = "global"
 
+	// Refresh interval of the widget, in seconds
+	optionRefresh = "refresh"
+
 	// Tables
 	optionRowLimit  = "row_limit"
 	optionCharLimit = "character_limit"
@@ -44,6 +47,33 @@ const (
 	// Repository
 	optionRepository = "repository"
 	optionOwner      = "owner"
+	optionBranch     = "branch"
+	optionTag        = "tag"
+
+	// List of repositories, like "devdash,termui"
+	optionRepositories = "repositories"
+
+	// Github Actions
+	optionWorkflow = "workflow"
+	optionEvent    = "event"
+
+	// Github reviews
+	optionUser = "user"
+	optionTeam = "team"
+
+	// Github pull requests without update for this number of days
+	optionStaleDays = "stale_days"
+
+	// Remote hosts
+	optionHost  = "host"
+	optionGroup = "group"
+
+	// Glob patterns of the devices to display (cores, interfaces, disks), separated with commas
+	optionInclude = "include"
+	optionExclude = "exclude"
+
+	// Resource of an API with several rate limits
+	optionResource = "resource"
 
 	// Owner / all
 	optionScope = ownerScope